@@ -0,0 +1,54 @@
+package configcmd
+
+import (
+	"fmt"
+
+	"github.com/davidborzek/spofi/internal/config"
+	"github.com/davidborzek/spofi/internal/views"
+	"github.com/davidborzek/spofi/pkg/rofi"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	Cmd = &cli.Command{
+		Name:  "config",
+		Usage: "Inspect the configuration",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "check",
+				Usage:  "Checks the keybindings for conflicts",
+				Action: check,
+			},
+		},
+	}
+)
+
+func check(ctx *cli.Context) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	// The menu backend of the command line takes
+	// precedence over the config, as on start.
+	menu := ctx.String("menu")
+	if menu == "" {
+		menu = cfg.MenuBackend
+	}
+
+	if _, err := rofi.NewBackend(menu); err != nil {
+		return err
+	}
+
+	errs := views.ValidateKeybindings(cfg.Keybindings, menu)
+	for _, err := range errs {
+		fmt.Println(err)
+	}
+
+	if len(errs) > 0 {
+		return cli.Exit(fmt.Sprintf("found %d keybinding conflicts", len(errs)), 1)
+	}
+
+	fmt.Println("No keybinding conflicts found.")
+	return nil
+}
@@ -1,12 +1,13 @@
 package cmd
 
 import (
+	"fmt"
+	"io"
 	"os"
 
+	"github.com/davidborzek/spofi/cmd/configcmd"
 	"github.com/davidborzek/spofi/cmd/setup"
-	"github.com/davidborzek/spofi/internal/app"
 	"github.com/davidborzek/spofi/internal/config"
-	"github.com/davidborzek/spofi/internal/theme"
 	"github.com/davidborzek/spofi/internal/views"
 	"github.com/davidborzek/spofi/pkg/rofi"
 	"github.com/urfave/cli/v2"
@@ -26,23 +27,48 @@ func start(ctx *cli.Context) error {
 		return err
 	}
 
+	if name := ctx.String("profile"); !cfg.HasProfile(name) {
+		return fmt.Errorf("unknown profile %q, run 'spofi setup --profile %s' to set it up", name, name)
+	}
+
 	if cfg.IsConfigIncomplete() {
 		return setup.Cmd.Action(ctx)
 	}
 
-	themeStr := ctx.String("theme")
-	if themeStr != "" {
-		rofi.SetCustomTheme(themeStr)
-	} else if cfg.Theme != "" {
-		rofi.SetCustomTheme(cfg.Theme)
-	} else {
-		theme.LoadTheme()
+	menuStr := ctx.String("menu")
+	if menuStr == "" {
+		menuStr = cfg.MenuBackend
 	}
 
-	appCtx := app.NewApp(cfg)
+	backend, err := rofi.NewBackend(menuStr)
+	if err != nil {
+		return err
+	}
+	rofi.SetBackend(backend)
 
-	views.NewMainView(appCtx).
-		Show()
+	appCtx, err := views.Setup(cfg, views.SetupOptions{
+		Theme: ctx.String("theme"),
+		Menu:  menuStr,
+	})
+	if err != nil {
+		return err
+	}
+
+	var stack []views.View
+	if name := ctx.String("view"); name != "" {
+		view, err := views.NewView(appCtx, name)
+		if err != nil {
+			return err
+		}
+		stack = append(stack, view)
+	}
+
+	views.NewNavigator(views.NewMainView(appCtx), stack...).
+		Run()
+
+	// Let background refreshes of the library finish,
+	// so the cache is up to date on the next start.
+	appCtx.Library.Wait()
 
 	return nil
 }
@@ -54,21 +80,60 @@ func newApp() *cli.App {
 	app.Version = Version
 	app.Commands = []*cli.Command{
 		setup.Cmd,
+		configcmd.Cmd,
 	}
 	app.Flags = []cli.Flag{
+		&cli.StringFlag{
+			Name:     "config",
+			Required: false,
+			EnvVars:  []string{"SPOFI_CONFIG"},
+			Usage:    "Set the path of the config file",
+		},
+		&cli.StringFlag{
+			Name:     "profile",
+			Required: false,
+			EnvVars:  []string{"SPOFI_PROFILE"},
+			Usage:    "Use a profile of the config file",
+		},
 		&cli.StringFlag{
 			Name:     "theme",
 			Required: false,
 			Usage:    "Set a custom rofi theme",
 		},
+		&cli.StringFlag{
+			Name:     "menu",
+			Required: false,
+			EnvVars:  []string{"SPOFI_MENU"},
+			Usage:    "Set the menu backend (rofi, wofi, fuzzel, bemenu, dmenu, fzf)",
+		},
+		&cli.StringFlag{
+			Name:     "view",
+			Required: false,
+			Usage:    "Open a view on start (player, quick, search, likedTracks, albums, playlists, queue, recentlyPlayed, devices, accounts)",
+		},
+	}
+	app.Before = func(ctx *cli.Context) error {
+		config.SetPath(ctx.String("config"))
+		config.SetProfile(ctx.String("profile"))
+		return nil
 	}
 	app.Action = start
 
 	return app
 }
 
-func Main(args []string) {
+// run is an internal implementation to run spofi and
+// to print the error which ended it. It returns the
+// exit code.
+func run(args []string, stderr io.Writer) int {
 	if err := newApp().Run(args); err != nil {
-		os.Exit(1)
+		fmt.Fprintln(stderr, "Error:", err)
+		return 1
 	}
+
+	return 0
+}
+
+func Main(args []string) {
+	os.Exit(run(args, os.Stderr))
 }
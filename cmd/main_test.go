@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+// writeTestConfig writes a complete config
+// to a temporary config dir.
+func writeTestConfig(t *testing.T) {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("XDG_STATE_HOME", filepath.Join(dir, "state"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	t.Setenv("HOME", dir)
+	t.Setenv("TMPDIR", dir)
+	t.Setenv("SPOFI_CONFIG", "")
+	t.Setenv("SPOFI_PROFILE", "")
+
+	if err := os.MkdirAll(filepath.Join(dir, "spofi"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	raw := []byte("spotify:\n  clientId: id\n  clientSecret: secret\n  refreshToken: token\n")
+	if err := os.WriteFile(filepath.Join(dir, "spofi", "spofi.yaml"), raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "unknown view",
+			args: []string{"--view", "bogus"},
+			want: "Error: unknown view: bogus",
+		},
+		{
+			name: "unknown menu backend",
+			args: []string{"--menu", "bogus"},
+			want: "Error: unknown menu backend",
+		},
+		{
+			name: "unknown profile",
+			args: []string{"--profile", "work"},
+			want: `Error: unknown profile "work", run 'spofi setup --profile work' to set it up`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			writeTestConfig(t)
+
+			var stderr bytes.Buffer
+			if code := run(append([]string{"spofi"}, tc.args...), &stderr); code != 1 {
+				t.Fatalf("expected exit code 1, got %d", code)
+			}
+
+			if !strings.HasPrefix(stderr.String(), tc.want) {
+				t.Fatalf("expected %q, got %q", tc.want, stderr.String())
+			}
+		})
+	}
+}
+
+func TestConfigCheckMenu(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  string
+		code int
+	}{
+		{
+			// Alt+f clashes with the builtin keybindings of rofi.
+			name: "config",
+			code: 1,
+		},
+		{
+			name: "flag",
+			args: []string{"--menu", "fzf"},
+		},
+		{
+			name: "env",
+			env:  "fzf",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			writeTestConfig(t)
+			t.Setenv("SPOFI_MENU", tc.env)
+			t.Setenv("SPOFI_KEYBINDINGS", "{pin: Alt+f}")
+
+			code := 0
+			cli.OsExiter = func(c int) { code = c }
+			t.Cleanup(func() { cli.OsExiter = os.Exit })
+
+			args := append(append([]string{"spofi"}, tc.args...), "config", "check")
+			if run(args, io.Discard) != 0 && code == 0 {
+				code = 1
+			}
+
+			if code != tc.code {
+				t.Fatalf("expected exit code %d, got %d", tc.code, code)
+			}
+		})
+	}
+}
@@ -1,14 +1,18 @@
 package setup
 
 import (
-	"context"
+	"bufio"
+	"errors"
 	"fmt"
-	"net"
-	"net/http"
+	"io"
+	"net/url"
+	"os"
 	"os/exec"
-	"strconv"
+	"strings"
+	"time"
 
 	"github.com/davidborzek/spofi/internal/config"
+	"github.com/davidborzek/spofi/internal/credentials"
 	"github.com/davidborzek/spofi/pkg/spotify"
 )
 
@@ -25,8 +29,9 @@ var (
 		"playlist-modify-public",
 	}
 
-	srv      http.Server
-	codeChan chan string = make(chan string)
+	// stdin is shared by the client secret and
+	// the pasted authorization code.
+	stdin = bufio.NewReader(os.Stdin)
 )
 
 // openBrowser opens a given url in a browser.
@@ -34,32 +39,80 @@ func openBrowser(url string) error {
 	return exec.Command("xdg-open", url).Start()
 }
 
-// startAuthServer starts a server for the spotify
-// oauth callback.
-func startAuthServer(addr string) {
-	srv = http.Server{
-		Addr: addr,
+// getTokenPair is an internal implementation to exchange the
+// authorization code with or without the PKCE code verifier.
+func getTokenPair(sc spotify.AuthClient, code string, codeVerifier string) (*spotify.AuthorizationCodeGrantResponse, error) {
+	if codeVerifier == "" {
+		return sc.GetTokenPair(code)
 	}
+	return sc.GetPKCETokenPair(code, codeVerifier)
+}
+
+// readAuthCode is an internal implementation to read the
+// authorization code from the user, who pastes either the
+// redirected url or only the code.
+func readAuthCode(in *bufio.Reader, state string) (string, error) {
+	fmt.Print("\nPaste the url of the page you were redirected to (or only the code): ")
 
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		code := r.URL.Query().Get("code")
-		w.WriteHeader(200)
-		w.Write([]byte("The setup process is complete! You may now close this window and use spofi to control spotify."))
-		codeChan <- code
-	})
+	line, err := in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
 
-	srv.ListenAndServe()
+	return parseAuthCode(strings.TrimSpace(line), state)
 }
 
-// shutdownServer shutdowns the http
-// callback server.
-func shutdownServer() {
-	srv.Shutdown(context.Background())
+// parseAuthCode is an internal implementation to get the
+// authorization code from a redirected url or a plain code.
+// The state of a url must match the authorization request.
+func parseAuthCode(input string, state string) (string, error) {
+	if input == "" {
+		return "", errors.New("no authorization code given")
+	}
+
+	u, err := url.Parse(input)
+	if err != nil || u.RawQuery == "" {
+		return input, nil
+	}
+
+	query := u.Query()
+	if query.Get("state") != state {
+		return "", errors.New("the url does not belong to this setup, please use the printed url")
+	}
+
+	if e := query.Get("error"); e != "" {
+		return "", fmt.Errorf("authorization failed: %s", e)
+	}
+
+	code := query.Get("code")
+	if code == "" {
+		return "", errors.New("the url contains no authorization code")
+	}
+
+	return code, nil
 }
 
 // startAuthentication starts the spotify authentication
-// process.
-func startAuthentication(clientId string, clientSecret string, host string, port int) error {
+// process. Without a browser, the authorization url is
+// opened elsewhere and the code is pasted back.
+func startAuthentication(clientId string, clientSecret string, host string, port int, noBrowser bool, timeout time.Duration, creds *credentials.Config) error {
+	state, err := spotify.NewState()
+	if err != nil {
+		return err
+	}
+
+	// The callback server is started first, so a port
+	// in use is reported before the browser is opened.
+	var cs *callbackServer
+	if !noBrowser {
+		l, err := listenCallback(host, port)
+		if err != nil {
+			return err
+		}
+
+		cs = newCallbackServer(l, state)
+	}
+
 	redirectUrl := fmt.Sprintf("http://%s:%d", host, port)
 
 	sc := spotify.NewAuthClient(
@@ -69,30 +122,65 @@ func startAuthentication(clientId string, clientSecret string, host string, port
 		scopes,
 	)
 
-	addr := net.JoinHostPort(host, strconv.Itoa(port))
-	go startAuthServer(addr)
+	// Without a client secret, the authorization
+	// code is secured with PKCE.
+	var codeVerifier string
+	authUrl := sc.BuildAuthUrl(state)
+	if clientSecret == "" {
+		verifier, err := spotify.NewCodeVerifier()
+		if err != nil {
+			return err
+		}
+
+		codeVerifier = verifier
+		authUrl = sc.BuildPKCEAuthUrl(state, spotify.CodeChallenge(codeVerifier))
+	}
 
-	authUrl := sc.BuildAuthUrl()
-	openBrowser(authUrl)
+	var code string
+	if noBrowser {
+		fmt.Println("\nOpen the following URL in a web browser on any device and log in using your Spotify account:")
+		fmt.Println(authUrl)
+		fmt.Printf("\nAfterwards the browser is redirected to %s, which may fail to load. Copy the url from the address bar.\n", redirectUrl)
 
-	fmt.Println("\nPlease follow the steps in your web browser and log in using your Spotify account. If the URL did not open automatically, please manually open the following URL:")
-	fmt.Println(authUrl)
+		code, err = readAuthCode(stdin, state)
+	} else {
+		cs.Start()
+		openBrowser(authUrl)
 
-	code := <-codeChan
-	shutdownServer()
+		fmt.Println("\nPlease follow the steps in your web browser and log in using your Spotify account. If the URL did not open automatically, please manually open the following URL:")
+		fmt.Println(authUrl)
+
+		code, err = cs.Wait(timeout)
+	}
+
+	if err != nil {
+		return err
+	}
 
-	token, err := sc.GetTokenPair(code)
+	token, err := getTokenPair(sc, code, codeVerifier)
 	if err != nil {
-		fmt.Println(err.Error())
 		return err
 	}
 
-	cfg := config.Config{
-		Spotify: config.SpotifyConfig{
-			ClientID:     clientId,
-			ClientSecret: clientSecret,
-			RefreshToken: token.RefreshToken,
-		},
+	// The credentials of an existing config are replaced,
+	// so the rest of the config file is kept.
+	cfg, err := config.LoadConfig()
+	if config.IsConfigNotExistsErr(err) {
+		cfg, err = config.NewConfig(), nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	cfg.Spotify = config.SpotifyConfig{
+		ClientID:     clientId,
+		ClientSecret: clientSecret,
+		RefreshToken: token.RefreshToken,
+	}
+
+	if creds != nil {
+		cfg.Credentials = *creds
 	}
 
 	return cfg.Write()
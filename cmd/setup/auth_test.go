@@ -0,0 +1,57 @@
+package setup
+
+import "testing"
+
+func TestParseAuthCode(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		code  string
+		err   string
+	}{
+		{
+			name:  "bare code",
+			input: "code",
+			code:  "code",
+		},
+		{
+			name:  "redirect url",
+			input: "http://127.0.0.1:8888/?code=code&state=state",
+			code:  "code",
+		},
+		{
+			name:  "state mismatch",
+			input: "http://127.0.0.1:8888/?code=code&state=other",
+			err:   "the url does not belong to this setup, please use the printed url",
+		},
+		{
+			name:  "authorization error",
+			input: "http://127.0.0.1:8888/?error=access_denied&state=state",
+			err:   "authorization failed: access_denied",
+		},
+		{
+			name:  "missing code",
+			input: "http://127.0.0.1:8888/?state=state",
+			err:   "the url contains no authorization code",
+		},
+		{
+			name: "empty input",
+			err:  "no authorization code given",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			code, err := parseAuthCode(tc.input, "state")
+
+			errStr := ""
+			if err != nil {
+				errStr = err.Error()
+			}
+
+			if code != tc.code || errStr != tc.err {
+				t.Fatalf("unexpected code %q and error %v", code, err)
+			}
+		})
+	}
+}
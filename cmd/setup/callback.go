@@ -0,0 +1,162 @@
+package setup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	callbackPage = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>spofi setup</title></head>
+<body style="font-family: sans-serif; text-align: center; margin-top: 4em;">
+<h2>%s</h2>
+<p>%s</p>
+</body>
+</html>`
+)
+
+type (
+	// callbackResult is the result of the oauth callback,
+	// either an authorization code or an error.
+	callbackResult struct {
+		code string
+		err  error
+	}
+
+	// callbackServer is the http server for the spotify
+	// oauth callback, which accepts only the callback
+	// of the authorization request with its state.
+	callbackServer struct {
+		srv      *http.Server
+		listener net.Listener
+		state    string
+		result   chan callbackResult
+	}
+)
+
+// listenCallback is an internal implementation to listen on the
+// port of the redirect url. Another port would not match the
+// redirect url of the app, so a port in use is an error.
+func listenCallback(host string, port int) (net.Listener, error) {
+	l, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, fmt.Errorf(
+			"could not listen on port %d (%w), stop the program using it or "+
+				"run the setup with --port and the port of a redirect url registered for your app",
+			port, err,
+		)
+	}
+
+	return l, nil
+}
+
+// newCallbackServer creates a callback server
+// which serves the callback on a listener.
+func newCallbackServer(l net.Listener, state string) *callbackServer {
+	cs := &callbackServer{
+		listener: l,
+		state:    state,
+		result:   make(chan callbackResult, 1),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", cs.handleCallback)
+
+	cs.srv = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return cs
+}
+
+// Start starts serving the callback in the background.
+func (cs *callbackServer) Start() {
+	go func() {
+		err := cs.srv.Serve(cs.listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			cs.finish(callbackResult{err: fmt.Errorf("the callback server failed: %w", err)})
+		}
+	}()
+}
+
+// Wait waits for the callback until the timeout
+// expires and shuts the server down.
+func (cs *callbackServer) Wait(timeout time.Duration) (string, error) {
+	defer cs.srv.Shutdown(context.Background())
+
+	select {
+	case res := <-cs.result:
+		return res.code, res.err
+	case <-time.After(timeout):
+		return "", fmt.Errorf("no authorization within %s, please restart the setup", timeout)
+	}
+}
+
+// finish is an internal implementation to
+// report the first result of the callback.
+func (cs *callbackServer) finish(res callbackResult) {
+	select {
+	case cs.result <- res:
+	default:
+	}
+}
+
+// handleCallback is an internal implementation to handle the
+// redirect of spotify. Requests without the state of the
+// authorization request are rejected and do not end the setup.
+func (cs *callbackServer) handleCallback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if query.Get("state") != cs.state {
+		writeCallbackPage(w, http.StatusBadRequest,
+			"Invalid request",
+			"This request does not belong to the running setup. Please use the link printed by spofi setup.",
+		)
+		return
+	}
+
+	if e := query.Get("error"); e != "" {
+		writeCallbackPage(w, http.StatusForbidden,
+			"Authorization failed",
+			fmt.Sprintf("Spotify did not grant access (%s). Please run spofi setup again.", e),
+		)
+		cs.finish(callbackResult{err: fmt.Errorf("authorization failed: %s", e)})
+		return
+	}
+
+	code := query.Get("code")
+	if code == "" {
+		writeCallbackPage(w, http.StatusBadRequest,
+			"Invalid request",
+			"The request contains no authorization code.",
+		)
+		return
+	}
+
+	writeCallbackPage(w, http.StatusOK,
+		"Setup complete",
+		"The setup process is complete! You may now close this window and use spofi to control spotify.",
+	)
+	cs.finish(callbackResult{code: code})
+}
+
+// writeCallbackPage is an internal implementation to
+// write a html page with a title and a message.
+func writeCallbackPage(w http.ResponseWriter, status int, title string, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, callbackPage, html.EscapeString(title), html.EscapeString(message))
+}
@@ -0,0 +1,102 @@
+package setup
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandleCallback(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		code   string
+		err    string
+	}{
+		{
+			name:   "state mismatch",
+			query:  "?state=other&code=code",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "authorization error",
+			query:  "?state=state&error=access_denied",
+			status: http.StatusForbidden,
+			err:    "authorization failed: access_denied",
+		},
+		{
+			name:   "missing code",
+			query:  "?state=state",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "success",
+			query:  "?state=state&code=code",
+			status: http.StatusOK,
+			code:   "code",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := newCallbackServer(nil, "state")
+
+			w := httptest.NewRecorder()
+			cs.handleCallback(w, httptest.NewRequest(http.MethodGet, "/"+tc.query, nil))
+
+			if w.Code != tc.status {
+				t.Fatalf("expected status %d, got %d", tc.status, w.Code)
+			}
+
+			var res callbackResult
+			select {
+			case res = <-cs.result:
+			default:
+			}
+
+			errStr := ""
+			if res.err != nil {
+				errStr = res.err.Error()
+			}
+
+			if res.code != tc.code || errStr != tc.err {
+				t.Fatalf("unexpected result %+v", res)
+			}
+		})
+	}
+}
+
+func TestCallbackServerWait(t *testing.T) {
+	l, err := listenCallback("127.0.0.1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cs := newCallbackServer(l, "state")
+	cs.Start()
+
+	_, err = cs.Wait(10 * time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "no authorization within 10ms") {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+}
+
+func TestListenCallbackPortInUse(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	port := l.Addr().(*net.TCPAddr).Port
+	if _, err := listenCallback("127.0.0.1", port); err == nil ||
+		!strings.Contains(err.Error(), "could not listen on port "+strconv.Itoa(port)) ||
+		!strings.Contains(err.Error(), "--port") {
+		t.Fatalf("expected an error for the port in use, got %v", err)
+	}
+}
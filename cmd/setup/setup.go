@@ -1,11 +1,17 @@
 package setup
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/davidborzek/spofi/internal/config"
+	"github.com/davidborzek/spofi/internal/credentials"
 	"github.com/urfave/cli/v2"
 )
 
@@ -34,28 +40,79 @@ var (
 				Required: false,
 				Value:    "localhost",
 			},
+			&cli.DurationFlag{
+				Name:     "timeout",
+				Usage:    "How long to wait for the authorization in the browser.",
+				Required: false,
+				Value:    5 * time.Minute,
+			},
+			&cli.BoolFlag{
+				Name:     "no-browser",
+				Usage:    "Open the authorization url on another device and paste back the redirected url.",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "client-id",
+				Usage:    "The Client ID of the app, instead of asking for it.",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "client-secret",
+				Usage:    "Read the Client Secret of the app from a file or '-' for stdin (implies --with-secret).",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "with-secret",
+				Usage:    "Use the client secret of the app instead of the PKCE flow.",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "profile",
+				Usage:    "The profile to set up, e.g. for another account.",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "credentials",
+				Usage:    "Where to store the credentials (config, secret-service, command, file).",
+				Required: false,
+				Value:    credentials.BackendConfig,
+			},
+			&cli.StringFlag{
+				Name:     "password-command",
+				Usage:    "The command which prints a credential for the command backend, e.g. 'pass show spofi/{key}'.",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "store-command",
+				Usage:    "The command which stores a credential from stdin for the command backend, e.g. 'pass insert -m -f spofi/{key}'.",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "passphrase-command",
+				Usage:    "The command which prints the passphrase of the file backend (default: $SPOFI_PASSPHRASE).",
+				Required: false,
+			},
 		},
 	}
 
-	qs = []*survey.Question{
-		{
-			Name:     "clientId",
-			Prompt:   &survey.Input{Message: "Enter the Client ID:"},
-			Validate: survey.Required,
-		},
-		{
-			Name:     "clientSecret",
-			Prompt:   &survey.Password{Message: "Click on 'Show Client Secret' and enter the Client Secret:"},
-			Validate: survey.Required,
-		},
+	clientIdQuestion = &survey.Question{
+		Name:     "clientId",
+		Prompt:   &survey.Input{Message: "Enter the Client ID:"},
+		Validate: survey.Required,
+	}
+
+	clientSecretQuestion = &survey.Question{
+		Name:     "clientSecret",
+		Prompt:   &survey.Password{Message: "Click on 'Show Client Secret' and enter the Client Secret:"},
+		Validate: survey.Required,
 	}
 )
 
-// runSurvey runs the survey to ask the user for
-// a client id and a client secret.
-func runSurvey(host string, port int) (*surveyAnswer, error) {
+// runSurvey runs the survey to ask the user for the missing
+// client id and, without the PKCE flow, the client secret.
+func runSurvey(host string, port int, answers *surveyAnswer, withSecret bool) error {
 	fmt.Printf(`Welcome to spofi setup!
-WARNING: If you already have configured spofi, this will overwrite you current configuration.
+WARNING: If you already have configured spofi, this will overwrite the credentials of the profile.
 		
 1) Visit https://developer.spotify.com/dashboard/applications and click on "Create an app".
 2) Enter a name and description.
@@ -63,8 +120,16 @@ WARNING: If you already have configured spofi, this will overwrite you current c
    by clicking on "Add" an save the settings with "Save".
 4) Enter the app details in the following steps.\n`, host, port)
 
-	var answers surveyAnswer
-	err := survey.Ask(qs, &answers, survey.WithIcons(func(is *survey.IconSet) {
+	var qs []*survey.Question
+	if answers.ClientID == "" {
+		qs = append(qs, clientIdQuestion)
+	}
+
+	if withSecret && answers.ClientSecret == "" {
+		qs = append(qs, clientSecretQuestion)
+	}
+
+	err := survey.Ask(qs, answers, survey.WithIcons(func(is *survey.IconSet) {
 		is.Question.Text = ""
 	}))
 	if err != nil {
@@ -73,22 +138,87 @@ WARNING: If you already have configured spofi, this will overwrite you current c
 			os.Exit(0)
 		}
 
-		return nil, err
+		return err
+	}
+
+	return nil
+}
+
+// readClientSecret is an internal implementation to read
+// the client secret from a file or the first line of stdin.
+func readClientSecret(path string) (string, error) {
+	var secret string
+	if path == "-" {
+		line, err := stdin.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", err
+		}
+		secret = line
+	} else {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		secret = string(raw)
+	}
+
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return "", errors.New("the client secret is empty")
 	}
 
-	return &answers, nil
+	return secret, nil
 }
 
 // setup starts a new spofi setup by asking the user
-// for a client id and a client secret and runs
-// the oauth flow.
+// for a client id (and optionally a client secret),
+// unless they are given by flags, and runs the
+// oauth flow.
 func setup(ctx *cli.Context) error {
 	host := ctx.String("host")
 	port := ctx.Int("port")
 
-	answers, err := runSurvey(host, port)
-	if err != nil {
-		return err
+	if ctx.IsSet("profile") {
+		config.SetProfile(ctx.String("profile"))
+	}
+
+	var creds *credentials.Config
+	if ctx.IsSet("credentials") {
+		creds = &credentials.Config{
+			Backend:           ctx.String("credentials"),
+			PasswordCommand:   ctx.String("password-command"),
+			StoreCommand:      ctx.String("store-command"),
+			PassphraseCommand: ctx.String("passphrase-command"),
+		}
+
+		if creds.Backend == credentials.BackendConfig {
+			creds = &credentials.Config{}
+		}
+
+		if creds.External() {
+			// Check the backend before the oauth flow.
+			if _, err := credentials.New(*creds, ""); err != nil {
+				return err
+			}
+		}
+	}
+
+	answers := &surveyAnswer{ClientID: ctx.String("client-id")}
+	if ctx.IsSet("client-secret") {
+		secret, err := readClientSecret(ctx.String("client-secret"))
+		if err != nil {
+			return err
+		}
+		answers.ClientSecret = secret
+	}
+
+	// The survey only asks for the details
+	// which are not given by the flags.
+	withSecret := ctx.Bool("with-secret")
+	if answers.ClientID == "" || (withSecret && answers.ClientSecret == "") {
+		if err := runSurvey(host, port, answers, withSecret); err != nil {
+			return err
+		}
 	}
 
 	if err := startAuthentication(
@@ -96,6 +226,9 @@ func setup(ctx *cli.Context) error {
 		answers.ClientSecret,
 		host,
 		port,
+		ctx.Bool("no-browser"),
+		ctx.Duration("timeout"),
+		creds,
 	); err != nil {
 		return err
 	}
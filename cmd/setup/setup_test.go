@@ -0,0 +1,79 @@
+package setup
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadClientSecret(t *testing.T) {
+	dir := t.TempDir()
+
+	secretFile := filepath.Join(dir, "secret")
+	if err := os.WriteFile(secretFile, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	emptyFile := filepath.Join(dir, "empty")
+	if err := os.WriteFile(emptyFile, []byte(" \n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		stdin  string
+		secret string
+		err    string
+	}{
+		{
+			name:   "file",
+			path:   secretFile,
+			secret: "secret",
+		},
+		{
+			name: "empty file",
+			path: emptyFile,
+			err:  "the client secret is empty",
+		},
+		{
+			name:   "stdin",
+			path:   "-",
+			stdin:  "secret\nignored\n",
+			secret: "secret",
+		},
+		{
+			name:   "stdin without newline",
+			path:   "-",
+			stdin:  "secret",
+			secret: "secret",
+		},
+		{
+			name:  "empty stdin line",
+			path:  "-",
+			stdin: "\n",
+			err:   "the client secret is empty",
+		},
+	}
+
+	defer func(r *bufio.Reader) { stdin = r }(stdin)
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stdin = bufio.NewReader(strings.NewReader(tc.stdin))
+
+			secret, err := readClientSecret(tc.path)
+
+			errStr := ""
+			if err != nil {
+				errStr = err.Error()
+			}
+
+			if secret != tc.secret || errStr != tc.err {
+				t.Fatalf("unexpected secret %q and error %v", secret, err)
+			}
+		})
+	}
+}
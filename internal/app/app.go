@@ -1,7 +1,14 @@
 package app
 
 import (
+	"fmt"
+	"log"
+
+	"github.com/davidborzek/spofi/internal/artwork"
 	"github.com/davidborzek/spofi/internal/config"
+	"github.com/davidborzek/spofi/internal/format"
+	"github.com/davidborzek/spofi/internal/history"
+	"github.com/davidborzek/spofi/internal/library"
 	"github.com/davidborzek/spofi/internal/player"
 	"github.com/davidborzek/spofi/pkg/spotify"
 )
@@ -13,6 +20,46 @@ type App struct {
 	SpotifyClient spotify.Client
 
 	Player player.Player
+
+	// Library is the local cache of the library of the user.
+	Library *library.Library
+
+	// History is the history of the items
+	// which were played through spofi.
+	History *history.History
+
+	// Artwork is the cache of the album art shown as
+	// row icons. It is nil when artwork is disabled.
+	Artwork *artwork.Cache
+}
+
+// tokenOptions is an internal implementation to reuse the
+// saved access token and to save new and rotated tokens.
+func tokenOptions(cfg *config.Config) []spotify.Option {
+	opts := []spotify.Option{
+		spotify.WithTokenHandler(func(token spotify.Token) {
+			if err := cfg.SaveToken(config.AccessToken{
+				Token:  token.AccessToken,
+				Expiry: token.Expiry,
+			}, token.RefreshToken); err != nil {
+				log.Println("failed to save the token:", err)
+			}
+		}),
+	}
+
+	token, err := cfg.LoadAccessToken()
+	if err != nil {
+		log.Println("failed to load the access token:", err)
+	}
+
+	if token != nil {
+		opts = append(opts, spotify.WithToken(spotify.Token{
+			AccessToken: token.Token,
+			Expiry:      token.Expiry,
+		}))
+	}
+
+	return opts
 }
 
 // NewApp creates a new application context
@@ -22,13 +69,80 @@ func NewApp(cfg *config.Config) *App {
 		cfg.Spotify.RefreshToken,
 		cfg.Spotify.ClientID,
 		cfg.Spotify.ClientSecret,
+		tokenOptions(cfg)...,
 	)
 
+	// Profiles have their own library cache and history.
+	profile := ""
+	if cfg.Profile() != config.DefaultProfile {
+		profile = cfg.Profile()
+	}
+
 	a := App{
 		Config:        cfg,
 		SpotifyClient: sp,
-		Player:        player.New(sp, cfg.Device.ID),
+		Library:       library.Open(sp, profile),
+		History:       history.Open(profile),
+	}
+
+	a.Player = history.NewPlayer(
+		player.New(sp, cfg.Device.ID),
+		a.History,
+		a.Describe,
+	)
+
+	if cfg.ShowArtwork {
+		// Artwork is optional, so the rows are
+		// shown without icons when there is no cache dir.
+		if cache, err := artwork.NewCache(); err == nil {
+			a.Artwork = cache
+		}
 	}
 
 	return &a
 }
+
+// Describe describes a played uri for the history
+// using the library cache.
+func (a *App) Describe(uri string) (history.Item, error) {
+	item := history.Item{
+		URI:  uri,
+		Kind: history.KindOf(uri),
+	}
+
+	id := spotify.URIToID(uri)
+
+	switch item.Kind {
+	case history.KindTrack:
+		track, err := a.Library.Track(id)
+		if err != nil {
+			return item, err
+		}
+		item.Name = track.Name
+		item.Detail = format.ArtistNames(track.Artists)
+	case history.KindAlbum:
+		album, err := a.Library.Album(id)
+		if err != nil {
+			return item, err
+		}
+		item.Name = album.Name
+		item.Detail = format.ArtistNames(album.Artists)
+	case history.KindPlaylist:
+		playlist, err := a.Library.Playlist(id)
+		if err != nil {
+			return item, err
+		}
+		item.Name = playlist.Name
+		item.Detail = playlist.Owner.DisplayName
+	case history.KindArtist:
+		artist, err := a.SpotifyClient.GetArtist(id)
+		if err != nil {
+			return item, err
+		}
+		item.Name = artist.Name
+	default:
+		return item, fmt.Errorf("cannot describe %s", uri)
+	}
+
+	return item, nil
+}
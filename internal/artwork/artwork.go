@@ -0,0 +1,162 @@
+package artwork
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/davidborzek/spofi/internal/xdg"
+	"github.com/davidborzek/spofi/pkg/spotify"
+)
+
+const (
+	artworkDirName = "artwork"
+
+	// thumbnailSize is the minimum width of a downloaded
+	// image. Spotify provides covers in 64, 300 and 640px.
+	thumbnailSize = 64
+	// maxDownloads is the maximum number of parallel downloads.
+	maxDownloads = 8
+
+	downloadTimeout = 5 * time.Second
+)
+
+// Cache downloads images (album covers, artist
+// and playlist images) and caches them on disk.
+type Cache struct {
+	dir    string
+	client *http.Client
+}
+
+// NewCache creates a new artwork cache in the
+// os user cache dir.
+func NewCache() (*Cache, error) {
+	dir, err := xdg.CacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return newCache(
+		filepath.Join(dir, artworkDirName),
+		&http.Client{Timeout: downloadTimeout},
+	)
+}
+
+// newCache is an internal implementation to create
+// a cache in a given directory using a given http client.
+func newCache(dir string, client *http.Client) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &Cache{
+		dir:    dir,
+		client: client,
+	}, nil
+}
+
+// thumbnail is an internal implementation to get the url
+// of the smallest image which is at least thumbnailSize wide.
+func thumbnail(images []spotify.Image) string {
+	url := ""
+	width := 0
+	for _, image := range images {
+		if url == "" ||
+			(image.Width >= thumbnailSize && (image.Width < width || width < thumbnailSize)) {
+			url = image.URL
+			width = image.Width
+		}
+	}
+
+	return url
+}
+
+// path is an internal implementation to get the
+// cache path of a image url.
+func (c *Cache) path(url string) string {
+	sum := sha1.Sum([]byte(url))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".jpg")
+}
+
+// download is an internal implementation to download
+// a image to the cache, if it is not already cached.
+func (c *Cache) download(url string) (string, error) {
+	path := c.path(url)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	res, err := c.client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download %s: %s", url, res.Status)
+	}
+
+	// Write to a temporary file first, so a failed or
+	// parallel download never leaves a partial image behind.
+	tmp, err := os.CreateTemp(c.dir, "download-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, res.Body); err != nil {
+		tmp.Close()
+		return "", err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	return path, os.Rename(tmp.Name(), path)
+}
+
+// Paths returns the cached file paths of the thumbnails
+// for each set of images. Missing thumbnails are downloaded
+// in parallel. The path is empty if a set has no images
+// or the download failed.
+func (c *Cache) Paths(images [][]spotify.Image) []string {
+	paths := make([]string, len(images))
+
+	// Group the sets by url, so every image is only downloaded once.
+	indexes := map[string][]int{}
+	for i, set := range images {
+		if url := thumbnail(set); url != "" {
+			indexes[url] = append(indexes[url], i)
+		}
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxDownloads)
+	for url, idx := range indexes {
+		wg.Add(1)
+		go func(url string, idx []int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			path, err := c.download(url)
+			if err != nil {
+				return
+			}
+
+			for _, i := range idx {
+				paths[i] = path
+			}
+		}(url, idx)
+	}
+	wg.Wait()
+
+	return paths
+}
@@ -0,0 +1,103 @@
+package artwork
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/davidborzek/spofi/pkg/spotify"
+)
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		name   string
+		images []spotify.Image
+		want   string
+	}{
+		{
+			name: "no images",
+		},
+		{
+			name: "smallest thumbnail",
+			images: []spotify.Image{
+				{URL: "640", Width: 640},
+				{URL: "300", Width: 300},
+				{URL: "64", Width: 64},
+			},
+			want: "64",
+		},
+		{
+			name: "too small images",
+			images: []spotify.Image{
+				{URL: "32", Width: 32},
+				{URL: "300", Width: 300},
+			},
+			want: "300",
+		},
+		{
+			name:   "unknown size",
+			images: []spotify.Image{{URL: "unknown"}},
+			want:   "unknown",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := thumbnail(tc.images); got != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCachePaths(t *testing.T) {
+	var downloads int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+
+		atomic.AddInt32(&downloads, 1)
+		w.Write([]byte("image " + r.URL.Path))
+	}))
+	t.Cleanup(srv.Close)
+
+	cache, err := newCache(t.TempDir(), srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	images := [][]spotify.Image{
+		{{URL: srv.URL + "/a", Width: 64}},
+		nil,
+		{{URL: srv.URL + "/missing", Width: 64}},
+		{{URL: srv.URL + "/a", Width: 64}},
+	}
+
+	for i := 0; i < 2; i++ {
+		paths := cache.Paths(images)
+		if paths[1] != "" || paths[2] != "" {
+			t.Fatalf("expected no paths for missing images, got %v", paths)
+		}
+
+		if paths[0] == "" || paths[0] != paths[3] {
+			t.Fatalf("expected the same path for the same image, got %v", paths)
+		}
+
+		raw, err := os.ReadFile(paths[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(raw) != "image /a" {
+			t.Fatalf("unexpected image content %q", raw)
+		}
+	}
+
+	if n := atomic.LoadInt32(&downloads); n != 1 {
+		t.Fatalf("expected 1 download, got %d", n)
+	}
+}
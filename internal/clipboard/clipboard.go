@@ -0,0 +1,53 @@
+// Package clipboard copies texts to the clipboard
+// using the clipboard tool of the desktop.
+package clipboard
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ErrNoClipboard is returned when no clipboard tool is installed.
+var ErrNoClipboard = errors.New("no clipboard tool found (install wl-clipboard, xclip or xsel)")
+
+var (
+	// waylandCommands are the clipboard tools for wayland.
+	waylandCommands = [][]string{
+		{"wl-copy"},
+	}
+	// x11Commands are the clipboard tools for x11 and macOS.
+	x11Commands = [][]string{
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+		{"pbcopy"},
+	}
+)
+
+// commands is an internal implementation to get the clipboard
+// tools to try in order. Wayland tools are preferred in a
+// wayland session.
+func commands() [][]string {
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		return append(waylandCommands, x11Commands...)
+	}
+	return append(x11Commands, waylandCommands...)
+}
+
+// Copy copies a text to the clipboard with the first
+// installed clipboard tool.
+func Copy(text string) error {
+	for _, command := range commands() {
+		path, err := exec.LookPath(command[0])
+		if err != nil {
+			continue
+		}
+
+		cmd := exec.Command(path, command[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+
+	return ErrNoClipboard
+}
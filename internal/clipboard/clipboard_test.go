@@ -0,0 +1,47 @@
+package clipboard
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopy(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "clipboard")
+
+	cat, err := exec.LookPath("cat")
+	if err != nil {
+		t.Skip("cat is not installed")
+	}
+
+	script := "#!/bin/sh\n" + cat + " > " + out + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "wl-copy"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("PATH", dir)
+	t.Setenv("WAYLAND_DISPLAY", "wayland-0")
+
+	if err := Copy("https://open.spotify.com/track/1"); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(raw) != "https://open.spotify.com/track/1" {
+		t.Fatalf("unexpected clipboard %q", raw)
+	}
+}
+
+func TestCopyNoClipboard(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	if err := Copy("text"); err != ErrNoClipboard {
+		t.Fatalf("expected ErrNoClipboard, got %v", err)
+	}
+}
@@ -1,23 +1,32 @@
 package config
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
 
+	"github.com/davidborzek/spofi/internal/credentials"
 	"gopkg.in/yaml.v3"
 )
 
 // Default icon set (requires Jetbrains NerdFont)
 const (
+	defaultIconAccount        = "󰀙"
 	defaultIconAlbum          = "󰀥"
+	defaultIconArtist         = "󰠃"
 	defaultIconDevice         = "󰾰"
 	defaultIconLikedTracks    = ""
 	defaultIconNext           = "󰒭"
 	defaultIconPause          = ""
 	defaultIconPlay           = ""
 	defaultIconPlayer         = ""
+	defaultIconPlaylist       = "󰐑"
 	defaultIconPrevious       = "󰒮"
+	defaultIconQuick          = "󱐋"
 	defaultIconQueue          = "󰲸"
 	defaultIconRecentlyPlayed = "󰅐"
 	defaultIconRepeatContext  = "󰑖"
@@ -29,26 +38,19 @@ const (
 	defaultIconTrack          = ""
 )
 
-// Default keybindings
-const (
-	defaultKeyAddToQueue        = "Alt+d"
-	defaultKeyNextPage          = "Alt+Right"
-	defaultKeyNextTrack         = "Alt+n"
-	defaultKeyPlayAlbum         = "Alt+p"
-	defaultKeyPlayTrack         = "Alt+t"
-	defaultKeyPreviousPage      = "Alt+Left"
-	defaultKeyPreviousTrack     = "Alt+p"
-	defaultKeyTogglePauseResume = "Alt+space"
-	defaultKeyToggleRepeat      = "Alt+r"
-	defaultKeyToggleSearchType  = "Alt+s"
-	defaultKeyToggleShuffle     = "Alt+s"
-)
-
 const (
 	configDirName  = "spofi"
 	configFileName = "spofi.yaml"
 )
 
+var (
+	customPath = ""
+
+	// writeMu serializes the writes of the config and state
+	// files, as tokens may be refreshed in the background.
+	writeMu sync.Mutex
+)
+
 // SpotifyDevice represents a saved spotify
 // device in the config.
 type SpotifyDevice struct {
@@ -56,19 +58,37 @@ type SpotifyDevice struct {
 	ID   string `yaml:"id"`
 }
 
-// KeyConfig represent the hotkey configuration.
-type KeyConfig struct {
-	AddToQueue        string `yaml:"addToQueue"`
-	NextPage          string `yaml:"nextPage"`
-	NextTrack         string `yaml:"nextTrack"`
-	PlayAlbum         string `yaml:"playAlbum"`
-	PlayTrack         string `yaml:"playTrack"`
-	PreviousPage      string `yaml:"previousPage"`
-	PreviousTrack     string `yaml:"previousTrack"`
-	TogglePauseResume string `yaml:"togglePauseResume"`
-	ToggleRepeat      string `yaml:"toggleRepeat"`
-	ToggleSearchType  string `yaml:"toggleSearchType"`
-	ToggleShuffle     string `yaml:"toggleShuffle"`
+// Favorite represents an item (album, playlist, artist
+// or track) which is pinned to the main menu.
+type Favorite struct {
+	Name string `yaml:"name"`
+	URI  string `yaml:"uri"`
+}
+
+// MenuEntry represents an entry of the main menu. An entry
+// opens a view, runs a saved search or opens a uri.
+type MenuEntry struct {
+	View   string `yaml:"view,omitempty"`
+	Search string `yaml:"search,omitempty"`
+	URI    string `yaml:"uri,omitempty"`
+	Title  string `yaml:"title,omitempty"`
+	Icon   string `yaml:"icon,omitempty"`
+}
+
+// MenuConfig represents the layout of the main menu.
+type MenuConfig struct {
+	// Entries are the entries of the main menu in order.
+	// Views which are not listed are hidden.
+	Entries []MenuEntry `yaml:"entries,omitempty"`
+}
+
+// FormatConfig represents the row formats. A format is a
+// go text/template, empty formats use the default layout.
+type FormatConfig struct {
+	Track    string `yaml:"track,omitempty"`
+	Album    string `yaml:"album,omitempty"`
+	Playlist string `yaml:"playlist,omitempty"`
+	Episode  string `yaml:"episode,omitempty"`
 }
 
 type SpotifyConfig struct {
@@ -78,15 +98,19 @@ type SpotifyConfig struct {
 }
 
 type IconConfig struct {
+	Account        string `yaml:"account"`
 	Album          string `yaml:"album"`
+	Artist         string `yaml:"artist"`
 	Device         string `yaml:"device"`
 	LikedTracks    string `yaml:"likedTracks"`
 	Next           string `yaml:"next"`
 	Pause          string `yaml:"pause"`
 	Play           string `yaml:"play"`
 	Player         string `yaml:"player"`
+	Playlist       string `yaml:"playlist"`
 	Previous       string `yaml:"previous"`
 	Queue          string `yaml:"queue"`
+	Quick          string `yaml:"quick"`
 	RecentlyPlayed string `yaml:"recentlyPlayed"`
 	RepeatContext  string `yaml:"repeatContext"`
 	RepeatOff      string `yaml:"repeatOff"`
@@ -99,12 +123,41 @@ type IconConfig struct {
 
 // Config represent the application config.
 type Config struct {
-	Spotify         SpotifyConfig `yaml:"spotify"`
-	Device          SpotifyDevice `yaml:"device"`
-	Theme           string        `yaml:"theme"`
-	Keybindings     KeyConfig     `yaml:"keybindings"`
-	Icons           IconConfig    `yaml:"icons"`
-	ShowKeybindings bool          `yaml:"showKeybindings"`
+	Spotify         SpotifyConfig      `yaml:"spotify"`
+	Credentials     credentials.Config `yaml:"credentials,omitempty"`
+	Device          SpotifyDevice      `yaml:"device"`
+	Theme           string             `yaml:"theme"`
+	MenuBackend     string             `yaml:"menuBackend"`
+	Keybindings     Keybindings        `yaml:"keybindings,omitempty"`
+	Icons           IconConfig         `yaml:"icons"`
+	Favorites       []Favorite         `yaml:"favorites"`
+	Menu            MenuConfig         `yaml:"menu,omitempty"`
+	Formats         FormatConfig       `yaml:"formats,omitempty"`
+	ShowKeybindings bool               `yaml:"showKeybindings"`
+	ShowArtwork     bool               `yaml:"showArtwork"`
+	Profiles        map[string]Profile `yaml:"profiles,omitempty"`
+
+	// profile is the name of the loaded profile,
+	// empty for the default profile.
+	profile string
+	// ownClient is set, when the profile has another
+	// client than the top level (e.g. with PKCE), so
+	// it does not share the client secret.
+	ownClient bool
+	// overrides are the fields which are set by
+	// environment variables.
+	overrides []override
+	// store is the external credentials backend of storeConfig
+	// and stored are the credentials which are stored in it.
+	store       credentials.Store
+	storeConfig credentials.Config
+	stored      map[string]string
+	// state is the state file of the runtime state.
+	state *State
+	// doc is the yaml document of the config file and
+	// written is the config which is in the document.
+	doc     yaml.Node
+	written *Config
 }
 
 // getConfigDir is an internal implementation
@@ -119,14 +172,37 @@ func getConfigDir() (string, error) {
 	return fmt.Sprintf("%s/%s", userConfigDir, configDirName), nil
 }
 
-// LoadConfig load the config at the given path.
-func LoadConfig() (*Config, error) {
+// SetPath globally sets a custom path of the config file.
+func SetPath(path string) {
+	customPath = path
+}
+
+// Path returns the path of the config file. Without a
+// custom path it is $XDG_CONFIG_HOME/spofi/spofi.yaml.
+func Path() (string, error) {
+	if customPath != "" {
+		return customPath, nil
+	}
+
 	cfgPath, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cfgPath, configFileName), nil
+}
+
+// LoadConfig loads the config file with the fields of the
+// selected profile, reads the credentials from the configured
+// backend and overrides the fields with the SPOFI_* environment
+// variables.
+func LoadConfig() (*Config, error) {
+	path, err := Path()
 	if err != nil {
 		return nil, err
 	}
 
-	rawCfg, err := os.ReadFile(fmt.Sprintf("%s/%s", cfgPath, configFileName))
+	rawCfg, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
@@ -136,65 +212,50 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
-	cfg.fillDefaults()
-
-	return &cfg, nil
-}
-
-// IsConfigNotExistsErr checks if the error
-// from LoadConfig means that the config file
-// does not exist.
-func IsConfigNotExistsErr(err error) bool {
-	return errors.Is(err, os.ErrNotExist)
-}
-
-func (cfg *KeyConfig) fillDefaults() {
-	if cfg.AddToQueue == "" {
-		cfg.AddToQueue = defaultKeyAddToQueue
-	}
-
-	if cfg.NextPage == "" {
-		cfg.NextPage = defaultKeyNextPage
-	}
-
-	if cfg.PreviousPage == "" {
-		cfg.PreviousPage = defaultKeyPreviousPage
+	if err := cfg.parseDocument(rawCfg); err != nil {
+		return nil, err
 	}
 
-	if cfg.TogglePauseResume == "" {
-		cfg.TogglePauseResume = defaultKeyTogglePauseResume
-	}
+	cfg.applyProfile(profile)
 
-	if cfg.NextTrack == "" {
-		cfg.NextTrack = defaultKeyNextTrack
+	state, err := cfg.openState()
+	if err != nil {
+		return nil, err
 	}
 
-	if cfg.PreviousTrack == "" {
-		cfg.PreviousTrack = defaultKeyPreviousTrack
+	// The device selected in the menu takes
+	// precedence over the device of the config file.
+	if state.Device.ID != "" {
+		cfg.Device = state.Device
 	}
 
-	if cfg.PlayAlbum == "" {
-		cfg.PlayAlbum = defaultKeyPlayAlbum
+	// The environment is applied before the credentials
+	// are loaded, so it can select the credentials backend.
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
 	}
 
-	if cfg.PlayTrack == "" {
-		cfg.PlayTrack = defaultKeyPlayTrack
+	if err := cfg.loadCredentials(); err != nil {
+		return nil, err
 	}
 
-	if cfg.ToggleSearchType == "" {
-		cfg.ToggleSearchType = defaultKeyToggleSearchType
-	}
+	cfg.fillDefaults()
 
-	if cfg.ToggleRepeat == "" {
-		cfg.ToggleRepeat = defaultKeyToggleRepeat
-	}
+	return &cfg, nil
+}
 
-	if cfg.ToggleShuffle == "" {
-		cfg.ToggleShuffle = defaultKeyToggleShuffle
-	}
+// IsConfigNotExistsErr checks if the error
+// from LoadConfig means that the config file
+// does not exist.
+func IsConfigNotExistsErr(err error) bool {
+	return errors.Is(err, os.ErrNotExist)
 }
 
 func (cfg *IconConfig) fillDefaults() {
+	if cfg.Account == "" {
+		cfg.Account = defaultIconAccount
+	}
+
 	if cfg.Album == "" {
 		cfg.Album = defaultIconAlbum
 	}
@@ -203,6 +264,10 @@ func (cfg *IconConfig) fillDefaults() {
 		cfg.Track = defaultIconTrack
 	}
 
+	if cfg.Artist == "" {
+		cfg.Artist = defaultIconArtist
+	}
+
 	if cfg.Device == "" {
 		cfg.Device = defaultIconDevice
 	}
@@ -243,6 +308,10 @@ func (cfg *IconConfig) fillDefaults() {
 		cfg.Player = defaultIconPlayer
 	}
 
+	if cfg.Playlist == "" {
+		cfg.Playlist = defaultIconPlaylist
+	}
+
 	if cfg.Next == "" {
 		cfg.Next = defaultIconNext
 	}
@@ -255,6 +324,10 @@ func (cfg *IconConfig) fillDefaults() {
 		cfg.Queue = defaultIconQueue
 	}
 
+	if cfg.Quick == "" {
+		cfg.Quick = defaultIconQuick
+	}
+
 	if cfg.RecentlyPlayed == "" {
 		cfg.RecentlyPlayed = defaultIconRecentlyPlayed
 	}
@@ -265,10 +338,34 @@ func (cfg *IconConfig) fillDefaults() {
 }
 
 func (cfg *Config) fillDefaults() {
-	cfg.Keybindings.fillDefaults()
 	cfg.Icons.fillDefaults()
 }
 
+// IsFavorite checks if an item is pinned to the main menu.
+func (cfg *Config) IsFavorite(uri string) bool {
+	for _, f := range cfg.Favorites {
+		if f.URI == uri {
+			return true
+		}
+	}
+	return false
+}
+
+// ToggleFavorite pins an item to the main menu or removes
+// the pin when it is already pinned. It returns true when
+// the item was pinned.
+func (cfg *Config) ToggleFavorite(favorite Favorite) bool {
+	for i, f := range cfg.Favorites {
+		if f.URI == favorite.URI {
+			cfg.Favorites = append(cfg.Favorites[:i:i], cfg.Favorites[i+1:]...)
+			return false
+		}
+	}
+
+	cfg.Favorites = append(cfg.Favorites, favorite)
+	return true
+}
+
 // IsConfigIncomplete checks if the config is incomplete.
 func (cfg *Config) IsConfigIncomplete() bool {
 	return cfg.Spotify.ClientID == "" &&
@@ -276,24 +373,87 @@ func (cfg *Config) IsConfigIncomplete() bool {
 		cfg.Spotify.RefreshToken == ""
 }
 
-// Write writes the current (in-memory) configuration to the config file.
+// parseDocument is an internal implementation to keep the yaml
+// document of the config file, so writes only change the keys
+// which were changed.
+func (cfg *Config) parseDocument(raw []byte) error {
+	if err := yaml.Unmarshal(raw, &cfg.doc); err != nil {
+		return err
+	}
+
+	var written Config
+	if err := cfg.doc.Decode(&written); err != nil && len(cfg.doc.Content) > 0 {
+		return err
+	}
+	written.fillDefaults()
+
+	cfg.written = &written
+	return nil
+}
+
+// Write writes the changes of the configuration to the config file.
+// Only the changed keys are updated, so comments and the layout of the
+// file are kept. Values of environment variables are not written, the
+// credentials and the selected device are written to their backend or
+// the state file.
 func (cfg *Config) Write() error {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
 	cfg.fillDefaults()
 
-	raw, err := yaml.Marshal(cfg)
+	written := cfg.written
+	if written == nil {
+		written = &Config{}
+		written.fillDefaults()
+	}
+
+	file := cfg.withoutEnv()
+	file.Device = written.Device
+	if err := cfg.writeCredentials(file); err != nil {
+		return err
+	}
+	cfg.splitProfile(file, written)
+
+	err := updateMapping(
+		documentMapping(&cfg.doc),
+		reflect.ValueOf(written).Elem(),
+		reflect.ValueOf(file).Elem(),
+	)
 	if err != nil {
 		return err
 	}
 
-	cfgPath, err := getConfigDir()
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&cfg.doc); err != nil {
+		return err
+	}
+
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	path, err := Path()
 	if err != nil {
 		return err
 	}
 
-	if err := os.MkdirAll(cfgPath, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		fmt.Println(err)
 		return err
 	}
 
-	return os.WriteFile(fmt.Sprintf("%s/%s", cfgPath, configFileName), raw, 0644)
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+
+	// The config file may contain credentials, so
+	// existing files are made private as well.
+	if err := os.Chmod(path, 0600); err != nil {
+		return err
+	}
+
+	return cfg.parseDocument(buf.Bytes())
 }
@@ -0,0 +1,532 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEnvName(t *testing.T) {
+	tests := map[string]string{
+		"theme":             "SPOFI_THEME",
+		"spotify.clientId":  "SPOFI_SPOTIFY_CLIENT_ID",
+		"device.id":         "SPOFI_DEVICE_ID",
+		"icons.likedTracks": "SPOFI_ICONS_LIKED_TRACKS",
+		"showArtwork":       "SPOFI_SHOW_ARTWORK",
+	}
+
+	for key, want := range tests {
+		if name := EnvName(key); name != want {
+			t.Errorf("unexpected name %q for %s, want %q", name, key, want)
+		}
+	}
+}
+
+func writeTestConfig(t *testing.T, raw string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", filepath.Join(dir, "state"))
+
+	path := filepath.Join(dir, "config", "spofi.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(raw), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	SetPath(path)
+	t.Cleanup(func() { SetPath("") })
+}
+
+func TestLoadConfigEnv(t *testing.T) {
+	writeTestConfig(t, `
+spotify:
+  clientId: file-id
+  clientSecret: file-secret
+theme: file.rasi
+`)
+
+	t.Setenv("SPOFI_SPOTIFY_CLIENT_SECRET", "env-secret")
+	t.Setenv("SPOFI_DEVICE_ID", "device-1")
+	t.Setenv("SPOFI_THEME", "")
+	t.Setenv("SPOFI_SHOW_ARTWORK", "true")
+	t.Setenv("SPOFI_KEYBINDINGS", "{queue: Alt+q, album.pin: [Alt+1, Alt+2]}")
+	t.Setenv("SPOFI_ICONS_ALBUM", "A")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Spotify.ClientID != "file-id" || cfg.Spotify.ClientSecret != "env-secret" {
+		t.Errorf("unexpected spotify config %+v", cfg.Spotify)
+	}
+
+	if cfg.Device.ID != "device-1" || cfg.Theme != "file.rasi" || !cfg.ShowArtwork || cfg.Icons.Album != "A" {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+
+	want := Keybindings{"queue": {"Alt+q"}, "album.pin": {"Alt+1", "Alt+2"}}
+	if !reflect.DeepEqual(cfg.Keybindings, want) {
+		t.Errorf("unexpected keybindings %v", cfg.Keybindings)
+	}
+}
+
+func TestLoadConfigInvalidEnv(t *testing.T) {
+	writeTestConfig(t, "theme: file.rasi\n")
+	t.Setenv("SPOFI_SHOW_ARTWORK", "sometimes")
+
+	if _, err := LoadConfig(); err == nil {
+		t.Fatal("expected an error for an invalid value")
+	}
+}
+
+func TestWriteWithoutEnv(t *testing.T) {
+	writeTestConfig(t, `
+spotify:
+  clientId: file-id
+  clientSecret: file-secret
+device:
+  id: file-device
+`)
+
+	t.Setenv("SPOFI_SPOTIFY_CLIENT_SECRET", "env-secret")
+	t.Setenv("SPOFI_DEVICE_ID", "env-device")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Theme = "theme.rasi"
+	if err := cfg.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Unsetenv("SPOFI_SPOTIFY_CLIENT_SECRET")
+	os.Unsetenv("SPOFI_DEVICE_ID")
+
+	written, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if written.Spotify.ClientSecret != "file-secret" {
+		t.Errorf("expected the secret of the environment not to be written, got %q", written.Spotify.ClientSecret)
+	}
+
+	if written.Theme != "theme.rasi" || written.Device.ID != "file-device" {
+		t.Errorf("unexpected config %+v", written)
+	}
+
+	if cfg.Spotify.ClientSecret != "env-secret" {
+		t.Errorf("expected the in-memory config to keep the environment, got %q", cfg.Spotify.ClientSecret)
+	}
+}
+
+func TestCredentialsMigration(t *testing.T) {
+	dir := t.TempDir()
+	writeTestConfig(t, `
+spotify:
+  clientId: file-id
+  clientSecret: file-secret
+  refreshToken: file-token
+credentials:
+  backend: command
+  passwordCommand: cat `+dir+`/{key}
+  storeCommand: cat > `+dir+`/{key}
+`)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Spotify.ClientSecret != "file-secret" || cfg.Spotify.RefreshToken != "file-token" {
+		t.Fatalf("unexpected spotify config %+v", cfg.Spotify)
+	}
+
+	path, _ := Path()
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(raw), "file-secret") || strings.Contains(string(raw), "file-token") {
+		t.Fatalf("expected the credentials to be removed from the config file, got %s", raw)
+	}
+
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("expected a private config file, got %v (%v)", info.Mode(), err)
+	}
+
+	// A rotated refresh token is written to the backend.
+	cfg.Spotify.RefreshToken = "new-token"
+	if err := cfg.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Spotify.ClientID != "file-id" || loaded.Spotify.ClientSecret != "file-secret" || loaded.Spotify.RefreshToken != "new-token" {
+		t.Fatalf("unexpected spotify config %+v", loaded.Spotify)
+	}
+}
+
+func TestCredentialsBackendEnv(t *testing.T) {
+	dir := t.TempDir()
+	raw := "spotify:\n  clientId: file-id\n"
+	writeTestConfig(t, raw)
+
+	for key, value := range map[string]string{"clientSecret": "stored-secret", "refreshToken": "stored-token"} {
+		if err := os.WriteFile(filepath.Join(dir, key), []byte(value), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Setenv("SPOFI_CREDENTIALS_BACKEND", "command")
+	t.Setenv("SPOFI_CREDENTIALS_PASSWORD_COMMAND", "cat "+dir+"/{key}")
+	t.Setenv("SPOFI_SPOTIFY_CLIENT_SECRET", "env-secret")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The secret of the environment takes precedence over the backend.
+	if cfg.Spotify.ClientSecret != "env-secret" || cfg.Spotify.RefreshToken != "stored-token" {
+		t.Fatalf("unexpected spotify config %+v", cfg.Spotify)
+	}
+
+	path, _ := Path()
+	if written, err := os.ReadFile(path); err != nil || string(written) != raw {
+		t.Fatalf("expected the config file to be unchanged, got %s (%v)", written, err)
+	}
+}
+
+func TestWriteKeepsComments(t *testing.T) {
+	writeTestConfig(t, `# spofi config
+spotify:
+  clientId: id # from the dashboard
+  clientSecret: secret
+theme: theme.rasi # dark
+keybindings:
+  queue: Alt+q
+`)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.ToggleFavorite(Favorite{Name: "Focus", URI: "spotify:playlist:focus"})
+	cfg.Theme = "light.rasi"
+	if err := cfg.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	path, _ := Path()
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# spofi config
+spotify:
+  clientId: id # from the dashboard
+  clientSecret: secret
+theme: light.rasi # dark
+keybindings:
+  queue: Alt+q
+favorites:
+  - name: Focus
+    uri: spotify:playlist:focus
+`
+	if string(raw) != want {
+		t.Fatalf("unexpected config file:\n%s", raw)
+	}
+}
+
+func TestState(t *testing.T) {
+	writeTestConfig(t, `
+spotify:
+  clientId: id
+  clientSecret: secret
+  refreshToken: token
+device:
+  id: default
+`)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SelectDevice(SpotifyDevice{Name: "Phone", ID: "phone"}); err != nil {
+		t.Fatal(err)
+	}
+
+	path, _ := Path()
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The refresh token was moved to the state file
+	// and the device is not written to the config file.
+	if string(raw) != "spotify:\n  clientId: id\n  clientSecret: secret\ndevice:\n  id: default\n" {
+		t.Fatalf("unexpected config file:\n%s", raw)
+	}
+
+	loaded, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Spotify.RefreshToken != "token" || loaded.Device.ID != "phone" {
+		t.Fatalf("unexpected config %+v", loaded)
+	}
+
+	state, err := loadState("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state.RefreshToken != "token" || state.Device.Name != "Phone" {
+		t.Fatalf("unexpected state %+v", state)
+	}
+
+	// The access token is kept for the next start and a
+	// rotated refresh token is saved in the state file.
+	expiry := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := loaded.SaveToken(AccessToken{Token: "access", Expiry: expiry}, "rotated"); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err = loadState("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state.RefreshToken != "rotated" || state.Device.Name != "Phone" {
+		t.Fatalf("unexpected state %+v", state)
+	}
+
+	next, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := next.LoadAccessToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token == nil || token.Token != "access" || !token.Expiry.Equal(expiry) {
+		t.Fatalf("unexpected access token %+v", token)
+	}
+
+	// A new refresh token of the setup drops the access token.
+	next.Spotify.RefreshToken = "other"
+	if err := next.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err = loadState("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state.RefreshToken != "other" || state.AccessToken != nil {
+		t.Fatalf("unexpected state %+v", state)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	writeTestConfig(t, `
+spotify:
+  clientId: id
+  clientSecret: secret
+theme: default.rasi
+keybindings:
+  queue: Alt+q
+profiles:
+  work:
+    device:
+      id: office
+    theme: work.rasi
+    keybindings:
+      pin: Alt+x
+`)
+
+	SetProfile("work")
+	t.Cleanup(func() { SetProfile("") })
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Profile() != "work" || cfg.Spotify.ClientID != "id" || cfg.Spotify.RefreshToken != "" {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+
+	if cfg.Theme != "work.rasi" || cfg.Device.ID != "office" {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+
+	want := Keybindings{"queue": {"Alt+q"}, "pin": {"Alt+x"}}
+	if !reflect.DeepEqual(cfg.Keybindings, want) {
+		t.Fatalf("unexpected keybindings %v", cfg.Keybindings)
+	}
+
+	if names := cfg.ProfileNames(); !reflect.DeepEqual(names, []string{DefaultProfile, "work"}) {
+		t.Fatalf("unexpected profiles %v", names)
+	}
+
+	// The refresh token of the setup is written
+	// to the state of the profile.
+	cfg.Spotify.RefreshToken = "work-token"
+	if err := cfg.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := loadState("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state.RefreshToken != "work-token" {
+		t.Fatalf("unexpected state %+v", state)
+	}
+
+	SetProfile(DefaultProfile)
+	loaded, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Profile() != DefaultProfile || loaded.Theme != "default.rasi" || loaded.Spotify.RefreshToken != "" {
+		t.Fatalf("unexpected default profile %+v", loaded)
+	}
+}
+
+func TestSetupProfile(t *testing.T) {
+	writeTestConfig(t, `# accounts
+spotify:
+  clientId: id
+  clientSecret: secret
+`)
+
+	SetProfile("kids")
+	t.Cleanup(func() { SetProfile("") })
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.HasProfile("kids") {
+		t.Fatal("expected the profile not to exist before the setup")
+	}
+
+	cfg.Spotify = SpotifyConfig{ClientID: "kids-id", ClientSecret: "secret", RefreshToken: "kids-token"}
+	if err := cfg.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	path, _ := Path()
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# accounts
+spotify:
+  clientId: id
+  clientSecret: secret
+profiles:
+  kids:
+    spotify:
+      clientId: kids-id
+      clientSecret: secret
+`
+	if string(raw) != want {
+		t.Fatalf("unexpected config file:\n%s", raw)
+	}
+
+	loaded, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !loaded.HasProfile("kids") || loaded.Spotify != cfg.Spotify {
+		t.Fatalf("unexpected config %+v", loaded)
+	}
+}
+
+func TestProfileOwnClient(t *testing.T) {
+	dir := t.TempDir()
+	writeTestConfig(t, `
+spotify:
+  clientId: id
+  clientSecret: top-secret
+credentials:
+  backend: command
+  passwordCommand: cat `+dir+`/{key} 2>/dev/null || true
+  storeCommand: cat > `+dir+`/{key}
+profiles:
+  shared:
+    spotify:
+      clientId: id
+  work:
+    spotify:
+      clientId: work-id
+`)
+	t.Cleanup(func() { SetProfile("") })
+
+	for _, profile := range []string{"shared", "work"} {
+		if err := os.Mkdir(filepath.Join(dir, profile), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for key, value := range map[string]string{"clientSecret": "stored-secret", "work/refreshToken": "work-token"} {
+		if err := os.WriteFile(filepath.Join(dir, key), []byte(value), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		profile string
+		want    SpotifyConfig
+	}{
+		// A profile with the client of the top level shares its secret.
+		{"shared", SpotifyConfig{ClientID: "id", ClientSecret: "top-secret"}},
+		// A profile with its own client (e.g. with PKCE) has no secret,
+		// neither from the config file nor from the credentials backend.
+		{"work", SpotifyConfig{ClientID: "work-id", RefreshToken: "work-token"}},
+	}
+
+	for _, tc := range tests {
+		SetProfile(tc.profile)
+
+		cfg, err := LoadConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if cfg.Spotify != tc.want {
+			t.Errorf("unexpected spotify config of %s: %+v", tc.profile, cfg.Spotify)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/davidborzek/spofi/internal/credentials"
+)
+
+// secrets is an internal implementation to get the
+// fields of the credentials which are not stored
+// in the config file with an external backend.
+func (cfg *Config) secrets() map[string]*string {
+	return map[string]*string{
+		credentials.KeyClientSecret: &cfg.Spotify.ClientSecret,
+		credentials.KeyRefreshToken: &cfg.Spotify.RefreshToken,
+	}
+}
+
+// credentialKey is an internal implementation to get the key
+// of a credential in the store, e.g. work/refreshToken for
+// the profile work.
+func (cfg *Config) credentialKey(key string) string {
+	if cfg.profile == "" {
+		return key
+	}
+	return cfg.profile + "/" + key
+}
+
+// openStore is an internal implementation to open the
+// store of the configured credentials backend once.
+func (cfg *Config) openStore() (credentials.Store, error) {
+	// The backend may have been changed, e.g. by the setup.
+	if cfg.store != nil && cfg.storeConfig == cfg.Credentials {
+		return cfg.store, nil
+	}
+
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := credentials.New(cfg.Credentials, filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.store = store
+	cfg.storeConfig = cfg.Credentials
+	cfg.stored = map[string]string{}
+	return store, nil
+}
+
+// loadCredentials is an internal implementation to read
+// the credentials from an external backend. Credentials
+// which are still in the config file are moved to the
+// backend and removed from the file. Credentials of
+// environment variables take precedence and are not moved.
+func (cfg *Config) loadCredentials() error {
+	if !cfg.Credentials.External() {
+		return cfg.loadRefreshToken()
+	}
+
+	store, err := cfg.openStore()
+	if err != nil {
+		return err
+	}
+
+	file := cfg.withoutEnv().secrets()
+
+	migrate := false
+	for key, value := range cfg.secrets() {
+		if *file[key] != "" {
+			migrate = true
+		}
+
+		if *value != "" {
+			continue
+		}
+
+		stored, err := store.Get(cfg.credentialKey(key))
+		if errors.Is(err, credentials.ErrNotFound) && cfg.profile != "" && !cfg.ownClient && key == credentials.KeyClientSecret {
+			// Profiles share the client of the default profile,
+			// unless they have their own client.
+			stored, err = store.Get(key)
+		}
+
+		if errors.Is(err, credentials.ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		*value = stored
+		cfg.stored[key] = stored
+	}
+
+	if migrate {
+		return cfg.Write()
+	}
+
+	return nil
+}
+
+// writeCredentials is an internal implementation to store
+// the changed credentials in the external backend and
+// remove them from the config which is written to the file.
+func (cfg *Config) writeCredentials(file *Config) error {
+	if !cfg.Credentials.External() {
+		return cfg.writeRefreshToken(file)
+	}
+
+	store, err := cfg.openStore()
+	if err != nil {
+		return err
+	}
+
+	for key, value := range file.secrets() {
+		if *value != "" && *value != cfg.stored[key] {
+			if err := store.Set(cfg.credentialKey(key), *value); err != nil {
+				return err
+			}
+			cfg.stored[key] = *value
+
+			if key == credentials.KeyRefreshToken {
+				if err := cfg.clearAccessToken(); err != nil {
+					return err
+				}
+			}
+		}
+
+		*value = ""
+	}
+
+	return nil
+}
+
+// loadRefreshToken is an internal implementation to read the
+// refresh token from the state file, when the credentials are
+// kept in the config file. A refresh token of the config file is
+// moved to the state file.
+func (cfg *Config) loadRefreshToken() error {
+	state, err := cfg.openState()
+	if err != nil {
+		return err
+	}
+
+	if cfg.withoutEnv().Spotify.RefreshToken != "" {
+		return cfg.Write()
+	}
+
+	if cfg.Spotify.RefreshToken == "" {
+		cfg.Spotify.RefreshToken = state.RefreshToken
+	}
+	return nil
+}
+
+// writeRefreshToken is an internal implementation to write a
+// changed refresh token to the state file and remove it from
+// the config which is written to the file.
+func (cfg *Config) writeRefreshToken(file *Config) error {
+	state, err := cfg.openState()
+	if err != nil {
+		return err
+	}
+
+	if token := file.Spotify.RefreshToken; token != "" && token != state.RefreshToken {
+		state.RefreshToken = token
+		state.AccessToken = nil
+		if err := state.write(); err != nil {
+			return err
+		}
+	}
+
+	file.Spotify.RefreshToken = ""
+	return nil
+}
+
+// saveRefreshToken is an internal implementation to save a
+// rotated refresh token in the credentials backend or in the
+// state, without writing the config file.
+func (cfg *Config) saveRefreshToken(state *State, token string) error {
+	cfg.Spotify.RefreshToken = token
+
+	if !cfg.Credentials.External() {
+		state.RefreshToken = token
+		return nil
+	}
+
+	store, err := cfg.openStore()
+	if err != nil {
+		return err
+	}
+
+	if err := store.Set(cfg.credentialKey(credentials.KeyRefreshToken), token); err != nil {
+		return err
+	}
+
+	cfg.stored[credentials.KeyRefreshToken] = token
+	return nil
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix is the prefix of the environment
+// variables which override the config file.
+const envPrefix = "SPOFI_"
+
+// override is a field of the config which
+// is overridden by an environment variable.
+type override struct {
+	index []int
+	file  reflect.Value
+	value reflect.Value
+}
+
+// EnvName returns the name of the environment variable
+// of a config key, e.g. "spotify.clientId" is
+// SPOFI_SPOTIFY_CLIENT_ID.
+func EnvName(key string) string {
+	var b strings.Builder
+	b.WriteString(envPrefix)
+
+	for i, part := range strings.Split(key, ".") {
+		if i > 0 {
+			b.WriteByte('_')
+		}
+
+		for j, r := range part {
+			if j > 0 && unicode.IsUpper(r) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToUpper(r))
+		}
+	}
+
+	return b.String()
+}
+
+// yamlName is an internal implementation to get the
+// key of a struct field in the config file.
+func yamlName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "" {
+		return strings.ToLower(field.Name[:1]) + field.Name[1:]
+	}
+	return name
+}
+
+// applyEnv is an internal implementation to override the
+// fields of the config with the environment variables.
+// Nested keys are joined, e.g. SPOFI_ICONS_ALBUM. Strings
+// are used as is, other values are parsed as yaml (e.g.
+// SPOFI_SHOW_ARTWORK=true or SPOFI_KEYBINDINGS='{queue: Alt+q}').
+func (cfg *Config) applyEnv(lookup func(string) (string, bool)) error {
+	cfg.overrides = nil
+	return cfg.applyEnvFields(lookup, reflect.ValueOf(cfg).Elem(), nil, "")
+}
+
+func (cfg *Config) applyEnvFields(
+	lookup func(string) (string, bool),
+	v reflect.Value,
+	index []int,
+	prefix string,
+) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Tag.Get("yaml") == "-" {
+			continue
+		}
+
+		key := yamlName(field)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		fieldIndex := append(index[:len(index):len(index)], i)
+		fv := v.Field(i)
+
+		if fv.Kind() == reflect.Struct {
+			if err := cfg.applyEnvFields(lookup, fv, fieldIndex, key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := EnvName(key)
+		raw, ok := lookup(name)
+		if !ok || raw == "" {
+			continue
+		}
+
+		value := reflect.New(fv.Type()).Elem()
+		if value.Kind() == reflect.String {
+			value.SetString(raw)
+		} else if err := yaml.Unmarshal([]byte(raw), value.Addr().Interface()); err != nil {
+			return fmt.Errorf("invalid value of %s: %w", name, err)
+		}
+
+		file := reflect.New(fv.Type()).Elem()
+		file.Set(fv)
+
+		fv.Set(value)
+		cfg.overrides = append(cfg.overrides, override{
+			index: fieldIndex,
+			file:  file,
+			value: value,
+		})
+	}
+
+	return nil
+}
+
+// withoutEnv is an internal implementation to get a copy of
+// the config for the config file. Fields which still have
+// the value of an environment variable get their value from
+// the file back, so overrides (e.g. secrets) are not written.
+func (cfg *Config) withoutEnv() *Config {
+	c := *cfg
+	v := reflect.ValueOf(&c).Elem()
+
+	for _, o := range cfg.overrides {
+		field := v.FieldByIndex(o.index)
+		if reflect.DeepEqual(field.Interface(), o.value.Interface()) {
+			field.Set(o.file)
+		}
+	}
+
+	return &c
+}
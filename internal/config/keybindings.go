@@ -0,0 +1,65 @@
+package config
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// keyNone disables an action in the config.
+const keyNone = "none"
+
+// KeyList is a list of keys bound to an action. In the config
+// it is a single key, a list of keys or "none" (or empty) to
+// disable the action.
+type KeyList []string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (k *KeyList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		key := strings.TrimSpace(value.Value)
+		if key == "" || key == keyNone {
+			*k = KeyList{}
+			return nil
+		}
+
+		*k = KeyList{key}
+		return nil
+	}
+
+	var keys []string
+	if err := value.Decode(&keys); err != nil {
+		return err
+	}
+
+	*k = KeyList(keys)
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (k KeyList) MarshalYAML() (interface{}, error) {
+	switch len(k) {
+	case 0:
+		return keyNone, nil
+	case 1:
+		return k[0], nil
+	}
+
+	return []string(k), nil
+}
+
+// Keybindings are the configured keys by action (e.g. "queue"),
+// optionally scoped to a view (e.g. "album.play-album"). Actions
+// which are not configured use their default keys.
+type Keybindings map[string]KeyList
+
+// Lookup returns the configured keys of an action in a view.
+// Keys which are scoped to the view take precedence.
+func (k Keybindings) Lookup(view string, action string) (KeyList, bool) {
+	if keys, ok := k[view+"."+action]; ok {
+		return keys, true
+	}
+
+	keys, ok := k[action]
+	return keys, ok
+}
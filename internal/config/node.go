@@ -0,0 +1,168 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// documentMapping is an internal implementation to get the
+// top level mapping of a yaml document. Empty documents
+// get an empty mapping.
+func documentMapping(doc *yaml.Node) *yaml.Node {
+	if doc.Kind != yaml.DocumentNode {
+		*doc = yaml.Node{Kind: yaml.DocumentNode}
+	}
+
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+
+	return doc.Content[0]
+}
+
+// findKey is an internal implementation to get the index
+// of the key node of a mapping or -1.
+func findKey(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// removeKey is an internal implementation
+// to remove a key from a mapping.
+func removeKey(mapping *yaml.Node, key string) {
+	if i := findKey(mapping, key); i >= 0 {
+		mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+	}
+}
+
+// setKey is an internal implementation to set the value of
+// a key in a mapping. The comments of a replaced value are kept.
+func setKey(mapping *yaml.Node, key string, value *yaml.Node) {
+	i := findKey(mapping, key)
+	if i < 0 {
+		mapping.Content = append(mapping.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+			value,
+		)
+		return
+	}
+
+	old := mapping.Content[i+1]
+	value.HeadComment = old.HeadComment
+	value.LineComment = old.LineComment
+	value.FootComment = old.FootComment
+	mapping.Content[i+1] = value
+}
+
+// isNested is an internal implementation to check if a
+// field is a struct which is updated key by key.
+func isNested(v reflect.Value) bool {
+	if v.Kind() != reflect.Struct {
+		return false
+	}
+
+	_, marshaler := v.Interface().(yaml.Marshaler)
+	return !marshaler
+}
+
+// isNestedMap is an internal implementation to check if a field
+// is a map of structs (e.g. profiles) which is updated key by key.
+func isNestedMap(v reflect.Value) bool {
+	return v.Kind() == reflect.Map &&
+		v.Type().Key().Kind() == reflect.String &&
+		isNested(reflect.Zero(v.Type().Elem()))
+}
+
+// childMapping is an internal implementation to get the
+// mapping of a key. Missing keys get an empty mapping.
+func childMapping(mapping *yaml.Node, key string) *yaml.Node {
+	if i := findKey(mapping, key); i >= 0 && mapping.Content[i+1].Kind == yaml.MappingNode {
+		return mapping.Content[i+1]
+	}
+
+	child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	setKey(mapping, key, child)
+	return child
+}
+
+// updateMapMapping is an internal implementation to update
+// the entries of a map of structs in a mapping.
+func updateMapMapping(mapping *yaml.Node, old reflect.Value, value reflect.Value) error {
+	for _, key := range old.MapKeys() {
+		if !value.MapIndex(key).IsValid() {
+			removeKey(mapping, key.String())
+		}
+	}
+
+	keys := value.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	for _, key := range keys {
+		oldEntry := old.MapIndex(key)
+		if !oldEntry.IsValid() {
+			oldEntry = reflect.Zero(value.Type().Elem())
+		}
+
+		child := childMapping(mapping, key.String())
+		if err := updateMapping(child, oldEntry, value.MapIndex(key)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateMapping is an internal implementation to update only
+// the keys of a mapping whose values changed from old to
+// value, so comments and the order of the file are kept.
+// Keys with zero values are removed.
+func updateMapping(mapping *yaml.Node, old reflect.Value, value reflect.Value) error {
+	t := value.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Tag.Get("yaml") == "-" {
+			continue
+		}
+
+		key := yamlName(field)
+
+		oldField, newField := old.Field(i), value.Field(i)
+		if reflect.DeepEqual(oldField.Interface(), newField.Interface()) {
+			continue
+		}
+
+		if newField.IsZero() {
+			removeKey(mapping, key)
+			continue
+		}
+
+		if isNested(newField) {
+			if err := updateMapping(childMapping(mapping, key), oldField, newField); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if isNestedMap(newField) {
+			if err := updateMapMapping(childMapping(mapping, key), oldField, newField); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var node yaml.Node
+		if err := node.Encode(newField.Interface()); err != nil {
+			return err
+		}
+		setKey(mapping, key, &node)
+	}
+
+	return nil
+}
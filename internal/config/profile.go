@@ -0,0 +1,166 @@
+package config
+
+import (
+	"sort"
+
+	"github.com/davidborzek/spofi/internal/credentials"
+)
+
+// DefaultProfile is the name of the profile
+// of the top level of the config file.
+const DefaultProfile = "default"
+
+var (
+	profile = ""
+)
+
+// Profile represents a named profile, e.g. of another spotify
+// account. Fields which are not set are taken from the top
+// level of the config file, except for the refresh token.
+type Profile struct {
+	Spotify     SpotifyConfig      `yaml:"spotify,omitempty"`
+	Credentials credentials.Config `yaml:"credentials,omitempty"`
+	Device      SpotifyDevice      `yaml:"device,omitempty"`
+	Theme       string             `yaml:"theme,omitempty"`
+	Keybindings Keybindings        `yaml:"keybindings,omitempty"`
+}
+
+// SetProfile globally sets the profile which is
+// loaded. An empty name is the default profile.
+func SetProfile(name string) {
+	if name == DefaultProfile {
+		name = ""
+	}
+	profile = name
+}
+
+// NewConfig creates an empty config of the selected profile,
+// e.g. for the setup when there is no config file.
+func NewConfig() *Config {
+	return &Config{profile: profile}
+}
+
+// Profile returns the name of the loaded profile.
+func (cfg *Config) Profile() string {
+	if cfg.profile == "" {
+		return DefaultProfile
+	}
+	return cfg.profile
+}
+
+// HasProfile checks if a profile exists in the config file.
+func (cfg *Config) HasProfile(name string) bool {
+	if name == "" || name == DefaultProfile {
+		return true
+	}
+
+	_, ok := cfg.Profiles[name]
+	return ok
+}
+
+// ProfileNames returns the names of all profiles,
+// starting with the default profile.
+func (cfg *Config) ProfileNames() []string {
+	names := make([]string, 0, len(cfg.Profiles)+1)
+	for name := range cfg.Profiles {
+		if name != DefaultProfile {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return append([]string{DefaultProfile}, names...)
+}
+
+// applyProfile is an internal implementation to override
+// the top level fields with the fields of a profile.
+func (cfg *Config) applyProfile(name string) {
+	cfg.profile = name
+	if name == "" {
+		return
+	}
+
+	p := cfg.Profiles[name]
+
+	// The client secret of the top level only
+	// belongs to the client of the top level.
+	if p.Spotify.ClientID != "" && p.Spotify.ClientID != cfg.Spotify.ClientID {
+		cfg.Spotify.ClientID = p.Spotify.ClientID
+		cfg.Spotify.ClientSecret = ""
+		cfg.ownClient = true
+	}
+
+	if p.Spotify.ClientSecret != "" {
+		cfg.Spotify.ClientSecret = p.Spotify.ClientSecret
+	}
+
+	// The refresh token belongs to the account of the profile.
+	cfg.Spotify.RefreshToken = p.Spotify.RefreshToken
+
+	if p.Credentials.Backend != "" {
+		cfg.Credentials = p.Credentials
+	}
+
+	if p.Device.ID != "" {
+		cfg.Device = p.Device
+	}
+
+	if p.Theme != "" {
+		cfg.Theme = p.Theme
+	}
+
+	if len(p.Keybindings) > 0 {
+		keybindings := make(Keybindings, len(cfg.Keybindings)+len(p.Keybindings))
+		for action, keys := range cfg.Keybindings {
+			keybindings[action] = keys
+		}
+		for action, keys := range p.Keybindings {
+			keybindings[action] = keys
+		}
+		cfg.Keybindings = keybindings
+	}
+}
+
+// splitProfile is an internal implementation to move the
+// changes of a profile from the top level of the config which
+// is written to the file into the profile.
+func (cfg *Config) splitProfile(file *Config, written *Config) {
+	if cfg.profile == "" {
+		return
+	}
+
+	p := written.Profiles[cfg.profile]
+
+	p.Spotify.ClientID = inherited(file.Spotify.ClientID, written.Spotify.ClientID)
+	p.Spotify.ClientSecret = file.Spotify.ClientSecret
+	if p.Spotify.ClientID == "" {
+		// The profile shares the client of the top level.
+		p.Spotify.ClientSecret = inherited(file.Spotify.ClientSecret, written.Spotify.ClientSecret)
+	}
+	p.Spotify.RefreshToken = file.Spotify.RefreshToken
+
+	if file.Credentials != written.Credentials {
+		p.Credentials = file.Credentials
+	}
+
+	profiles := make(map[string]Profile, len(written.Profiles)+1)
+	for name, other := range written.Profiles {
+		profiles[name] = other
+	}
+	profiles[cfg.profile] = p
+
+	file.Profiles = profiles
+	file.Spotify = written.Spotify
+	file.Credentials = written.Credentials
+	file.Theme = written.Theme
+	file.Keybindings = written.Keybindings
+}
+
+// inherited is an internal implementation to get the value of
+// a profile, which is empty when it is the top level value.
+func inherited(value string, top string) string {
+	if value == top {
+		return ""
+	}
+	return value
+}
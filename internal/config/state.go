@@ -0,0 +1,166 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/davidborzek/spofi/internal/xdg"
+)
+
+const stateFileName = "state.json"
+
+// State represents the runtime state of spofi, which is kept
+// in $XDG_STATE_HOME/spofi apart from the hand-edited config.
+type State struct {
+	// Device is the device which was selected in the menu.
+	Device SpotifyDevice `json:"device"`
+	// RefreshToken is the refresh token, unless the
+	// credentials are stored in an external backend.
+	RefreshToken string `json:"refreshToken,omitempty"`
+	// AccessToken is the last access token, which is
+	// reused by the next start until it expires.
+	AccessToken *AccessToken `json:"accessToken,omitempty"`
+
+	path string
+}
+
+// AccessToken represents an access token
+// of the spotify api with its expiry.
+type AccessToken struct {
+	Token  string    `json:"token"`
+	Expiry time.Time `json:"expiry"`
+}
+
+// loadState is an internal implementation to load the state
+// file of a profile. Without a state file, the state is empty.
+func loadState(profile string) (*State, error) {
+	path, err := xdg.StateFile(stateFileName, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &State{path: path}
+
+	raw, err := os.ReadFile(state.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(raw, state); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// write is an internal implementation to write the state file.
+func (s *State) write() error {
+	raw, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	// The state may contain the refresh token.
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path)
+}
+
+// openState is an internal implementation to
+// load the state of the config once.
+func (cfg *Config) openState() (*State, error) {
+	if cfg.state != nil {
+		return cfg.state, nil
+	}
+
+	state, err := loadState(cfg.profile)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.state = state
+	return state, nil
+}
+
+// SelectDevice selects the device for the playback
+// and saves it in the state file.
+func (cfg *Config) SelectDevice(device SpotifyDevice) error {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	state, err := cfg.openState()
+	if err != nil {
+		return err
+	}
+
+	cfg.Device = device
+	state.Device = device
+	return state.write()
+}
+
+// LoadAccessToken returns the access token of the
+// state file or nil, when no token was saved.
+func (cfg *Config) LoadAccessToken() (*AccessToken, error) {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	state, err := cfg.openState()
+	if err != nil {
+		return nil, err
+	}
+
+	return state.AccessToken, nil
+}
+
+// SaveToken saves a new access token with its expiry in the
+// state file. A rotated refresh token is saved as well, in the
+// credentials backend or the state file.
+func (cfg *Config) SaveToken(token AccessToken, refreshToken string) error {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	state, err := cfg.openState()
+	if err != nil {
+		return err
+	}
+
+	if refreshToken != "" && refreshToken != cfg.Spotify.RefreshToken {
+		if err := cfg.saveRefreshToken(state, refreshToken); err != nil {
+			return err
+		}
+	}
+
+	state.AccessToken = &token
+	return state.write()
+}
+
+// clearAccessToken is an internal implementation to drop the
+// access token of the state file, e.g. when the setup replaced
+// the refresh token with the one of another account.
+func (cfg *Config) clearAccessToken() error {
+	state, err := cfg.openState()
+	if err != nil {
+		return err
+	}
+
+	if state.AccessToken == nil {
+		return nil
+	}
+
+	state.AccessToken = nil
+	return state.write()
+}
@@ -0,0 +1,81 @@
+package credentials
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// keyPlaceholder is replaced by the key of
+// the credential in the commands.
+const keyPlaceholder = "{key}"
+
+// commandStore stores the credentials with shell
+// commands, e.g. of a password manager like pass.
+type commandStore struct {
+	get string
+	set string
+}
+
+// shellQuote is an internal implementation to quote a
+// value as a single word of a shell command.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// runShell is an internal implementation to run a shell
+// command for a credential. The key is quoted, as it contains
+// the name of the profile, and also passed as SPOFI_CREDENTIAL.
+func runShell(command string, key string, stdin string) (string, error) {
+	command = strings.ReplaceAll(command, keyPlaceholder, shellQuote(key))
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Env = append(os.Environ(), "SPOFI_CREDENTIAL="+key)
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %w: %s", command, err, msg)
+		}
+		return "", fmt.Errorf("%s: %w", command, err)
+	}
+
+	return stdout.String(), nil
+}
+
+// firstLine is an internal implementation to get the first
+// line of an output, as password managers like pass print
+// additional data after the password.
+func firstLine(output string) string {
+	line, _, _ := strings.Cut(output, "\n")
+	return strings.TrimSpace(line)
+}
+
+func (s *commandStore) Get(key string) (string, error) {
+	output, err := runShell(s.get, key, "")
+	if err != nil {
+		return "", err
+	}
+
+	value := firstLine(output)
+	if value == "" {
+		return "", ErrNotFound
+	}
+
+	return value, nil
+}
+
+func (s *commandStore) Set(key string, value string) error {
+	if s.set == "" {
+		return errors.New("the command backend requires a storeCommand to store credentials")
+	}
+
+	_, err := runShell(s.set, key, value+"\n")
+	return err
+}
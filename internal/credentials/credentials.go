@@ -0,0 +1,93 @@
+// Package credentials stores the spotify credentials
+// outside of the config file.
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// The backends which can store the credentials.
+const (
+	// BackendConfig keeps the credentials in the config file.
+	BackendConfig        = "config"
+	BackendSecretService = "secret-service"
+	BackendCommand       = "command"
+	BackendFile          = "file"
+)
+
+// The keys of the stored credentials.
+const (
+	KeyClientSecret = "clientSecret"
+	KeyRefreshToken = "refreshToken"
+)
+
+// defaultFileName is the name of the encrypted
+// file in the config directory.
+const defaultFileName = "credentials.enc"
+
+// ErrNotFound is returned when a credential is not stored.
+var ErrNotFound = errors.New("credential not found")
+
+// Config represents the credential storage in the config file.
+type Config struct {
+	// Backend is the storage of the credentials.
+	// The config file is used when it is empty.
+	Backend string `yaml:"backend,omitempty"`
+	// PasswordCommand prints a credential for the command
+	// backend, e.g. "pass show spofi/{key}".
+	PasswordCommand string `yaml:"passwordCommand,omitempty"`
+	// StoreCommand stores a credential read from stdin for the
+	// command backend, e.g. "pass insert -m -f spofi/{key}".
+	StoreCommand string `yaml:"storeCommand,omitempty"`
+	// File is the path of the encrypted file.
+	File string `yaml:"file,omitempty"`
+	// PassphraseCommand prints the passphrase of the encrypted
+	// file. Without it, SPOFI_PASSPHRASE is used.
+	PassphraseCommand string `yaml:"passphraseCommand,omitempty"`
+}
+
+// External checks if the credentials are
+// stored outside of the config file.
+func (cfg Config) External() bool {
+	return cfg.Backend != "" && cfg.Backend != BackendConfig
+}
+
+// Store stores credentials by their key.
+type Store interface {
+	// Get returns a credential or ErrNotFound.
+	Get(key string) (string, error)
+	// Set stores a credential.
+	Set(key string, value string) error
+}
+
+// New creates the store of a backend. dir is
+// the directory of the default encrypted file.
+func New(cfg Config, dir string) (Store, error) {
+	switch cfg.Backend {
+	case BackendSecretService:
+		return &secretServiceStore{}, nil
+	case BackendCommand:
+		if cfg.PasswordCommand == "" {
+			return nil, errors.New("the command backend requires a passwordCommand")
+		}
+
+		return &commandStore{
+			get: cfg.PasswordCommand,
+			set: cfg.StoreCommand,
+		}, nil
+	case BackendFile:
+		path := cfg.File
+		if path == "" {
+			path = filepath.Join(dir, defaultFileName)
+		}
+
+		return &fileStore{
+			path:       path,
+			passphrase: passphrase(cfg.PassphraseCommand),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown credentials backend %q", cfg.Backend)
+}
@@ -0,0 +1,147 @@
+package credentials
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(envPassphrase, "secret")
+
+	store, err := New(Config{Backend: BackendFile}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Get(KeyRefreshToken); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	if err := store.Set(KeyRefreshToken, "token"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Set(KeyClientSecret, "client-secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	if value, err := store.Get(KeyRefreshToken); err != nil || value != "token" {
+		t.Fatalf("unexpected value %q (%v)", value, err)
+	}
+
+	path := filepath.Join(dir, defaultFileName)
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(raw), "token") || strings.Contains(string(raw), "client-secret") {
+		t.Fatalf("expected the file to be encrypted, got %s", raw)
+	}
+
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("expected a private file, got %v (%v)", info.Mode(), err)
+	}
+
+	t.Setenv(envPassphrase, "wrong")
+	store, err = New(Config{Backend: BackendFile}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Get(KeyRefreshToken); err == nil {
+		t.Fatal("expected an error with a wrong passphrase")
+	}
+}
+
+func TestFileStorePassphrase(t *testing.T) {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+
+	store, err := New(Config{
+		Backend:           BackendFile,
+		PassphraseCommand: "echo >> " + calls + "; echo secret",
+	}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{KeyRefreshToken, KeyClientSecret} {
+		if err := store.Set(key, "value"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := store.Get(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The key is derived once per store.
+	if raw, err := os.ReadFile(calls); err != nil || len(raw) != 1 {
+		t.Fatalf("expected a single passphrase request, got %q (%v)", raw, err)
+	}
+}
+
+func TestCommandStore(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := New(Config{
+		Backend:         BackendCommand,
+		PasswordCommand: "if [ -f " + dir + "/{key} ]; then cat " + dir + "/{key}; echo 'user: spofi'; fi",
+		StoreCommand:    "cat > " + dir + "/$SPOFI_CREDENTIAL",
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Get(KeyClientSecret); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	if err := store.Set(KeyClientSecret, "client-secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the first line of the output is the credential.
+	if value, err := store.Get(KeyClientSecret); err != nil || value != "client-secret" {
+		t.Fatalf("unexpected value %q (%v)", value, err)
+	}
+
+	// The key is quoted, as it contains the name of a profile.
+	store, err = New(Config{Backend: BackendCommand, PasswordCommand: "echo {key}"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := "a b'; touch " + dir + "/injected #/" + KeyRefreshToken
+	if value, err := store.Get(key); err != nil || value != key {
+		t.Fatalf("unexpected value %q (%v)", value, err)
+	}
+}
+
+func TestCommandStoreErrors(t *testing.T) {
+	if _, err := New(Config{Backend: BackendCommand}, ""); err == nil {
+		t.Fatal("expected an error without a password command")
+	}
+
+	if _, err := New(Config{Backend: "vault"}, ""); err == nil {
+		t.Fatal("expected an error for an unknown backend")
+	}
+
+	store, err := New(Config{Backend: BackendCommand, PasswordCommand: "echo 'not found' >&2; exit 1"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Get(KeyClientSecret); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected the error of the command, got %v", err)
+	}
+
+	if err := store.Set(KeyClientSecret, "secret"); err == nil {
+		t.Fatal("expected an error without a store command")
+	}
+}
@@ -0,0 +1,205 @@
+package credentials
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// envPassphrase is the environment variable with the
+	// passphrase when there is no passphrase command.
+	envPassphrase = "SPOFI_PASSPHRASE"
+
+	keyIterations = 200000
+	keyLength     = 32
+	saltLength    = 16
+)
+
+// encryptedFile is the content of the encrypted
+// file. Data is the AES-GCM encrypted json of the
+// credentials.
+type encryptedFile struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+// fileStore stores the credentials in a file, which is
+// encrypted with a key derived from a passphrase.
+type fileStore struct {
+	path       string
+	passphrase func() (string, error)
+
+	mu sync.Mutex
+	// salt is the salt of the file and key
+	// the key derived from the passphrase.
+	salt []byte
+	key  []byte
+}
+
+// passphrase is an internal implementation to get the
+// passphrase of the encrypted file from a command or
+// from the environment.
+func passphrase(command string) func() (string, error) {
+	return func() (string, error) {
+		if command != "" {
+			output, err := runShell(command, "passphrase", "")
+			if err != nil {
+				return "", err
+			}
+			return firstLine(output), nil
+		}
+
+		if p := os.Getenv(envPassphrase); p != "" {
+			return p, nil
+		}
+
+		return "", fmt.Errorf("the file backend requires a passphraseCommand or %s", envPassphrase)
+	}
+}
+
+// aead is an internal implementation to create the AES-GCM
+// cipher of a salt. The key is only derived again, when the
+// salt changed, as the passphrase command and the key
+// derivation are slow.
+func (s *fileStore) aead(salt []byte) (cipher.AEAD, error) {
+	if s.key == nil || !bytes.Equal(s.salt, salt) {
+		p, err := s.passphrase()
+		if err != nil {
+			return nil, err
+		}
+
+		s.key = pbkdf2.Key([]byte(p), salt, keyIterations, keyLength, sha256.New)
+		s.salt = salt
+	}
+
+	block, err := aes.NewCipher(s.key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// read is an internal implementation to read
+// and decrypt the credentials of the file.
+func (s *fileStore) read() (map[string]string, error) {
+	raw, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("invalid credentials file %s: %w", s.path, err)
+	}
+
+	aead, err := s.aead(file.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := aead.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s, is the passphrase correct?", s.path)
+	}
+
+	creds := map[string]string{}
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, err
+	}
+
+	return creds, nil
+}
+
+// write is an internal implementation to encrypt the
+// credentials and write them to the file. The salt of
+// the file is kept, a new file gets a new salt.
+func (s *fileStore) write(creds map[string]string) error {
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+
+	file := encryptedFile{Salt: s.salt}
+	if file.Salt == nil {
+		file.Salt = make([]byte, saltLength)
+		if _, err := rand.Read(file.Salt); err != nil {
+			return err
+		}
+	}
+
+	aead, err := s.aead(file.Salt)
+	if err != nil {
+		return err
+	}
+
+	file.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	file.Data = aead.Seal(nil, file.Nonce, data, nil)
+
+	raw, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so the
+	// credentials are not lost on a failed write.
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path)
+}
+
+func (s *fileStore) Get(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	creds, err := s.read()
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := creds[key]
+	if !ok || value == "" {
+		return "", ErrNotFound
+	}
+
+	return value, nil
+}
+
+func (s *fileStore) Set(key string, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	creds, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	creds[key] = value
+	return s.write(creds)
+}
@@ -0,0 +1,39 @@
+package credentials
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	// secretService is the service attribute of the secrets.
+	secretService = "spofi"
+)
+
+// secretServiceStore stores the credentials in the secret
+// service of the desktop (e.g. gnome-keyring or KeePassXC),
+// which is accessed over D-Bus.
+type secretServiceStore struct{}
+
+func (s *secretServiceStore) Get(key string) (string, error) {
+	value, err := keyring.Get(secretService, key)
+	if errors.Is(err, keyring.ErrNotFound) || (err == nil && value == "") {
+		return "", ErrNotFound
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("secret service lookup: %w", err)
+	}
+
+	return value, nil
+}
+
+func (s *secretServiceStore) Set(key string, value string) error {
+	if err := keyring.Set(secretService, key, value); err != nil {
+		return fmt.Errorf("secret service store: %w", err)
+	}
+
+	return nil
+}
@@ -11,16 +11,21 @@ func FormatAlbumRows(albums []spotify.Album, icon string) []rofi.Row {
 	for i, album := range albums {
 		data[i] = []string{
 			album.Name,
-			album.Artists[0].Name,
+			FirstArtist(album.Artists),
 		}
 	}
 
-	rawRows := BuildRows(data, 30)
+	rawRows := BuildMarkupRows(data, 30)
 	rows := make([]rofi.Row, len(albums))
 	for i, rawRow := range rawRows {
+		if albumFormat != nil {
+			rawRow = execute(albumFormat, newAlbumData(albums[i]), rawRow)
+		}
+
 		rows[i] = rofi.Row{
 			Title: FormatIcon(icon, rawRow),
 			Value: albums[i].URI,
+			Meta:  FormatMeta(ArtistNames(albums[i].Artists), ReleaseYear(albums[i].ReleaseDate)),
 		}
 	}
 
@@ -1,7 +1,5 @@
 package format
 
-import "fmt"
-
 type Keybinding struct {
 	Key         string
 	Description string
@@ -10,13 +8,13 @@ type Keybinding struct {
 func FormatKeybindings(
 	keys ...Keybinding,
 ) string {
-	var str string
+	var m Markup
 	for idx, key := range keys {
-		str += fmt.Sprintf("<b>%s:</b> %s", key.Key, key.Description)
+		m.Bold(key.Key + ":").Text(" " + key.Description)
 		if idx != len(keys)-1 {
-			str += " | "
+			m.Text(" | ")
 		}
 	}
 
-	return str
+	return m.String()
 }
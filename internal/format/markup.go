@@ -0,0 +1,85 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+)
+
+// markupEscaper escapes the characters with
+// a special meaning in pango markup.
+var markupEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	"'", "&#39;",
+	`"`, "&quot;",
+)
+
+// Escape escapes a text (e.g. a track name) to be
+// safely rendered as pango markup.
+func Escape(s string) string {
+	return markupEscaper.Replace(s)
+}
+
+// Bold wraps markup in a bold span.
+func Bold(markup string) string {
+	return "<b>" + markup + "</b>"
+}
+
+// Underline wraps markup in an underlined span.
+func Underline(markup string) string {
+	return "<u>" + markup + "</u>"
+}
+
+// Dim wraps markup in a span with reduced opacity,
+// e.g. for secondary information like the artist.
+func Dim(markup string) string {
+	return `<span alpha="60%">` + markup + "</span>"
+}
+
+// Color wraps markup in a span with the given
+// foreground color (e.g. "#1db954" or "green").
+func Color(color string, markup string) string {
+	return fmt.Sprintf(`<span foreground="%s">%s</span>`, Escape(color), markup)
+}
+
+// Markup builds pango markup. Texts are escaped when
+// they are added, so they are always rendered as is.
+type Markup struct {
+	b strings.Builder
+}
+
+// Text adds a plain text.
+func (m *Markup) Text(s string) *Markup {
+	m.b.WriteString(Escape(s))
+	return m
+}
+
+// Bold adds a bold text.
+func (m *Markup) Bold(s string) *Markup {
+	m.b.WriteString(Bold(Escape(s)))
+	return m
+}
+
+// Underline adds an underlined text.
+func (m *Markup) Underline(s string) *Markup {
+	m.b.WriteString(Underline(Escape(s)))
+	return m
+}
+
+// Dim adds a text with reduced opacity.
+func (m *Markup) Dim(s string) *Markup {
+	m.b.WriteString(Dim(Escape(s)))
+	return m
+}
+
+// Color adds a text with the given foreground color.
+func (m *Markup) Color(color string, s string) *Markup {
+	m.b.WriteString(Color(color, Escape(s)))
+	return m
+}
+
+// String returns the built markup.
+func (m *Markup) String() string {
+	return m.b.String()
+}
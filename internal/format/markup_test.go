@@ -0,0 +1,53 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/davidborzek/spofi/internal/config"
+	"github.com/davidborzek/spofi/pkg/spotify"
+)
+
+func TestEscape(t *testing.T) {
+	if s := Escape(`Rock & Roll <3 "live" it's`); s != "Rock &amp; Roll &lt;3 &quot;live&quot; it&#39;s" {
+		t.Errorf("unexpected escaped text %q", s)
+	}
+}
+
+func TestMarkup(t *testing.T) {
+	var m Markup
+	m.Bold("Rock & Roll").
+		Text(" <3 ").
+		Dim("A & B").
+		Text(" ").
+		Underline("<u>").
+		Color("#1db954", "ok")
+
+	want := `<b>Rock &amp; Roll</b> &lt;3 <span alpha="60%">A &amp; B</span> <u>&lt;u&gt;</u><span foreground="#1db954">ok</span>`
+	if s := m.String(); s != want {
+		t.Errorf("unexpected markup %q, want %q", s, want)
+	}
+}
+
+func TestBuildMarkupRows(t *testing.T) {
+	rows := BuildMarkupRows([][]string{
+		{"Rock & Roll", "<3"},
+		{"Song", "Artist"},
+	}, 30)
+
+	if rows[0] != "Rock &amp; Roll"+"          "+Dim("&lt;3") {
+		t.Errorf("unexpected row %q", rows[0])
+	}
+
+	if rows[1] != "Song"+"                 "+Dim("Artist") {
+		t.Errorf("unexpected row %q", rows[1])
+	}
+}
+
+func TestEscapedRowFormat(t *testing.T) {
+	setRowFormats(t, config.FormatConfig{Playlist: `{{.Name}} & more`})
+
+	rows := FormatPlaylistRows([]spotify.Playlist{{Name: "<3"}}, "i")
+	if rows[0].Title != "i  &lt;3 &amp; more" {
+		t.Errorf("unexpected row %q", rows[0].Title)
+	}
+}
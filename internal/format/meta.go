@@ -0,0 +1,53 @@
+package format
+
+import (
+	"strings"
+
+	"github.com/davidborzek/spofi/pkg/spotify"
+)
+
+// FormatMeta joins the non empty search keywords of a row.
+func FormatMeta(keywords ...string) string {
+	nonEmpty := make([]string, 0, len(keywords))
+	for _, k := range keywords {
+		if k = strings.TrimSpace(k); k != "" {
+			nonEmpty = append(nonEmpty, k)
+		}
+	}
+	return strings.Join(nonEmpty, " ")
+}
+
+// ArtistNames joins the names of all artists, e.g.
+// for the rows, row formats and the history.
+func ArtistNames(artists []spotify.Artist) string {
+	names := make([]string, len(artists))
+	for i, artist := range artists {
+		names[i] = artist.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// FirstArtist returns the name of the first artist,
+// or an empty string when there are no artists.
+func FirstArtist(artists []spotify.Artist) string {
+	if len(artists) == 0 {
+		return ""
+	}
+	return artists[0].Name
+}
+
+// TrackArtist returns the first artist of a track or
+// the show of an episode, which is shown next to its name.
+func TrackArtist(track spotify.Track) string {
+	if track.IsEpisode() {
+		return track.Show.Name
+	}
+	return FirstArtist(track.Artists)
+}
+
+// ReleaseYear returns the year of a spotify release
+// date (e.g. 2001 for 2001-03-07).
+func ReleaseYear(date string) string {
+	year, _, _ := strings.Cut(date, "-")
+	return year
+}
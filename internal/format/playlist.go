@@ -0,0 +1,25 @@
+package format
+
+import (
+	"github.com/davidborzek/spofi/pkg/rofi"
+	"github.com/davidborzek/spofi/pkg/spotify"
+)
+
+// FormatPlaylistRows formats each playlist as row for rofi.
+func FormatPlaylistRows(playlists []spotify.Playlist, icon string) []rofi.Row {
+	rows := make([]rofi.Row, len(playlists))
+	for i, playlist := range playlists {
+		title := Escape(playlist.Name)
+		if playlistFormat != nil {
+			title = execute(playlistFormat, newPlaylistData(playlist), title)
+		}
+
+		rows[i] = rofi.Row{
+			Title: FormatIcon(icon, title),
+			Value: playlist.ID,
+			Meta:  playlist.Owner.DisplayName,
+		}
+	}
+
+	return rows
+}
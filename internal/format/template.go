@@ -0,0 +1,185 @@
+package format
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+
+	"github.com/davidborzek/spofi/internal/config"
+	"github.com/davidborzek/spofi/pkg/spotify"
+)
+
+// TrackData is the data of the track row format.
+type TrackData struct {
+	Name string
+	// Artist is the first artist of the track.
+	Artist string
+	// Artists are all artists of the track separated by comma.
+	Artists     string
+	Album       string
+	Year        string
+	Duration    string
+	TrackNumber int
+	Explicit    bool
+	Liked       bool
+}
+
+// AlbumData is the data of the album row format.
+type AlbumData struct {
+	Name string
+	// Artist is the first artist of the album.
+	Artist string
+	// Artists are all artists of the album separated by comma.
+	Artists string
+	Year    string
+	Tracks  int
+}
+
+// PlaylistData is the data of the playlist row format.
+type PlaylistData struct {
+	Name          string
+	Owner         string
+	Tracks        int
+	Collaborative bool
+}
+
+// EpisodeData is the data of the episode row format.
+type EpisodeData struct {
+	Name      string
+	Show      string
+	Publisher string
+	Year      string
+	Duration  string
+	Explicit  bool
+}
+
+var (
+	trackFormat    *template.Template
+	albumFormat    *template.Template
+	playlistFormat *template.Template
+	episodeFormat  *template.Template
+
+	isLiked = func(id string) bool { return false }
+
+	// formatFuncs are the helpers available in the row formats.
+	formatFuncs = template.FuncMap{
+		"truncate": truncate,
+		"pad":      pad,
+		"padLeft":  padLeft,
+	}
+)
+
+// SetRowFormats globally sets the row formats. Empty
+// formats use the default column layout.
+func SetRowFormats(cfg config.FormatConfig) error {
+	formats := []struct {
+		name   string
+		format string
+		tmpl   **template.Template
+	}{
+		{"track", cfg.Track, &trackFormat},
+		{"album", cfg.Album, &albumFormat},
+		{"playlist", cfg.Playlist, &playlistFormat},
+		{"episode", cfg.Episode, &episodeFormat},
+	}
+
+	for _, f := range formats {
+		*f.tmpl = nil
+		if f.format == "" {
+			continue
+		}
+
+		tmpl, err := template.New(f.name).Funcs(formatFuncs).Parse(f.format)
+		if err != nil {
+			return fmt.Errorf("invalid %s format: %w", f.name, err)
+		}
+		*f.tmpl = tmpl
+	}
+
+	return nil
+}
+
+// SetLikedFunc globally sets the function which
+// checks if a track (by id) is liked.
+func SetLikedFunc(liked func(id string) bool) {
+	isLiked = liked
+}
+
+// execute is an internal implementation to render a row format
+// as escaped pango markup. A failing format falls back to the
+// given markup.
+func execute(tmpl *template.Template, data any, fallback string) string {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		log.Println(err)
+		return fallback
+	}
+	return Escape(b.String())
+}
+
+func newTrackData(track spotify.Track) TrackData {
+	data := TrackData{
+		Name:        track.Name,
+		Artists:     ArtistNames(track.Artists),
+		Album:       track.Album.Name,
+		Year:        ReleaseYear(track.Album.ReleaseDate),
+		Duration:    FormatTime(track.DurationMs),
+		TrackNumber: track.TrackNumber,
+		Explicit:    track.Explicit,
+		Liked:       isLiked(track.ID),
+	}
+
+	data.Artist = FirstArtist(track.Artists)
+	return data
+}
+
+func newAlbumData(album spotify.Album) AlbumData {
+	data := AlbumData{
+		Name:    album.Name,
+		Artists: ArtistNames(album.Artists),
+		Year:    ReleaseYear(album.ReleaseDate),
+		Tracks:  album.TotalTracks,
+	}
+
+	data.Artist = FirstArtist(album.Artists)
+	return data
+}
+
+func newPlaylistData(playlist spotify.Playlist) PlaylistData {
+	return PlaylistData{
+		Name:          playlist.Name,
+		Owner:         playlist.Owner.DisplayName,
+		Tracks:        playlist.Tracks.Total,
+		Collaborative: playlist.Collaborative,
+	}
+}
+
+func newEpisodeData(episode spotify.Track) EpisodeData {
+	return EpisodeData{
+		Name:      episode.Name,
+		Show:      episode.Show.Name,
+		Publisher: episode.Show.Publisher,
+		Year:      ReleaseYear(episode.ReleaseDate),
+		Duration:  FormatTime(episode.DurationMs),
+		Explicit:  episode.Explicit,
+	}
+}
+
+// truncate shortens a text to a maximum width with "...".
+func truncate(width int, s string) string {
+	return truncateWidth(s, width)
+}
+
+// pad pads a text with spaces on the right to a minimum width.
+func pad(width int, s string) string {
+	return padWidth(s, width)
+}
+
+// padLeft pads a text with spaces on the left to a minimum width.
+func padLeft(width int, s string) string {
+	if diff := width - displayWidth(s); diff > 0 {
+		return strings.Repeat(" ", diff) + s
+	}
+	return s
+}
@@ -0,0 +1,114 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/davidborzek/spofi/internal/config"
+	"github.com/davidborzek/spofi/pkg/spotify"
+)
+
+func setRowFormats(t *testing.T, cfg config.FormatConfig) {
+	t.Helper()
+
+	if err := SetRowFormats(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		SetRowFormats(config.FormatConfig{})
+		SetLikedFunc(func(string) bool { return false })
+	})
+}
+
+func TestRowFormats(t *testing.T) {
+	setRowFormats(t, config.FormatConfig{
+		Track:    `{{.TrackNumber}}. {{.Name | truncate 10 | pad 12}}|{{.Artists}} ({{.Album}}, {{.Year}}) {{.Duration}}{{if .Explicit}} E{{end}}{{if .Liked}} *{{end}}`,
+		Album:    `{{.Name}} - {{.Artist}} [{{.Tracks}}]`,
+		Playlist: `{{.Name}} by {{.Owner}}{{if .Collaborative}} (collaborative){{end}}`,
+		Episode:  `{{.Show}}: {{.Name | padLeft 6}}`,
+	})
+	SetLikedFunc(func(id string) bool { return id == "t1" })
+
+	album := spotify.Album{
+		Name:        "Album",
+		Artists:     []spotify.Artist{{Name: "A"}, {Name: "B"}},
+		ReleaseDate: "2001-03-07",
+		TotalTracks: 12,
+	}
+
+	tracks := []spotify.Track{
+		{
+			ID:          "t1",
+			Name:        "A very long track name",
+			Artists:     album.Artists,
+			Album:       album,
+			DurationMs:  185000,
+			TrackNumber: 3,
+			Explicit:    true,
+		},
+		{
+			Type: "episode",
+			Name: "Ep 1",
+			Show: spotify.Show{Name: "Podcast"},
+		},
+	}
+
+	rows := FormatTrackRows(tracks, "i")
+	if want := "i  3. A very ...  |A, B (Album, 2001) 3:05 E *"; rows[0].Title != want {
+		t.Errorf("unexpected track row %q, want %q", rows[0].Title, want)
+	}
+
+	if want := "i  Podcast:   Ep 1"; rows[1].Title != want {
+		t.Errorf("unexpected episode row %q, want %q", rows[1].Title, want)
+	}
+
+	albumRows := FormatAlbumRows([]spotify.Album{album}, "i")
+	if want := "i  Album - A [12]"; albumRows[0].Title != want {
+		t.Errorf("unexpected album row %q, want %q", albumRows[0].Title, want)
+	}
+
+	playlistRows := FormatPlaylistRows([]spotify.Playlist{{
+		Name:          "Focus",
+		Owner:         spotify.User{DisplayName: "me"},
+		Collaborative: true,
+	}}, "i")
+	if want := "i  Focus by me (collaborative)"; playlistRows[0].Title != want {
+		t.Errorf("unexpected playlist row %q, want %q", playlistRows[0].Title, want)
+	}
+}
+
+func TestDefaultRowFormat(t *testing.T) {
+	setRowFormats(t, config.FormatConfig{})
+
+	rows := FormatTrackRows([]spotify.Track{
+		{Name: "Song", Artists: []spotify.Artist{{Name: "Artist"}}},
+		{Type: "episode", Name: "Episode", Show: spotify.Show{Name: "Show"}},
+	}, "i")
+
+	if rows[0].Title != "i  Song             "+Dim("Artist") || rows[1].Title != "i  Episode          "+Dim("Show") {
+		t.Fatalf("unexpected rows %q, %q", rows[0].Title, rows[1].Title)
+	}
+}
+
+func TestRowsWithoutArtists(t *testing.T) {
+	albums := []spotify.Album{{Name: "Album"}}
+
+	setRowFormats(t, config.FormatConfig{})
+	if rows := FormatAlbumRows(albums, "i"); !strings.HasPrefix(rows[0].Title, "i  Album ") {
+		t.Fatalf("unexpected row %q", rows[0].Title)
+	}
+
+	setRowFormats(t, config.FormatConfig{Album: "{{.Name}} - {{.Artist}}"})
+	if rows := FormatAlbumRows(albums, "i"); rows[0].Title != "i  Album - " {
+		t.Fatalf("unexpected row %q", rows[0].Title)
+	}
+}
+
+func TestInvalidRowFormat(t *testing.T) {
+	if err := SetRowFormats(config.FormatConfig{Album: "{{.Name"}); err == nil {
+		t.Fatal("expected an error for an invalid format")
+	}
+
+	SetRowFormats(config.FormatConfig{})
+}
@@ -3,23 +3,19 @@ package format
 import (
 	"fmt"
 	"strings"
-	"unicode/utf8"
 )
 
+// FormatTitle joins two texts (e.g. name and artist). When they
+// are wider than 30 cells, both are shortened to 15 cells.
 func FormatTitle(a string, b string) string {
-	aLength := utf8.RuneCountInString(a)
-	bLength := utf8.RuneCountInString(b)
-	if aLength+bLength > 30 {
-		if aLength > 15 {
-			aLength = 15
-			a = fmt.Sprintf("%s...", strings.TrimSpace(substring(a, 0, aLength)))
+	if displayWidth(a)+displayWidth(b) > 30 {
+		if displayWidth(a) > 15 {
+			a = strings.TrimSpace(cutWidth(a, 15)) + ellipsis
 		}
 
-		if bLength > 15 {
-			bLength = 15
-			b = fmt.Sprintf("%s...", strings.TrimSpace(substring(b, 0, bLength)))
+		if displayWidth(b) > 15 {
+			b = strings.TrimSpace(cutWidth(b, 15)) + ellipsis
 		}
-
 	}
 
 	return fmt.Sprintf(
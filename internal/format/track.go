@@ -5,22 +5,48 @@ import (
 	"github.com/davidborzek/spofi/pkg/spotify"
 )
 
-// FormatTrackRows formats each track as row for rofi.
+// FormatTrackRows formats each track (or episode) as row for rofi.
+// Without a configured format, the name and the first artist (or
+// the show of an episode) are aligned in columns.
 func FormatTrackRows(tracks []spotify.Track, icon string) []rofi.Row {
 	data := make([][]string, len(tracks))
 	for i, track := range tracks {
 		data[i] = []string{
 			track.Name,
-			track.Artists[0].Name,
+			TrackArtist(track),
 		}
 	}
 
-	rawRows := BuildRows(data, 30)
+	rawRows := BuildMarkupRows(data, 30)
 	rows := make([]rofi.Row, len(tracks))
 	for i, rawRow := range rawRows {
+		track := tracks[i]
+
+		if track.IsEpisode() {
+			if episodeFormat != nil {
+				rawRow = execute(episodeFormat, newEpisodeData(track), rawRow)
+			}
+
+			rows[i] = rofi.Row{
+				Title: FormatIcon(icon, rawRow),
+				Value: track.URI,
+				Meta:  FormatMeta(track.Show.Name, track.Show.Publisher),
+			}
+			continue
+		}
+
+		if trackFormat != nil {
+			rawRow = execute(trackFormat, newTrackData(track), rawRow)
+		}
+
 		rows[i] = rofi.Row{
 			Title: FormatIcon(icon, rawRow),
-			Value: tracks[i].URI,
+			Value: track.URI,
+			Meta: FormatMeta(
+				track.Album.Name,
+				ArtistNames(track.Artists),
+				ReleaseYear(track.Album.ReleaseDate),
+			),
 		}
 	}
 
@@ -1,13 +1,61 @@
 package format
 
 import (
-	"fmt"
 	"strings"
-	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
 )
 
-func substring(s string, from int, to int) string {
-	return string([]rune(s)[from:to])
+// ellipsis is appended to truncated texts.
+const ellipsis = "..."
+
+// displayWidth is an internal implementation to get the width
+// of a text in terminal cells. Wide characters (e.g. CJK or
+// emoji) take two cells and combining characters none.
+func displayWidth(s string) int {
+	return uniseg.StringWidth(s)
+}
+
+// cutWidth is an internal implementation to cut a text to a
+// maximum width without splitting grapheme clusters.
+func cutWidth(s string, maxWidth int) string {
+	var b strings.Builder
+
+	width := 0
+	state := -1
+	for s != "" {
+		var (
+			cluster string
+			w       int
+		)
+		cluster, s, w, state = uniseg.FirstGraphemeClusterInString(s, state)
+		if width+w > maxWidth {
+			break
+		}
+
+		b.WriteString(cluster)
+		width += w
+	}
+
+	return b.String()
+}
+
+// truncateWidth is an internal implementation to shorten
+// a text with an ellipsis when it is wider than maxWidth.
+func truncateWidth(s string, maxWidth int) string {
+	if displayWidth(s) <= maxWidth || maxWidth < len(ellipsis) {
+		return s
+	}
+	return cutWidth(s, maxWidth-len(ellipsis)) + ellipsis
+}
+
+// padWidth is an internal implementation to pad a
+// text with spaces on the right to a minimum width.
+func padWidth(s string, minWidth int) string {
+	if diff := minWidth - displayWidth(s); diff > 0 {
+		return s + strings.Repeat(" ", diff)
+	}
+	return s
 }
 
 func getMaxLengths(data [][]string, maximum int) []int {
@@ -21,7 +69,7 @@ func getMaxLengths(data [][]string, maximum int) []int {
 	maxLengths := make([]int, arrSize)
 	for _, d := range data {
 		for i, t := range d {
-			l := utf8.RuneCountInString(t)
+			l := displayWidth(t)
 
 			if l > maxLengths[i] {
 				maxLengths[i] = l
@@ -35,37 +83,58 @@ func getMaxLengths(data [][]string, maximum int) []int {
 	return maxLengths
 }
 
-func buildRow(data []string, maxLengths []int, lengthLimit int) string {
-	out := ""
+// plainColumn is an internal implementation
+// to output a column of a row as is.
+func plainColumn(i int, t string) string {
+	return t
+}
+
+// markupColumn is an internal implementation to output a column
+// of a row as pango markup. All columns after the first are dimmed.
+func markupColumn(i int, t string) string {
+	if i == 0 {
+		return Escape(t)
+	}
+	return Dim(Escape(t))
+}
+
+func buildRow(data []string, maxLengths []int, lengthLimit int, style func(i int, t string) string) string {
+	var b strings.Builder
 	for i, t := range data {
-		l := utf8.RuneCountInString(t)
-		if l > lengthLimit-3 {
-			l = lengthLimit
-			t = fmt.Sprintf("%s...", substring(t, 0, lengthLimit-3))
+		if displayWidth(t) > lengthLimit-len(ellipsis) {
+			t = cutWidth(t, lengthLimit-len(ellipsis)) + ellipsis
 		}
 
-		spacer := ""
+		b.WriteString(style(i, t))
 		if i != len(data)-1 {
-			diff := maxLengths[i] - l
-			spacer = strings.Repeat(" ", diff+10)
+			b.WriteString(padWidth("", maxLengths[i]-displayWidth(t)+10))
 		}
-
-		out = fmt.Sprintf("%s%s%s",
-			out,
-			t,
-			spacer,
-		)
 	}
 
-	return out
+	return b.String()
 }
 
+// BuildRows aligns the columns of the rows by their display
+// width. Columns wider than maxColumnSize are truncated.
 func BuildRows(data [][]string, maxColumnSize int) []string {
 	maxLengths := getMaxLengths(data, maxColumnSize)
 
 	out := make([]string, len(data))
 	for i, d := range data {
-		out[i] = buildRow(d, maxLengths, maxColumnSize)
+		out[i] = buildRow(d, maxLengths, maxColumnSize, plainColumn)
+	}
+	return out
+}
+
+// BuildMarkupRows aligns the columns of the rows like BuildRows,
+// but outputs the rows as pango markup with escaped texts and
+// dimmed secondary columns (e.g. the artist).
+func BuildMarkupRows(data [][]string, maxColumnSize int) []string {
+	maxLengths := getMaxLengths(data, maxColumnSize)
+
+	out := make([]string, len(data))
+	for i, d := range data {
+		out[i] = buildRow(d, maxLengths, maxColumnSize, markupColumn)
 	}
 	return out
 }
@@ -0,0 +1,85 @@
+package format
+
+import (
+	"testing"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+	}{
+		{"abc", 3},
+		{"東京", 4},
+		{"🎵", 2},
+		{"Café", 4},
+		{"👩‍👩‍👧", 2},
+	}
+
+	for _, test := range tests {
+		if width := displayWidth(test.s); width != test.width {
+			t.Errorf("unexpected width of %q: %d, want %d", test.s, width, test.width)
+		}
+	}
+}
+
+func TestTruncateWidth(t *testing.T) {
+	tests := []struct {
+		s        string
+		width    int
+		expected string
+	}{
+		{"abcdef", 6, "abcdef"},
+		{"abcdefg", 6, "abc..."},
+		{"東京都庁舎", 6, "東..."},
+		{"東京都庁舎", 7, "東京..."},
+		{"Café Café", 7, "Café..."},
+		{"🎵🎵🎵🎵", 6, "🎵..."},
+	}
+
+	for _, test := range tests {
+		if s := truncateWidth(test.s, test.width); s != test.expected {
+			t.Errorf("unexpected truncation of %q: %q, want %q", test.s, s, test.expected)
+		}
+		if w := displayWidth(truncateWidth(test.s, test.width)); w > test.width {
+			t.Errorf("truncation of %q is too wide: %d", test.s, w)
+		}
+	}
+}
+
+func TestBuildRowsWidth(t *testing.T) {
+	rows := BuildRows([][]string{
+		{"東京", "a"},
+		{"Café", "b"},
+		{"🎵🎵🎵", "c"},
+	}, 30)
+
+	expected := []string{
+		"東京" + "            " + "a",
+		"Café" + "            " + "b",
+		"🎵🎵🎵" + "          " + "c",
+	}
+
+	for i, row := range rows {
+		if row != expected[i] {
+			t.Errorf("unexpected row %d: %q, want %q", i, row, expected[i])
+		}
+	}
+}
+
+func TestBuildRowsTruncate(t *testing.T) {
+	rows := BuildRows([][]string{
+		{"日本語の非常に長いタイトルです", "a"},
+	}, 10)
+
+	if rows[0] != "日本語..."+"           "+"a" {
+		t.Errorf("unexpected row: %q", rows[0])
+	}
+}
+
+func TestFormatTitle(t *testing.T) {
+	title := FormatTitle("日本語の非常に長いタイトル", "Artist")
+	if title != "日本語の非常に..."+" | Artist" {
+		t.Errorf("unexpected title: %q", title)
+	}
+}
@@ -0,0 +1,195 @@
+package history
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davidborzek/spofi/internal/xdg"
+)
+
+const (
+	historyFileName = "history.json"
+
+	// maxEntries is the maximum number of entries. The
+	// entries with the lowest frecency are removed first.
+	maxEntries = 500
+)
+
+// Kind is the kind of a played item.
+type Kind string
+
+const (
+	KindTrack    Kind = "track"
+	KindAlbum    Kind = "album"
+	KindPlaylist Kind = "playlist"
+	KindArtist   Kind = "artist"
+)
+
+// KindOf returns the kind of a spotify uri
+// (e.g. album for spotify:album:<id>).
+func KindOf(uri string) Kind {
+	parts := strings.Split(uri, ":")
+	if len(parts) != 3 {
+		return ""
+	}
+	return Kind(parts[1])
+}
+
+// Item is an item which was played.
+type Item struct {
+	URI  string `json:"uri"`
+	Kind Kind   `json:"kind"`
+	Name string `json:"name"`
+	// Detail describes the item, e.g. the artists of a track.
+	Detail string `json:"detail"`
+}
+
+// Entry is an item of the history with its usage.
+type Entry struct {
+	Item
+	Count      int       `json:"count"`
+	LastPlayed time.Time `json:"lastPlayed"`
+}
+
+// Frecency ranks an entry by how often and how recently it was
+// played. The count is weighted by the age of the last play, so
+// items played often a long time ago rank below recent ones.
+func (e Entry) Frecency(now time.Time) float64 {
+	age := now.Sub(e.LastPlayed)
+
+	weight := 1.0
+	switch {
+	case age <= 4*time.Hour:
+		weight = 100
+	case age <= 24*time.Hour:
+		weight = 70
+	case age <= 7*24*time.Hour:
+		weight = 50
+	case age <= 30*24*time.Hour:
+		weight = 30
+	case age <= 90*24*time.Hour:
+		weight = 10
+	}
+
+	return float64(e.Count) * weight
+}
+
+// History is the local history of the items
+// which were played through spofi.
+type History struct {
+	path string
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// Open opens the history of a profile in the state dir. The
+// default profile has an empty name. Without a state dir,
+// the history is only kept in memory.
+func Open(profile string) *History {
+	// An empty path keeps the history in memory.
+	path, _ := xdg.StateFile(historyFileName, profile)
+	return open(path)
+}
+
+// open is an internal implementation to open
+// the history at a given path.
+func open(path string) *History {
+	h := &History{
+		path: path,
+		now:  time.Now,
+	}
+
+	// A missing or corrupt history is ignored and started anew.
+	if raw, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(raw, &h.entries)
+	}
+
+	return h
+}
+
+// Add records that an item was played.
+func (h *History) Add(item Item) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+
+	found := false
+	for i, e := range h.entries {
+		if e.URI == item.URI {
+			h.entries[i].Item = item
+			h.entries[i].Count++
+			h.entries[i].LastPlayed = now
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		h.entries = append(h.entries, Entry{
+			Item:       item,
+			Count:      1,
+			LastPlayed: now,
+		})
+	}
+
+	h.sort(now)
+	if len(h.entries) > maxEntries {
+		h.entries = h.entries[:maxEntries]
+	}
+
+	return h.save()
+}
+
+// Entries returns the entries ranked by frecency.
+func (h *History) Entries() []Entry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.sort(h.now())
+	return append([]Entry{}, h.entries...)
+}
+
+// sort is an internal implementation to rank the entries by
+// frecency. Entries with the same frecency are ranked by the
+// last play. It must be called with the mutex locked.
+func (h *History) sort(now time.Time) {
+	sort.SliceStable(h.entries, func(i, j int) bool {
+		a, b := h.entries[i], h.entries[j]
+		if fa, fb := a.Frecency(now), b.Frecency(now); fa != fb {
+			return fa > fb
+		}
+		return a.LastPlayed.After(b.LastPlayed)
+	})
+}
+
+// save is an internal implementation to write the history
+// file. It must be called with the mutex locked.
+func (h *History) save() error {
+	if h.path == "" {
+		return nil
+	}
+
+	raw, err := json.Marshal(h.entries)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return err
+	}
+
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, h.path)
+}
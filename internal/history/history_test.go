@@ -0,0 +1,88 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func uris(entries []Entry) []string {
+	uris := make([]string, len(entries))
+	for i, e := range entries {
+		uris[i] = e.URI
+	}
+	return uris
+}
+
+func TestFrecency(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		entry Entry
+		want  float64
+	}{
+		{Entry{Count: 1, LastPlayed: now.Add(-time.Hour)}, 100},
+		{Entry{Count: 2, LastPlayed: now.Add(-12 * time.Hour)}, 140},
+		{Entry{Count: 3, LastPlayed: now.Add(-3 * 24 * time.Hour)}, 150},
+		{Entry{Count: 10, LastPlayed: now.Add(-60 * 24 * time.Hour)}, 100},
+		{Entry{Count: 10, LastPlayed: now.Add(-365 * 24 * time.Hour)}, 10},
+	}
+
+	for _, tc := range tests {
+		if got := tc.entry.Frecency(now); got != tc.want {
+			t.Errorf("Frecency(%+v) = %v, want %v", tc.entry, got, tc.want)
+		}
+	}
+}
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spofi", "history.json")
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	h := open(path)
+	h.now = func() time.Time { return now }
+
+	album := Item{URI: "spotify:album:1", Kind: KindAlbum, Name: "Album"}
+	track := Item{URI: "spotify:track:1", Kind: KindTrack, Name: "Track"}
+
+	// The album was played often, but two weeks ago.
+	for i := 0; i < 3; i++ {
+		if err := h.Add(album); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now = now.Add(14 * 24 * time.Hour)
+	h.Add(track)
+
+	if got := uris(h.Entries()); got[0] != track.URI || got[1] != album.URI {
+		t.Fatalf("expected the recent track first, got %v", got)
+	}
+
+	// Played again today, the album ranks first.
+	h.Add(album)
+
+	h = open(path)
+	h.now = func() time.Time { return now }
+
+	entries := h.Entries()
+	if len(entries) != 2 || entries[0].URI != album.URI || entries[0].Count != 4 {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	tests := map[string]Kind{
+		"spotify:track:1":    KindTrack,
+		"spotify:album:1":    KindAlbum,
+		"spotify:playlist:1": KindPlaylist,
+		"spotify:artist:1":   KindArtist,
+		"invalid":            "",
+	}
+
+	for uri, want := range tests {
+		if got := KindOf(uri); got != want {
+			t.Errorf("KindOf(%q) = %q, want %q", uri, got, want)
+		}
+	}
+}
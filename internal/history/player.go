@@ -0,0 +1,74 @@
+package history
+
+import (
+	"log"
+
+	"github.com/davidborzek/spofi/internal/player"
+)
+
+// Describer describes a played uri (name and
+// detail) for the history.
+type Describer func(uri string) (Item, error)
+
+// recordingPlayer is an internal implementation of a
+// player which adds all started items to the history.
+type recordingPlayer struct {
+	player.Player
+
+	history  *History
+	describe Describer
+}
+
+// NewPlayer wraps a player, so every track and context
+// which is started through it is added to the history.
+func NewPlayer(p player.Player, h *History, describe Describer) player.Player {
+	return &recordingPlayer{
+		Player:   p,
+		history:  h,
+		describe: describe,
+	}
+}
+
+func (p *recordingPlayer) PlayTrack(uri string) error {
+	if err := p.Player.PlayTrack(uri); err != nil {
+		return err
+	}
+
+	p.record(uri)
+	return nil
+}
+
+func (p *recordingPlayer) PlayTracks(uris ...string) error {
+	if err := p.Player.PlayTracks(uris...); err != nil {
+		return err
+	}
+
+	p.record(uris...)
+	return nil
+}
+
+func (p *recordingPlayer) PlayContext(contextUri string, uri ...string) error {
+	if err := p.Player.PlayContext(contextUri, uri...); err != nil {
+		return err
+	}
+
+	p.record(contextUri)
+	return nil
+}
+
+// record is an internal implementation to add played items to
+// the history. The playback already started, so failures are
+// only logged.
+func (p *recordingPlayer) record(uris ...string) {
+	for _, uri := range uris {
+		item, err := p.describe(uri)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+
+		if err := p.history.Add(item); err != nil {
+			log.Println(err)
+		}
+	}
+}
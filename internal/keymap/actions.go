@@ -0,0 +1,153 @@
+package keymap
+
+// The actions which can be bound to keys.
+const (
+	ActionAddToPlaylist    = "add-to-playlist"
+	ActionCopyLink         = "copy-link"
+	ActionLike             = "like"
+	ActionNextPage         = "next-page"
+	ActionNextTrack        = "next-track"
+	ActionOpenAlbum        = "open-album"
+	ActionOpenArtist       = "open-artist"
+	ActionPin              = "pin"
+	ActionPlayAlbum        = "play-album"
+	ActionPlayTrack        = "play-track"
+	ActionPreviousPage     = "previous-page"
+	ActionPreviousTrack    = "previous-track"
+	ActionQueue            = "queue"
+	ActionShowAll          = "show-all"
+	ActionTogglePause      = "toggle-pause"
+	ActionToggleRepeat     = "toggle-repeat"
+	ActionToggleSearchType = "toggle-search-type"
+	ActionToggleShuffle    = "toggle-shuffle"
+)
+
+// mainView is the name of the main menu, where
+// the player controls are bound by default.
+const mainView = "main"
+
+// Action describes an action of the registry.
+type Action struct {
+	Name        string
+	Description string
+	// Keys are the default keys of the action.
+	Keys []string
+	// Legacy is the name of the action in the former
+	// keybindings config (e.g. "addToQueue").
+	Legacy string
+	// DefaultViews are the views where the default keys and
+	// the legacy name apply. When empty, they apply in all
+	// views with the action.
+	DefaultViews []string
+}
+
+// defaultIn is an internal implementation to check if the
+// default keys of an action apply in a view.
+func (a Action) defaultIn(view string) bool {
+	if len(a.DefaultViews) == 0 {
+		return true
+	}
+
+	for _, v := range a.DefaultViews {
+		if v == view {
+			return true
+		}
+	}
+	return false
+}
+
+// registry are all actions which can be bound to keys.
+var registry = []Action{
+	{Name: ActionPlayTrack, Description: "Play track", Keys: []string{"Alt+t"}, Legacy: "playTrack"},
+	{Name: ActionPlayAlbum, Description: "Play album", Keys: []string{"Alt+p"}, Legacy: "playAlbum"},
+	{Name: ActionQueue, Description: "Add to queue", Keys: []string{"Alt+d"}, Legacy: "addToQueue"},
+	{Name: ActionLike, Description: "Like", Keys: []string{"Alt+l"}, Legacy: "likeTrack"},
+	{Name: ActionAddToPlaylist, Description: "Add to playlist", Keys: []string{"Alt+a"}, Legacy: "addToPlaylist"},
+	{Name: ActionPin, Description: "Pin/unpin", Keys: []string{"Alt+i"}, Legacy: "pin"},
+	{Name: ActionOpenAlbum, Description: "Open album", Keys: []string{"Alt+o"}},
+	{Name: ActionOpenArtist, Description: "Open artist", Keys: []string{"Alt+e"}},
+	{Name: ActionCopyLink, Description: "Copy link", Keys: []string{"Alt+c"}},
+	{Name: ActionNextPage, Description: "Next page", Keys: []string{"Alt+Right"}, Legacy: "nextPage"},
+	{Name: ActionPreviousPage, Description: "Previous page", Keys: []string{"Alt+Left"}, Legacy: "previousPage"},
+	{Name: ActionShowAll, Description: "Show all", Keys: []string{"Alt+v"}, Legacy: "showAll"},
+	{Name: ActionToggleSearchType, Description: "Toggle search type", Keys: []string{"Alt+s"}, Legacy: "toggleSearchType"},
+	{
+		Name:         ActionTogglePause,
+		Description:  "Toggle pause/resume",
+		Keys:         []string{"Alt+space"},
+		Legacy:       "togglePauseResume",
+		DefaultViews: []string{mainView},
+	},
+	{
+		Name:         ActionNextTrack,
+		Description:  "Next track",
+		Keys:         []string{"Alt+n"},
+		Legacy:       "nextTrack",
+		DefaultViews: []string{mainView},
+	},
+	{
+		Name:         ActionPreviousTrack,
+		Description:  "Previous track",
+		Keys:         []string{"Alt+p"},
+		Legacy:       "previousTrack",
+		DefaultViews: []string{mainView},
+	},
+	{
+		Name:         ActionToggleRepeat,
+		Description:  "Toggle repeat",
+		Keys:         []string{"Alt+r"},
+		Legacy:       "toggleRepeat",
+		DefaultViews: []string{mainView},
+	},
+	{
+		Name:         ActionToggleShuffle,
+		Description:  "Toggle shuffle",
+		Keys:         []string{"Alt+s"},
+		Legacy:       "toggleShuffle",
+		DefaultViews: []string{mainView},
+	},
+}
+
+// PlayerActions are the player controls,
+// which are available in all views.
+var PlayerActions = []string{
+	ActionTogglePause,
+	ActionNextTrack,
+	ActionPreviousTrack,
+	ActionToggleRepeat,
+	ActionToggleShuffle,
+}
+
+// Actions returns all actions of the registry.
+func Actions() []Action {
+	return append([]Action(nil), registry...)
+}
+
+// Lookup returns an action of the registry by name.
+func Lookup(name string) (Action, bool) {
+	for _, a := range registry {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Action{}, false
+}
+
+// Describe returns the description of an action.
+func Describe(name string) string {
+	if a, ok := Lookup(name); ok {
+		return a.Description
+	}
+	return name
+}
+
+// isLegacy is an internal implementation to check if a
+// name is the legacy name of an action.
+func isLegacy(name string) bool {
+	for _, a := range registry {
+		if a.Legacy == name {
+			return true
+		}
+	}
+	return false
+}
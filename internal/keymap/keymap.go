@@ -0,0 +1,273 @@
+// Package keymap describes the keybindings of the views
+// and validates them against each other and rofi.
+package keymap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/davidborzek/spofi/internal/config"
+)
+
+// MaxCustomKeys is the number of custom keybindings
+// supported by rofi (-kb-custom-1 to -kb-custom-19).
+const MaxCustomKeys = 19
+
+// RofiBuiltins are the default keybindings of rofi
+// by key. A custom keybinding with one of these keys
+// is rejected by rofi.
+var RofiBuiltins = builtins(map[string]string{
+	"kb-accept-alt":              "Shift+Return",
+	"kb-accept-custom":           "Control+Return",
+	"kb-accept-entry":            "Control+j,Control+m,Return,KP_Enter",
+	"kb-cancel":                  "Escape,Control+g,Control+bracketleft",
+	"kb-clear-line":              "Control+w",
+	"kb-delete-entry":            "Shift+Delete",
+	"kb-element-next":            "Tab",
+	"kb-element-prev":            "ISO_Left_Tab",
+	"kb-ellipsize":               "Alt+period",
+	"kb-entry-history-down":      "Control+Down",
+	"kb-entry-history-up":        "Control+Up",
+	"kb-mode-complete":           "Control+l",
+	"kb-mode-next":               "Shift+Right,Control+Tab",
+	"kb-mode-previous":           "Shift+Left,Control+ISO_Left_Tab",
+	"kb-move-char-back":          "Left,Control+b",
+	"kb-move-char-forward":       "Right,Control+f",
+	"kb-move-end":                "Control+e",
+	"kb-move-front":              "Control+a",
+	"kb-move-word-back":          "Alt+b,Control+Left",
+	"kb-move-word-forward":       "Alt+f,Control+Right",
+	"kb-page-next":               "Page_Down",
+	"kb-page-prev":               "Page_Up",
+	"kb-primary-paste":           "Control+V,Shift+Insert",
+	"kb-remove-char-back":        "BackSpace,Shift+BackSpace,Control+h",
+	"kb-remove-char-forward":     "Delete,Control+d",
+	"kb-remove-to-eol":           "Control+k",
+	"kb-remove-to-sol":           "Control+u",
+	"kb-remove-word-back":        "Control+Alt+h,Control+BackSpace",
+	"kb-remove-word-forward":     "Control+Alt+d",
+	"kb-row-down":                "Down,Control+n",
+	"kb-row-first":               "Home,KP_Home",
+	"kb-row-last":                "End,KP_End",
+	"kb-row-left":                "Control+Page_Up",
+	"kb-row-right":               "Control+Page_Down",
+	"kb-row-select":              "Control+space",
+	"kb-row-up":                  "Up,Control+p",
+	"kb-screenshot":              "Alt+S",
+	"kb-secondary-paste":         "Control+v,Insert",
+	"kb-toggle-case-sensitivity": "grave,dead_grave",
+	"kb-toggle-sort":             "Alt+grave",
+	"kb-select-1":                "Super+1",
+	"kb-select-2":                "Super+2",
+	"kb-select-3":                "Super+3",
+	"kb-select-4":                "Super+4",
+	"kb-select-5":                "Super+5",
+	"kb-select-6":                "Super+6",
+	"kb-select-7":                "Super+7",
+	"kb-select-8":                "Super+8",
+	"kb-select-9":                "Super+9",
+	"kb-select-10":               "Super+0",
+})
+
+// binding is a key bound to an action.
+type binding struct {
+	key    string
+	action string
+}
+
+// Keymap is the set of actions of a view
+// and the keys which are bound to them.
+type Keymap struct {
+	// View is the name of the view.
+	View string
+
+	actions  []string
+	bindings []binding
+}
+
+// New creates the keymap of a view with the given actions. The
+// keys of an action are looked up in the config, then by the
+// legacy name of the action and finally the default keys are
+// used. Legacy names and default keys only apply in the default
+// views of an action.
+func New(view string, keybindings config.Keybindings, actions ...string) Keymap {
+	k := Keymap{
+		View:    view,
+		actions: actions,
+	}
+
+	for _, name := range actions {
+		for _, key := range keysOf(view, keybindings, name) {
+			k.bindings = append(k.bindings, binding{key: key, action: name})
+		}
+	}
+
+	return k
+}
+
+// keysOf is an internal implementation to resolve
+// the keys of an action in a view.
+func keysOf(view string, keybindings config.Keybindings, name string) []string {
+	if keys, ok := keybindings.Lookup(view, name); ok {
+		return keys
+	}
+
+	action, ok := Lookup(name)
+	if !ok || !action.defaultIn(view) {
+		return nil
+	}
+
+	if action.Legacy != "" {
+		if keys, ok := keybindings[action.Legacy]; ok {
+			return keys
+		}
+	}
+
+	return action.Keys
+}
+
+// Keys returns all bound keys in order, e.g. as
+// custom keybindings of the menu.
+func (k Keymap) Keys() []string {
+	keys := make([]string, len(k.bindings))
+	for i, b := range k.bindings {
+		keys[i] = b.key
+	}
+	return keys
+}
+
+// Action returns the action which is bound to a key
+// or an empty string. When multiple actions share a
+// key, the first one is returned.
+func (k Keymap) Action(key string) string {
+	for _, b := range k.bindings {
+		if b.key == key {
+			return b.action
+		}
+	}
+	return ""
+}
+
+// Key returns the first key which is bound to an
+// action or an empty string if the action is disabled.
+func (k Keymap) Key(action string) string {
+	for _, b := range k.bindings {
+		if b.action == action {
+			return b.key
+		}
+	}
+	return ""
+}
+
+// Bound returns the actions with at least one bound key in order.
+func (k Keymap) Bound() []string {
+	var actions []string
+	for _, action := range k.actions {
+		if k.Key(action) != "" {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// Validate validates that no two actions of a view share a key, that
+// no key clashes with a builtin keybinding (by key, e.g. RofiBuiltins),
+// that a view does not have more keys than custom keybindings and
+// that the configured keybindings name known views and actions.
+func Validate(keymaps []Keymap, builtins map[string]string, keybindings config.Keybindings) []error {
+	var errs []error
+
+	views := make(map[string]bool, len(keymaps))
+	for _, k := range keymaps {
+		views[k.View] = true
+		errs = append(errs, k.validate(builtins)...)
+	}
+
+	names := make([]string, 0, len(keybindings))
+	for name := range keybindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		view, action, scoped := strings.Cut(name, ".")
+		if !scoped {
+			view, action = "", name
+		}
+
+		if scoped && !views[view] {
+			errs = append(errs, fmt.Errorf("keybindings: unknown view %s in %s", view, name))
+			continue
+		}
+
+		if _, ok := Lookup(action); !ok && (scoped || !isLegacy(action)) {
+			errs = append(errs, fmt.Errorf("keybindings: unknown action %s", name))
+		}
+	}
+
+	return errs
+}
+
+func (k Keymap) validate(builtins map[string]string) []error {
+	var errs []error
+
+	if len(k.bindings) > MaxCustomKeys {
+		errs = append(errs, fmt.Errorf(
+			"%s: %d keybindings exceed the %d custom keybindings of rofi",
+			k.View, len(k.bindings), MaxCustomKeys,
+		))
+	}
+
+	bound := make(map[string]string, len(k.bindings))
+	for _, b := range k.bindings {
+		key := normalize(b.key)
+		if other, ok := bound[key]; ok {
+			if other != b.action {
+				errs = append(errs, fmt.Errorf(
+					"%s: %s and %s are both bound to %s",
+					k.View, other, b.action, b.key,
+				))
+			}
+			continue
+		}
+		bound[key] = b.action
+
+		if builtin, ok := builtins[key]; ok {
+			errs = append(errs, fmt.Errorf(
+				"%s: %s (%s) clashes with the builtin keybinding %s",
+				k.View, b.action, b.key, builtin,
+			))
+		}
+	}
+
+	return errs
+}
+
+// normalize is an internal implementation to get a comparable
+// form of a key. Modifiers are case insensitive and unordered,
+// the key itself is case sensitive (e.g. "Alt+S" is Alt+Shift+s).
+func normalize(key string) string {
+	parts := strings.Split(strings.TrimSpace(key), "+")
+	mods := parts[:len(parts)-1]
+	for i, mod := range mods {
+		mods[i] = strings.ToLower(strings.TrimSpace(mod))
+	}
+	sort.Strings(mods)
+
+	return strings.Join(append(mods, strings.TrimSpace(parts[len(parts)-1])), "+")
+}
+
+// builtins is an internal implementation to map builtin
+// keybindings (by name) to a lookup of their keys.
+func builtins(bindings map[string]string) map[string]string {
+	keys := make(map[string]string)
+	for name, binding := range bindings {
+		for _, key := range strings.Split(binding, ",") {
+			if key != "" {
+				keys[normalize(key)] = name
+			}
+		}
+	}
+	return keys
+}
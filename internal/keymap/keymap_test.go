@@ -0,0 +1,176 @@
+package keymap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/davidborzek/spofi/internal/config"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name        string
+		view        string
+		keybindings config.Keybindings
+		actions     []string
+		keys        []string
+		bound       map[string]string
+	}{
+		{
+			name:    "default keys",
+			view:    "album",
+			actions: []string{ActionPlayAlbum, ActionQueue},
+			keys:    []string{"Alt+p", "Alt+d"},
+		},
+		{
+			name:    "player controls are only bound in the main view",
+			view:    "album",
+			actions: []string{ActionPlayAlbum, ActionPreviousTrack},
+			keys:    []string{"Alt+p"},
+		},
+		{
+			name:    "player controls in the main view",
+			view:    "main",
+			actions: []string{ActionPreviousTrack},
+			keys:    []string{"Alt+p"},
+		},
+		{
+			name:        "configured keys",
+			view:        "album",
+			keybindings: config.Keybindings{ActionQueue: {"Alt+q", "Alt+w"}, ActionPreviousTrack: {"Alt+b"}},
+			actions:     []string{ActionQueue, ActionPreviousTrack},
+			keys:        []string{"Alt+q", "Alt+w", "Alt+b"},
+			bound:       map[string]string{"Alt+w": ActionQueue, "Alt+b": ActionPreviousTrack},
+		},
+		{
+			name: "view keys take precedence",
+			view: "album",
+			keybindings: config.Keybindings{
+				ActionQueue:            {"Alt+q"},
+				"album." + ActionQueue: {"Alt+w"},
+			},
+			actions: []string{ActionQueue},
+			keys:    []string{"Alt+w"},
+		},
+		{
+			name:        "disabled action",
+			view:        "album",
+			keybindings: config.Keybindings{ActionQueue: {}},
+			actions:     []string{ActionPlayAlbum, ActionQueue},
+			keys:        []string{"Alt+p"},
+		},
+		{
+			name:        "legacy name",
+			view:        "main",
+			keybindings: config.Keybindings{"addToQueue": {"Alt+q"}, "previousTrack": {"Alt+b"}},
+			actions:     []string{ActionQueue, ActionPreviousTrack},
+			keys:        []string{"Alt+q", "Alt+b"},
+		},
+		{
+			name:        "legacy player controls only apply in the main view",
+			view:        "album",
+			keybindings: config.Keybindings{"previousTrack": {"Alt+b"}},
+			actions:     []string{ActionPreviousTrack},
+			keys:        []string{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			k := New(tc.view, tc.keybindings, tc.actions...)
+			if keys := k.Keys(); !reflect.DeepEqual(keys, tc.keys) {
+				t.Fatalf("unexpected keys %v, want %v", keys, tc.keys)
+			}
+
+			for key, action := range tc.bound {
+				if a := k.Action(key); a != action {
+					t.Errorf("unexpected action %q for %s, want %q", a, key, action)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		keybindings config.Keybindings
+		actions     []string
+		builtins    map[string]string
+		errs        []string
+	}{
+		{
+			name:     "default keys",
+			actions:  []string{ActionPlayAlbum, ActionQueue, ActionPin, ActionCopyLink},
+			builtins: RofiBuiltins,
+		},
+		{
+			name:        "same key",
+			keybindings: config.Keybindings{ActionQueue: {"alt+p"}},
+			actions:     []string{ActionPlayAlbum, ActionQueue},
+			errs:        []string{"main: play-album and queue are both bound to alt+p"},
+		},
+		{
+			name:        "unordered modifiers",
+			keybindings: config.Keybindings{ActionQueue: {"Control+Alt+x"}, ActionLike: {"Alt+Control+x"}},
+			actions:     []string{ActionQueue, ActionLike},
+			errs:        []string{"main: queue and like are both bound to Alt+Control+x"},
+		},
+		{
+			name:        "case sensitive key",
+			keybindings: config.Keybindings{ActionQueue: {"Alt+s"}, ActionLike: {"Alt+S"}},
+			actions:     []string{ActionQueue, ActionLike},
+		},
+		{
+			name:        "rofi builtin",
+			keybindings: config.Keybindings{ActionPin: {"Alt+f"}},
+			actions:     []string{ActionPin},
+			builtins:    RofiBuiltins,
+			errs:        []string{"main: pin (Alt+f) clashes with the builtin keybinding kb-move-word-forward"},
+		},
+		{
+			name: "too many keys",
+			keybindings: func() config.Keybindings {
+				keys := make(config.KeyList, MaxCustomKeys+1)
+				for i := range keys {
+					keys[i] = "Alt+F" + strings.Repeat("1", i+1)
+				}
+				return config.Keybindings{ActionQueue: keys}
+			}(),
+			actions: []string{ActionQueue},
+			errs:    []string{"main: 20 keybindings exceed the 19 custom keybindings of rofi"},
+		},
+		{
+			name: "unknown names",
+			keybindings: config.Keybindings{
+				"nope":            {"Alt+x"},
+				"other.queue":     {"Alt+y"},
+				"addToQueue":      {"Alt+z"},
+				"main.addToQueue": {"Alt+w"},
+			},
+			errs: []string{
+				"keybindings: unknown action main.addToQueue",
+				"keybindings: unknown action nope",
+				"keybindings: unknown view other in other.queue",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			k := New("main", tc.keybindings, tc.actions...)
+
+			errs := Validate([]Keymap{k}, tc.builtins, tc.keybindings)
+			if len(errs) != len(tc.errs) {
+				t.Fatalf("unexpected errors %v", errs)
+			}
+
+			for i, err := range errs {
+				if err.Error() != tc.errs[i] {
+					t.Errorf("unexpected error %q, want %q", err, tc.errs[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,605 @@
+package library
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/davidborzek/spofi/internal/xdg"
+	"github.com/davidborzek/spofi/pkg/spotify"
+)
+
+const (
+	cacheFileName = "library.json"
+
+	// pageLimit is the maximum page size of the spotify api.
+	pageLimit = 50
+
+	// saveDelay is the delay of writing the cache file, so the
+	// changes of a refresh and following invalidations (e.g.
+	// when several tracks are liked) are written at once.
+	saveDelay = 2 * time.Second
+)
+
+// data is the cached library which is
+// stored as json in the cache file.
+type data struct {
+	LikedTracks []spotify.SavedTrack               `json:"likedTracks"`
+	SavedAlbums []spotify.SavedAlbum               `json:"savedAlbums"`
+	Playlists   []spotify.Playlist                 `json:"playlists"`
+	Albums      map[string]spotify.AlbumWithTracks `json:"albums"`
+
+	// PlaylistTracks are the tracks of the playlists
+	// which were opened, by playlist id.
+	PlaylistTracks map[string]playlistTracks `json:"playlistTracks"`
+
+	// The collections are nil until they were fetched once,
+	// so stale flags are only needed for invalidation.
+	LikedTracksStale bool `json:"likedTracksStale"`
+}
+
+// playlistTracks are the cached tracks of a playlist with
+// the snapshot id of the playlist when they were fetched.
+type playlistTracks struct {
+	SnapshotID string                  `json:"snapshotId"`
+	Tracks     []spotify.PlaylistTrack `json:"tracks"`
+}
+
+// Library is a local cache of the library of the user (liked
+// tracks, saved albums, playlists and album details).
+//
+// Cached collections are served first and refreshed once in
+// the background per run. Refreshes are incremental: new items
+// are fetched from the start until a known item (same id
+// and added_at) is found. When the result does not match the
+// total, e.g. because items were removed by another client, the
+// collection is fetched again completely. Playlists are not ordered
+// by date, so the tracks of a playlist are only fetched again when
+// its snapshot id changed.
+//
+// Changes are written to the cache file after a short delay, so
+// a refresh is written at once. Wait writes the pending changes.
+type Library struct {
+	path   string
+	client spotify.Client
+
+	mu   sync.Mutex
+	data data
+
+	wg        sync.WaitGroup
+	refreshed map[string]bool
+
+	// saving is the pending write of the cache file.
+	saving *time.Timer
+	// writeMu serializes the writes of the cache file,
+	// which are done without locking the cache.
+	writeMu sync.Mutex
+}
+
+// Open opens the library cache of a profile in the os user cache
+// dir. The default profile has an empty name. Without a cache dir,
+// the library is only cached in memory.
+func Open(client spotify.Client, profile string) *Library {
+	// An empty path keeps the library in memory.
+	path, _ := xdg.CacheFile(cacheFileName, profile)
+	return open(path, client)
+}
+
+// open is an internal implementation to open
+// the library cache at a given path.
+func open(path string, client spotify.Client) *Library {
+	l := &Library{
+		path:      path,
+		client:    client,
+		refreshed: map[string]bool{},
+	}
+
+	// A missing or corrupt cache is ignored and rebuilt.
+	if raw, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(raw, &l.data)
+	}
+
+	if l.data.Albums == nil {
+		l.data.Albums = map[string]spotify.AlbumWithTracks{}
+	}
+
+	if l.data.PlaylistTracks == nil {
+		l.data.PlaylistTracks = map[string]playlistTracks{}
+	}
+
+	return l
+}
+
+// save is an internal implementation to schedule a write of the
+// cache file. It must be called with the mutex locked. Changes
+// within the save delay are written at once.
+func (l *Library) save() {
+	if l.path == "" || l.saving != nil {
+		return
+	}
+
+	l.saving = time.AfterFunc(saveDelay, l.flush)
+}
+
+// flush is an internal implementation to write the pending
+// changes to the cache file. The cache is only an optimization,
+// so write errors are ignored.
+func (l *Library) flush() {
+	l.writeMu.Lock()
+	defer l.writeMu.Unlock()
+
+	l.mu.Lock()
+	if l.saving == nil {
+		l.mu.Unlock()
+		return
+	}
+
+	l.saving.Stop()
+	l.saving = nil
+	raw, err := json.Marshal(l.data)
+	l.mu.Unlock()
+
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return
+	}
+
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0644); err != nil {
+		return
+	}
+
+	os.Rename(tmp, l.path)
+}
+
+// Wait waits for running background refreshes and writes
+// the pending changes before the app exits.
+func (l *Library) Wait() {
+	l.wg.Wait()
+	l.flush()
+}
+
+// serve is an internal implementation to serve a cached
+// collection. When the collection is not cached (or stale),
+// it is refreshed synchronously, otherwise a refresh is started
+// in the background once per run.
+func (l *Library) serve(name string, cached bool, refresh func() error) error {
+	l.mu.Lock()
+	started := l.refreshed[name]
+	l.refreshed[name] = true
+	l.mu.Unlock()
+
+	if !cached {
+		return refresh()
+	}
+
+	if !started {
+		l.wg.Add(1)
+		go func() {
+			defer l.wg.Done()
+			// Failures are ignored, as the cached
+			// collection is still served.
+			refresh()
+		}()
+	}
+
+	return nil
+}
+
+// LikedTracks returns the liked tracks of the user, newest first.
+func (l *Library) LikedTracks() ([]spotify.SavedTrack, error) {
+	l.mu.Lock()
+	cached := l.data.LikedTracks != nil && !l.data.LikedTracksStale
+	l.mu.Unlock()
+
+	if err := l.serve("likedTracks", cached, l.refreshLikedTracks); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.data.LikedTracks, nil
+}
+
+// HasLikedTracks checks if the liked tracks are cached.
+func (l *Library) HasLikedTracks() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.data.LikedTracks != nil
+}
+
+// IsLiked checks if a track is in the cached liked tracks.
+// It never fetches, so tracks are not liked before the
+// liked tracks were cached.
+func (l *Library) IsLiked(id string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, t := range l.data.LikedTracks {
+		if t.Track.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// InvalidateLikedTracks marks the cached liked tracks as stale,
+// e.g. after tracks were liked, so they are refreshed on the
+// next access.
+func (l *Library) InvalidateLikedTracks() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.data.LikedTracksStale = true
+	l.save()
+}
+
+// StreamLikedTracks sends the liked tracks of the user, newest
+// first. When they are not cached, each page is sent as soon as
+// it was fetched, so the tracks can be shown while loading.
+func (l *Library) StreamLikedTracks(send func(tracks []spotify.SavedTrack)) error {
+	l.mu.Lock()
+	cached := l.data.LikedTracks != nil
+	if !cached {
+		l.refreshed["likedTracks"] = true
+	}
+	l.mu.Unlock()
+
+	if !cached {
+		return l.fetchLikedTracks(send)
+	}
+
+	tracks, err := l.LikedTracks()
+	if err != nil {
+		return err
+	}
+
+	send(tracks)
+	return nil
+}
+
+func (l *Library) refreshLikedTracks() error {
+	return l.fetchLikedTracks(nil)
+}
+
+func (l *Library) fetchLikedTracks(progress func([]spotify.SavedTrack)) error {
+	l.mu.Lock()
+	cached := l.data.LikedTracks
+	l.mu.Unlock()
+
+	tracks, err := merge(cached, func(t spotify.SavedTrack) string {
+		return t.Track.ID + t.AddedAt
+	}, func(offset int) ([]spotify.SavedTrack, int, error) {
+		res, err := l.client.GetLikedTracks(pageLimit, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		return res.Items, res.Total, nil
+	}, progress)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.data.LikedTracks = tracks
+	l.data.LikedTracksStale = false
+	l.save()
+	return nil
+}
+
+// SavedAlbums returns the saved albums of the user, newest first.
+func (l *Library) SavedAlbums() ([]spotify.SavedAlbum, error) {
+	l.mu.Lock()
+	cached := l.data.SavedAlbums != nil
+	l.mu.Unlock()
+
+	if err := l.serve("savedAlbums", cached, l.refreshSavedAlbums); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.data.SavedAlbums, nil
+}
+
+func (l *Library) refreshSavedAlbums() error {
+	l.mu.Lock()
+	cached := l.data.SavedAlbums
+	l.mu.Unlock()
+
+	albums, err := merge(cached, func(a spotify.SavedAlbum) string {
+		return a.Album.ID + a.AddedAt
+	}, func(offset int) ([]spotify.SavedAlbum, int, error) {
+		res, err := l.client.GetSavedAlbums(pageLimit, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		return res.Items, res.Total, nil
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.data.SavedAlbums = albums
+	for _, a := range albums {
+		l.data.Albums[a.Album.ID] = a.Album
+	}
+	l.save()
+	return nil
+}
+
+// Playlists returns the playlists of the user.
+func (l *Library) Playlists() ([]spotify.Playlist, error) {
+	l.mu.Lock()
+	cached := l.data.Playlists != nil
+	l.mu.Unlock()
+
+	if err := l.serve("playlists", cached, l.refreshPlaylists); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.data.Playlists, nil
+}
+
+// refreshPlaylists fetches the playlists of the user. The
+// playlists are not ordered by date, so they are always
+// fetched completely (one request per 50 playlists). The
+// cached tracks of playlists whose snapshot id changed are
+// fetched again, the tracks of removed playlists are dropped.
+func (l *Library) refreshPlaylists() error {
+	var playlists []spotify.Playlist
+	for offset := 0; ; {
+		res, err := l.client.GetPlaylists(pageLimit, offset)
+		if err != nil {
+			return err
+		}
+
+		playlists = append(playlists, res.Items...)
+		offset += len(res.Items)
+		if len(res.Items) == 0 || offset >= res.Total {
+			break
+		}
+	}
+
+	if playlists == nil {
+		playlists = []spotify.Playlist{}
+	}
+
+	l.mu.Lock()
+	snapshots := make(map[string]string, len(playlists))
+	for _, p := range playlists {
+		snapshots[p.ID] = p.SnapshotID
+	}
+
+	var changed []spotify.Playlist
+	for id, cached := range l.data.PlaylistTracks {
+		snapshot, ok := snapshots[id]
+		if !ok {
+			delete(l.data.PlaylistTracks, id)
+		} else if snapshot != cached.SnapshotID {
+			changed = append(changed, spotify.Playlist{ID: id, SnapshotID: snapshot})
+		}
+	}
+
+	l.data.Playlists = playlists
+	l.save()
+	l.mu.Unlock()
+
+	for _, p := range changed {
+		if _, err := l.fetchPlaylistTracks(p.ID, p.SnapshotID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PlaylistTracks returns the tracks of a playlist of the user.
+// The cached tracks are served as long as the snapshot id of
+// the playlist did not change.
+func (l *Library) PlaylistTracks(id string) ([]spotify.PlaylistTrack, error) {
+	playlist, err := l.Playlist(id)
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	cached, ok := l.data.PlaylistTracks[id]
+	l.mu.Unlock()
+
+	if ok && cached.SnapshotID == playlist.SnapshotID {
+		return cached.Tracks, nil
+	}
+
+	return l.fetchPlaylistTracks(id, playlist.SnapshotID)
+}
+
+// InvalidatePlaylist drops the cached tracks of a playlist,
+// e.g. after tracks were added, so they are fetched again
+// on the next access.
+func (l *Library) InvalidatePlaylist(id string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.data.PlaylistTracks, id)
+	l.save()
+}
+
+// fetchPlaylistTracks is an internal implementation to fetch
+// the tracks of a playlist and cache them with the snapshot id.
+// Local and unavailable tracks are skipped.
+func (l *Library) fetchPlaylistTracks(id string, snapshotID string) ([]spotify.PlaylistTrack, error) {
+	tracks := []spotify.PlaylistTrack{}
+	for offset := 0; ; {
+		res, err := l.client.GetPlaylistTracks(id, pageLimit, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range res.Items {
+			if t.Track.ID != "" {
+				tracks = append(tracks, t)
+			}
+		}
+
+		offset += len(res.Items)
+		if len(res.Items) == 0 || offset >= res.Total {
+			break
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.data.PlaylistTracks[id] = playlistTracks{
+		SnapshotID: snapshotID,
+		Tracks:     tracks,
+	}
+	l.save()
+	return tracks, nil
+}
+
+// Album returns an album with its tracks. Albums do not
+// change, so cached albums are never refreshed.
+func (l *Library) Album(id string) (*spotify.AlbumWithTracks, error) {
+	l.mu.Lock()
+	album, ok := l.data.Albums[id]
+	l.mu.Unlock()
+
+	if ok {
+		return &album, nil
+	}
+
+	res, err := l.client.GetAlbum(id)
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.data.Albums[id] = *res
+	l.save()
+	return res, nil
+}
+
+// Track returns a track. Liked tracks and tracks of cached
+// albums are served from the cache, other tracks are fetched.
+func (l *Library) Track(id string) (*spotify.Track, error) {
+	l.mu.Lock()
+	track, ok := l.cachedTrack(id)
+	l.mu.Unlock()
+
+	if ok {
+		return &track, nil
+	}
+
+	return l.client.GetTrack(id)
+}
+
+// cachedTrack is an internal implementation to find a track in the
+// cache. It must be called with the mutex locked.
+func (l *Library) cachedTrack(id string) (spotify.Track, bool) {
+	for _, t := range l.data.LikedTracks {
+		if t.Track.ID == id {
+			return t.Track, true
+		}
+	}
+
+	for _, a := range l.data.Albums {
+		for _, t := range a.Tracks.Items {
+			if t.ID == id {
+				// Album tracks do not contain their album.
+				t.Album = a.Album
+				return t, true
+			}
+		}
+	}
+
+	return spotify.Track{}, false
+}
+
+// Playlist returns a playlist of the user.
+func (l *Library) Playlist(id string) (*spotify.Playlist, error) {
+	playlists, err := l.Playlists()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range playlists {
+		if p.ID == id {
+			return &p, nil
+		}
+	}
+
+	return nil, fmt.Errorf("playlist not found: %s", id)
+}
+
+// merge is an internal implementation to refresh a collection
+// which is ordered by date (newest first). New items are fetched
+// until a cached item is found and put in front of the cached
+// items. If the merged collection does not match the total, the
+// collection is fetched completely. Without cached items, progress
+// (if set) is called with every fetched page.
+func merge[T any](
+	cached []T,
+	key func(T) string,
+	fetch func(offset int) ([]T, int, error),
+	progress func([]T),
+) ([]T, error) {
+	known := map[string]bool{}
+	for _, item := range cached {
+		known[key(item)] = true
+	}
+
+	items := []T{}
+	total := 0
+	for offset := 0; ; {
+		page, t, err := fetch(offset)
+		if err != nil {
+			return nil, err
+		}
+		total = t
+
+		for _, item := range page {
+			if known[key(item)] {
+				merged := append(items, cached...)
+				if len(merged) == total {
+					return merged, nil
+				}
+
+				return merge(nil, key, fetch, nil)
+			}
+
+			items = append(items, item)
+		}
+
+		if cached == nil && progress != nil {
+			progress(page)
+		}
+
+		offset += len(page)
+		if len(page) == 0 || offset >= total {
+			break
+		}
+	}
+
+	return items, nil
+}
@@ -0,0 +1,353 @@
+package library
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/davidborzek/spofi/pkg/spotify"
+	"github.com/davidborzek/spofi/pkg/spotify/spotifytest"
+)
+
+func newTracks(n int) []spotify.Track {
+	tracks := make([]spotify.Track, n)
+	for i := range tracks {
+		tracks[i] = spotifytest.NewTrack(fmt.Sprintf("track-%d", i), fmt.Sprintf("Track %d", i), "Artist")
+	}
+	return tracks
+}
+
+func trackIDs(saved []spotify.SavedTrack) []string {
+	ids := make([]string, len(saved))
+	for i, t := range saved {
+		ids[i] = t.Track.ID
+	}
+	return ids
+}
+
+// countRequests counts the GET requests to a path.
+func countRequests(srv *spotifytest.Server, path string) int {
+	n := 0
+	for _, req := range srv.Requests() {
+		if req.Method == http.MethodGet && req.Path == path {
+			n++
+		}
+	}
+	return n
+}
+
+func TestLikedTracks(t *testing.T) {
+	srv := spotifytest.NewServer()
+	t.Cleanup(srv.Close)
+
+	tracks := newTracks(120)
+	srv.LikeTracks(tracks[:110]...)
+	srv.AddTracks(tracks[110:]...)
+
+	path := filepath.Join(t.TempDir(), "library.json")
+
+	// The first access fetches all pages synchronously.
+	lib := open(path, srv.NewClient())
+	saved, err := lib.LikedTracks()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(saved) != 110 || saved[0].Track.ID != "track-0" {
+		t.Fatalf("unexpected liked tracks %v", trackIDs(saved))
+	}
+
+	if n := countRequests(srv, "/me/tracks"); n != 3 {
+		t.Fatalf("expected 3 page requests, got %d", n)
+	}
+
+	// Liking a track invalidates the cache, so the new
+	// track is fetched incrementally on the next access.
+	client := srv.NewClient()
+	if err := client.SaveTracks([]string{"track-110"}); err != nil {
+		t.Fatal(err)
+	}
+	lib.InvalidateLikedTracks()
+
+	saved, err = lib.LikedTracks()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(saved) != 111 || saved[0].Track.ID != "track-110" {
+		t.Fatalf("unexpected liked tracks %v", trackIDs(saved))
+	}
+
+	if n := countRequests(srv, "/me/tracks"); n != 4 {
+		t.Fatalf("expected a single incremental request, got %d requests", n)
+	}
+
+	// A new instance serves the cache persisted on exit
+	// and refreshes it in the background.
+	lib.Wait()
+	srv.UnlikeTracks("track-5")
+	client.SaveTracks([]string{"track-111"})
+
+	lib = open(path, srv.NewClient())
+	saved, err = lib.LikedTracks()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(saved) != 111 {
+		t.Fatalf("expected the cached liked tracks, got %v", trackIDs(saved))
+	}
+
+	lib.Wait()
+
+	// The removed track does not match the total,
+	// so the liked tracks were fetched completely.
+	saved, _ = lib.LikedTracks()
+	if !reflect.DeepEqual(trackIDs(saved), trackIDs(toSaved(srv.LikedTracks()))) {
+		t.Fatalf("expected the refreshed liked tracks, got %v", trackIDs(saved))
+	}
+}
+
+func toSaved(tracks []spotify.Track) []spotify.SavedTrack {
+	saved := make([]spotify.SavedTrack, len(tracks))
+	for i, t := range tracks {
+		saved[i].Track = t
+	}
+	return saved
+}
+
+func TestSavedAlbumsAndAlbums(t *testing.T) {
+	srv := spotifytest.NewServer()
+	t.Cleanup(srv.Close)
+
+	tracks := newTracks(2)
+	saved := spotifytest.NewAlbum("album-1", "Saved", "Artist", tracks[0])
+	other := spotifytest.NewAlbum("album-2", "Other", "Artist", tracks[1])
+	srv.SaveAlbums(saved)
+	srv.AddAlbum(other)
+
+	lib := open(filepath.Join(t.TempDir(), "library.json"), srv.NewClient())
+
+	albums, err := lib.SavedAlbums()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(albums) != 1 || albums[0].Album.ID != "album-1" {
+		t.Fatalf("unexpected saved albums %+v", albums)
+	}
+
+	// Saved albums are already cached with their tracks.
+	for _, id := range []string{"album-1", "album-2", "album-2"} {
+		album, err := lib.Album(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if album.ID != id {
+			t.Fatalf("expected album %s, got %s", id, album.ID)
+		}
+	}
+
+	if n := countRequests(srv, "/albums/album-1"); n != 0 {
+		t.Fatalf("expected saved album to be cached, got %d requests", n)
+	}
+
+	if n := countRequests(srv, "/albums/album-2"); n != 1 {
+		t.Fatalf("expected album to be fetched once, got %d requests", n)
+	}
+}
+
+func TestPlaylists(t *testing.T) {
+	srv := spotifytest.NewServer()
+	t.Cleanup(srv.Close)
+
+	srv.AddPlaylist(spotifytest.NewPlaylist("playlist-1", "Focus"))
+
+	path := filepath.Join(t.TempDir(), "library.json")
+	lib := open(path, srv.NewClient())
+
+	playlists, err := lib.Playlists()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(playlists) != 1 || playlists[0].SnapshotID == "" {
+		t.Fatalf("unexpected playlists %+v", playlists)
+	}
+
+	srv.AddPlaylist(spotifytest.NewPlaylist("playlist-2", "Run"))
+
+	lib.Wait()
+	lib = open(path, srv.NewClient())
+	if playlists, _ := lib.Playlists(); len(playlists) != 1 {
+		t.Fatalf("expected the cached playlists, got %+v", playlists)
+	}
+
+	lib.Wait()
+	if playlists, _ := lib.Playlists(); len(playlists) != 2 {
+		t.Fatalf("expected the refreshed playlists, got %+v", playlists)
+	}
+}
+
+func TestPlaylistTracks(t *testing.T) {
+	srv := spotifytest.NewServer()
+	t.Cleanup(srv.Close)
+
+	tracks := newTracks(3)
+	srv.AddTracks(tracks...)
+	srv.AddPlaylist(spotifytest.NewPlaylist("playlist-1", "Focus", tracks[0], tracks[1]))
+	srv.AddPlaylist(spotifytest.NewPlaylist("playlist-2", "Run", tracks[2]))
+
+	path := filepath.Join(t.TempDir(), "library.json")
+	lib := open(path, srv.NewClient())
+
+	for _, id := range []string{"playlist-1", "playlist-2"} {
+		if _, err := lib.PlaylistTracks(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Changing a playlist changes its snapshot id.
+	if err := srv.NewClient().AddPlaylistTracks("playlist-1", []string{tracks[2].URI}); err != nil {
+		t.Fatal(err)
+	}
+
+	lib.Wait()
+	lib = open(path, srv.NewClient())
+	if cached, _ := lib.PlaylistTracks("playlist-1"); len(cached) != 2 {
+		t.Fatalf("expected the cached tracks, got %+v", cached)
+	}
+
+	lib.Wait()
+	if refreshed, _ := lib.PlaylistTracks("playlist-1"); len(refreshed) != 3 {
+		t.Fatalf("expected the refreshed tracks, got %+v", refreshed)
+	}
+
+	// Only the tracks of the changed playlist are fetched again.
+	if n := countRequests(srv, "/playlists/playlist-1/tracks"); n != 2 {
+		t.Fatalf("expected the changed playlist to be fetched twice, got %d requests", n)
+	}
+
+	if n := countRequests(srv, "/playlists/playlist-2/tracks"); n != 1 {
+		t.Fatalf("expected the unchanged playlist to be fetched once, got %d requests", n)
+	}
+}
+
+func TestSaveBatched(t *testing.T) {
+	srv := spotifytest.NewServer()
+	t.Cleanup(srv.Close)
+
+	srv.LikeTracks(newTracks(10)...)
+	srv.AddPlaylist(spotifytest.NewPlaylist("playlist-1", "Focus"))
+
+	path := filepath.Join(t.TempDir(), "library.json")
+	lib := open(path, srv.NewClient())
+	lib.LikedTracks()
+	lib.Playlists()
+	lib.InvalidateLikedTracks()
+	lib.InvalidatePlaylist("playlist-1")
+
+	// The changes are written at once after the save delay.
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected no cache file before the save delay, got %v", err)
+	}
+
+	lib.Wait()
+
+	lib = open(path, srv.NewClient())
+	if !lib.data.LikedTracksStale || len(lib.data.Playlists) != 1 {
+		t.Fatalf("expected the changes to be written, got %+v", lib.data)
+	}
+}
+
+func TestConcurrentRefresh(t *testing.T) {
+	srv := spotifytest.NewServer()
+	t.Cleanup(srv.Close)
+
+	srv.LikeTracks(newTracks(10)...)
+	srv.SaveAlbums(spotifytest.NewAlbum("album-1", "Album", "Artist"))
+	srv.AddPlaylist(spotifytest.NewPlaylist("playlist-1", "Focus"))
+
+	path := filepath.Join(t.TempDir(), "library.json")
+	lib := open(path, srv.NewClient())
+	lib.LikedTracks()
+	lib.SavedAlbums()
+	lib.Playlists()
+	lib.Wait()
+
+	// The background refreshes share the client with the menus,
+	// so an expired token is refreshed concurrently.
+	srv.ExpireTokens()
+
+	client := srv.NewClient()
+	lib = open(path, client)
+	lib.LikedTracks()
+	lib.SavedAlbums()
+	lib.Playlists()
+
+	if _, err := client.GetDevices(); err != nil {
+		t.Fatal(err)
+	}
+
+	lib.Wait()
+}
+
+func TestFetchError(t *testing.T) {
+	srv := spotifytest.NewServer()
+	t.Cleanup(srv.Close)
+
+	srv.InjectFault(spotifytest.Fault{Path: "/me/tracks", Status: 500})
+
+	lib := open(filepath.Join(t.TempDir(), "library.json"), srv.NewClient())
+	if _, err := lib.LikedTracks(); !spotify.IsStatusErr(err, 500) {
+		t.Fatalf("expected a status error, got %v", err)
+	}
+}
+
+func TestStreamLikedTracks(t *testing.T) {
+	srv := spotifytest.NewServer()
+	t.Cleanup(srv.Close)
+
+	srv.LikeTracks(newTracks(120)...)
+
+	lib := open(filepath.Join(t.TempDir(), "library.json"), srv.NewClient())
+
+	// Without a cache, every page is sent while loading.
+	var pages []int
+	var streamed []spotify.SavedTrack
+	send := func(tracks []spotify.SavedTrack) {
+		pages = append(pages, len(tracks))
+		streamed = append(streamed, tracks...)
+	}
+
+	if err := lib.StreamLikedTracks(send); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(pages, []int{50, 50, 20}) {
+		t.Fatalf("unexpected pages %v", pages)
+	}
+
+	saved, _ := lib.LikedTracks()
+	if !reflect.DeepEqual(trackIDs(streamed), trackIDs(saved)) {
+		t.Fatalf("expected the streamed tracks to be cached, got %v", trackIDs(saved))
+	}
+
+	// Cached tracks are sent at once.
+	pages = nil
+	if err := lib.StreamLikedTracks(send); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(pages, []int{120}) {
+		t.Fatalf("unexpected pages %v", pages)
+	}
+
+	if n := countRequests(srv, "/me/tracks"); n != 3 {
+		t.Fatalf("expected 3 page requests, got %d", n)
+	}
+}
@@ -12,6 +12,8 @@ type Player interface {
 	ToggleShuffle() error
 	// PlayTracks play a given track.
 	PlayTrack(uri string) error
+	// PlayTracks plays the given tracks in order.
+	PlayTracks(uris ...string) error
 	// PlayContext plays a given context (playlist, album, etc.)
 	// and can optionally handle a given uri in the context.
 	PlayContext(contextUri string, uri ...string) error
@@ -43,7 +45,7 @@ func (p *player) PlayPause() error {
 		return err
 	}
 
-	if state.IsPlaying {
+	if state != nil && state.IsPlaying {
 		return p.client.Pause(p.device)
 	}
 	return p.client.Play(p.device)
@@ -87,6 +89,10 @@ func (p *player) PlayTrack(uri string) error {
 	return p.client.PlayTrack(uri, p.device)
 }
 
+func (p *player) PlayTracks(uris ...string) error {
+	return p.client.PlayTracks(uris, p.device)
+}
+
 func (p *player) PlayContext(contextUri string, uri ...string) error {
 	return p.client.PlayContext(contextUri, p.device, uri...)
 }
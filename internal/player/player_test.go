@@ -0,0 +1,242 @@
+package player_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/davidborzek/spofi/internal/player"
+	"github.com/davidborzek/spofi/pkg/spotify"
+	"github.com/davidborzek/spofi/pkg/spotify/spotifytest"
+)
+
+func newServer(t *testing.T) *spotifytest.Server {
+	t.Helper()
+
+	srv := spotifytest.NewServer()
+	t.Cleanup(srv.Close)
+
+	srv.AddDevice(spotifytest.NewDevice("device-1", "Laptop"))
+	srv.AddDevice(spotifytest.NewDevice("device-2", "Phone"))
+	srv.AddAlbum(spotifytest.NewAlbum("album-1", "Album", "Artist",
+		spotifytest.NewTrack("track-1", "One", "Artist"),
+		spotifytest.NewTrack("track-2", "Two", "Artist"),
+	))
+
+	return srv
+}
+
+func TestPlayer(t *testing.T) {
+	tests := []struct {
+		name    string
+		device  string
+		actions func(p player.Player) error
+		want    spotify.Player
+	}{
+		{
+			name: "play pause toggles",
+			actions: func(p player.Player) error {
+				if err := p.PlayTrack("spotify:track:track-1"); err != nil {
+					return err
+				}
+				return p.PlayPause()
+			},
+			want: spotify.Player{
+				Item:        spotify.Track{ID: "track-1"},
+				Device:      spotify.Device{ID: "device-1"},
+				RepeatState: spotify.RepeatOff,
+			},
+		},
+		{
+			name: "play pause twice resumes",
+			actions: func(p player.Player) error {
+				if err := p.PlayTrack("spotify:track:track-1"); err != nil {
+					return err
+				}
+				if err := p.PlayPause(); err != nil {
+					return err
+				}
+				return p.PlayPause()
+			},
+			want: spotify.Player{
+				Item:        spotify.Track{ID: "track-1"},
+				Device:      spotify.Device{ID: "device-1"},
+				RepeatState: spotify.RepeatOff,
+				IsPlaying:   true,
+			},
+		},
+		{
+			name: "toggle repeat cycles off context track",
+			actions: func(p player.Player) error {
+				if err := p.PlayTrack("spotify:track:track-1"); err != nil {
+					return err
+				}
+				if err := p.ToggleRepeat(); err != nil {
+					return err
+				}
+				return p.ToggleRepeat()
+			},
+			want: spotify.Player{
+				Item:        spotify.Track{ID: "track-1"},
+				Device:      spotify.Device{ID: "device-1"},
+				RepeatState: spotify.RepeatTrack,
+				IsPlaying:   true,
+			},
+		},
+		{
+			name: "toggle repeat wraps to off",
+			actions: func(p player.Player) error {
+				if err := p.PlayTrack("spotify:track:track-1"); err != nil {
+					return err
+				}
+				for i := 0; i < 3; i++ {
+					if err := p.ToggleRepeat(); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			want: spotify.Player{
+				Item:        spotify.Track{ID: "track-1"},
+				Device:      spotify.Device{ID: "device-1"},
+				RepeatState: spotify.RepeatOff,
+				IsPlaying:   true,
+			},
+		},
+		{
+			name: "toggle shuffle",
+			actions: func(p player.Player) error {
+				if err := p.PlayTrack("spotify:track:track-1"); err != nil {
+					return err
+				}
+				return p.ToggleShuffle()
+			},
+			want: spotify.Player{
+				Item:         spotify.Track{ID: "track-1"},
+				Device:       spotify.Device{ID: "device-1"},
+				RepeatState:  spotify.RepeatOff,
+				ShuffleState: true,
+				IsPlaying:    true,
+			},
+		},
+		{
+			name:   "uses configured device",
+			device: "device-2",
+			actions: func(p player.Player) error {
+				return p.PlayContext("spotify:album:album-1", "spotify:track:track-2")
+			},
+			want: spotify.Player{
+				Item:        spotify.Track{ID: "track-2"},
+				Device:      spotify.Device{ID: "device-2"},
+				RepeatState: spotify.RepeatOff,
+				IsPlaying:   true,
+			},
+		},
+		{
+			name: "set device",
+			actions: func(p player.Player) error {
+				p.SetDevice("device-2")
+				return p.PlayTrack("spotify:track:track-2")
+			},
+			want: spotify.Player{
+				Item:        spotify.Track{ID: "track-2"},
+				Device:      spotify.Device{ID: "device-2"},
+				RepeatState: spotify.RepeatOff,
+				IsPlaying:   true,
+			},
+		},
+		{
+			name: "next plays queued track",
+			actions: func(p player.Player) error {
+				if err := p.PlayContext("spotify:album:album-1"); err != nil {
+					return err
+				}
+				if err := p.AddQueue("spotify:track:track-2"); err != nil {
+					return err
+				}
+				return p.Next()
+			},
+			want: spotify.Player{
+				Item:        spotify.Track{ID: "track-2"},
+				Device:      spotify.Device{ID: "device-1"},
+				RepeatState: spotify.RepeatOff,
+				IsPlaying:   true,
+			},
+		},
+		{
+			name: "previous in context",
+			actions: func(p player.Player) error {
+				if err := p.PlayContext("spotify:album:album-1", "spotify:track:track-2"); err != nil {
+					return err
+				}
+				return p.Previous()
+			},
+			want: spotify.Player{
+				Item:        spotify.Track{ID: "track-1"},
+				Device:      spotify.Device{ID: "device-1"},
+				RepeatState: spotify.RepeatOff,
+				IsPlaying:   true,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newServer(t)
+			p := player.New(srv.NewClient(), tc.device)
+
+			if err := tc.actions(p); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := srv.Player()
+			if got == nil {
+				t.Fatal("expected a player state")
+			}
+
+			if got.Item.ID != tc.want.Item.ID ||
+				got.Device.ID != tc.want.Device.ID ||
+				got.IsPlaying != tc.want.IsPlaying ||
+				got.ShuffleState != tc.want.ShuffleState ||
+				got.RepeatState != tc.want.RepeatState {
+				t.Fatalf("expected %+v, got %+v", tc.want, *got)
+			}
+		})
+	}
+}
+
+func TestPlayerWithoutPlayback(t *testing.T) {
+	tests := []struct {
+		name    string
+		action  func(p player.Player) error
+		wantErr bool
+	}{
+		{name: "toggle repeat is a no-op", action: player.Player.ToggleRepeat},
+		{name: "toggle shuffle is a no-op", action: player.Player.ToggleShuffle},
+		{name: "play pause fails", action: player.Player.PlayPause, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newServer(t)
+			srv.SetNoActiveDevice()
+
+			err := tc.action(player.New(srv.NewClient(), ""))
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("expected error: %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPlayerFaults(t *testing.T) {
+	srv := newServer(t)
+	srv.InjectFault(spotifytest.Fault{
+		Path:   "/me/player",
+		Status: http.StatusInternalServerError,
+	})
+
+	err := player.New(srv.NewClient(), "").ToggleShuffle()
+	if !spotify.IsStatusErr(err, http.StatusInternalServerError) {
+		t.Fatalf("expected a 500 error, got %v", err)
+	}
+}
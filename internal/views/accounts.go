@@ -0,0 +1,101 @@
+package views
+
+import (
+	"log"
+
+	"github.com/davidborzek/spofi/internal/app"
+	"github.com/davidborzek/spofi/internal/config"
+	"github.com/davidborzek/spofi/internal/format"
+	"github.com/davidborzek/spofi/pkg/rofi"
+)
+
+type accountsView struct {
+	rofi rofi.App
+	app  *app.App
+}
+
+// NewAccountsView creates a view to switch
+// to the account of another profile.
+func NewAccountsView(app *app.App, title string) View {
+	r := rofi.App{
+		Prompt:       title,
+		NoCustom:     true,
+		IgnoreCase:   true,
+		RenderMarkup: true,
+		ShowBack:     true,
+	}
+
+	view := &accountsView{
+		rofi: r,
+		app:  app,
+	}
+
+	return view
+}
+
+func (view *accountsView) getProfiles() []rofi.Row {
+	names := view.app.Config.ProfileNames()
+	rows := make([]rofi.Row, len(names))
+
+	for i, name := range names {
+		rows[i] = rofi.Row{
+			Title:  format.FormatIcon(view.app.Config.Icons.Account, format.Escape(name)),
+			Value:  name,
+			Active: name == view.app.Config.Profile(),
+		}
+	}
+
+	return rows
+}
+
+// switchProfile is an internal implementation to load the
+// config of a profile and to rebuild the application context
+// with the client of its account.
+func (view *accountsView) switchProfile(name string) Action {
+	if name == view.app.Config.Profile() {
+		return Back()
+	}
+
+	config.SetProfile(name)
+	cfg, err := config.LoadConfig()
+	if err != nil || cfg.Spotify.RefreshToken == "" {
+		config.SetProfile(view.app.Config.Profile())
+
+		if err != nil {
+			switchAccountError(err)
+		} else {
+			profileNotSetUpError(name)
+		}
+		return Stay()
+	}
+
+	// Let background refreshes of the library
+	// finish before the library is replaced.
+	view.app.Library.Wait()
+
+	a, err := Setup(cfg, setupOptions)
+	if err != nil {
+		config.SetProfile(view.app.Config.Profile())
+		switchAccountError(err)
+		return Stay()
+	}
+	*view.app = *a
+
+	return Restart(NewMainView(view.app))
+}
+
+func (view *accountsView) Show() Action {
+	view.rofi.Rows = view.getProfiles()
+
+	evt, err := view.rofi.Run()
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+
+	switch evt := evt.(type) {
+	case rofi.SelectedEvent:
+		return view.switchProfile(evt.Selection.Value)
+	}
+
+	return Back()
+}
@@ -0,0 +1,103 @@
+package views
+
+import (
+	"github.com/davidborzek/spofi/internal/app"
+	"github.com/davidborzek/spofi/internal/clipboard"
+	"github.com/davidborzek/spofi/internal/history"
+	"github.com/davidborzek/spofi/internal/keymap"
+	"github.com/davidborzek/spofi/pkg/spotify"
+)
+
+// playerAction is an internal implementation to handle the
+// player controls, which are available in all views. It
+// returns false if the action is not a player control.
+func playerAction(app *app.App, action string) bool {
+	switch action {
+	case keymap.ActionTogglePause:
+		if err := app.Player.PlayPause(); err != nil {
+			playPauseError(err)
+		}
+	case keymap.ActionNextTrack:
+		if err := app.Player.Next(); err != nil {
+			skipTrackError(err)
+		}
+	case keymap.ActionPreviousTrack:
+		if err := app.Player.Previous(); err != nil {
+			previousTrackError(err)
+		}
+	case keymap.ActionToggleRepeat:
+		if err := app.Player.ToggleRepeat(); err != nil {
+			updatePlayerError(err)
+		}
+	case keymap.ActionToggleShuffle:
+		if err := app.Player.ToggleShuffle(); err != nil {
+			updatePlayerError(err)
+		}
+	default:
+		return false
+	}
+
+	return true
+}
+
+// copyLink is an internal implementation to copy the
+// web player link of an item (by uri) to the clipboard.
+func copyLink(uri string) {
+	link := spotify.URIToURL(uri)
+	if link == "" {
+		return
+	}
+
+	if err := clipboard.Copy(link); err != nil {
+		copyLinkError(err)
+	}
+}
+
+// openAlbum is an internal implementation to
+// open the album of a track (by uri).
+func openAlbum(app *app.App, uri string) Action {
+	if history.KindOf(uri) != history.KindTrack {
+		return Stay()
+	}
+
+	track, err := app.Library.Track(spotify.URIToID(uri))
+	if err != nil {
+		getAlbumError(err)
+		return Stay()
+	}
+
+	if track.IsEpisode() || track.Album.ID == "" {
+		return Stay()
+	}
+
+	return Push(NewAlbumViewByID(app, track.Album.ID))
+}
+
+// openArtist is an internal implementation to open the
+// (first) artist of a track or an album (by uri).
+func openArtist(app *app.App, uri string) Action {
+	var artists []spotify.Artist
+
+	switch history.KindOf(uri) {
+	case history.KindTrack:
+		track, err := app.Library.Track(spotify.URIToID(uri))
+		if err != nil {
+			getArtistError(err)
+			return Stay()
+		}
+		artists = track.Artists
+	case history.KindAlbum:
+		album, err := app.Library.Album(spotify.URIToID(uri))
+		if err != nil {
+			getArtistError(err)
+			return Stay()
+		}
+		artists = album.Artists
+	}
+
+	if len(artists) == 0 {
+		return Stay()
+	}
+
+	return Push(NewArtistView(app, artists[0]))
+}
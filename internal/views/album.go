@@ -5,6 +5,7 @@ import (
 
 	"github.com/davidborzek/spofi/internal/app"
 	"github.com/davidborzek/spofi/internal/format"
+	"github.com/davidborzek/spofi/internal/keymap"
 	"github.com/davidborzek/spofi/pkg/rofi"
 	"github.com/davidborzek/spofi/pkg/spotify"
 )
@@ -12,60 +13,49 @@ import (
 type albumView struct {
 	rofi rofi.App
 	app  *app.App
+	keys keymap.Keymap
 
+	id    string
 	album *spotify.AlbumWithTracks
+}
 
-	parent View
+// NewAlbumView creates a view for the tracks of an album.
+func NewAlbumView(app *app.App, album spotify.AlbumWithTracks) View {
+	view := newAlbumView(app)
+	view.album = &album
+	return view
 }
 
-func NewAlbumView(app *app.App) View {
-	var msg = ""
-	if app.Config.ShowKeybindings {
-		msg = format.FormatKeybindings(
-			format.Keybinding{
-				Key:         app.Config.Keybindings.PlayAlbum,
-				Description: "Play album",
-			},
-			format.Keybinding{
-				Key:         app.Config.Keybindings.AddToQueue,
-				Description: "Add to queue",
-			},
-			format.Keybinding{
-				Key:         app.Config.Keybindings.PlayTrack,
-				Description: "Play track",
-			},
-		)
-	}
+// NewAlbumViewByID creates a view for the tracks of an
+// album which is fetched when the view is shown.
+func NewAlbumViewByID(app *app.App, id string) View {
+	view := newAlbumView(app)
+	view.id = id
+	return view
+}
+
+func newAlbumView(app *app.App) *albumView {
+	keys := newKeymap(app.Config.Keybindings, albumKeymapID)
 
 	r := rofi.App{
-		Keybindings: []string{
-			app.Config.Keybindings.PlayAlbum,
-			app.Config.Keybindings.AddToQueue,
-			app.Config.Keybindings.PlayTrack,
-		},
-		NoCustom:   true,
-		IgnoreCase: true,
-		ShowBack:   true,
-		Message:    msg,
+		Keybindings:  keys.Keys(),
+		NoCustom:     true,
+		IgnoreCase:   true,
+		RenderMarkup: true,
+		ShowBack:     true,
+		MultiSelect:  true,
+		Message:      keybindingsMessage(app, keys),
 	}
 
 	view := &albumView{
 		rofi: r,
 		app:  app,
+		keys: keys,
 	}
 
 	return view
 }
 
-func (view *albumView) addToQueue(uri string) {
-	err := view.app.Player.AddQueue(uri)
-	if err != nil {
-		addQueueError(err)
-	}
-
-	view.Show()
-}
-
 func (view *albumView) playTrack(uri string) {
 	err := view.app.Player.PlayTrack(uri)
 	if err != nil {
@@ -86,7 +76,7 @@ func (view *albumView) playAlbum(uri ...string) {
 }
 
 func (view *albumView) setPrompt() {
-	view.rofi.Prompt = format.FormatTitle(view.album.Name, view.album.Artists[0].Name)
+	view.rofi.Prompt = format.FormatTitle(view.album.Name, format.FirstArtist(view.album.Artists))
 }
 
 func (view *albumView) setRows() {
@@ -96,23 +86,14 @@ func (view *albumView) setRows() {
 	)
 }
 
-func (view *albumView) Show(payload ...interface{}) {
-	if len(payload) > 0 {
-		switch t := payload[0].(type) {
-		case spotify.AlbumWithTracks:
-			view.album = &t
-		case string:
-			res, err := view.app.SpotifyClient.GetAlbum(t)
-			if err != nil {
-				getAlbumError(err)
-				return
-			}
-			view.album = res
-		}
-	}
-
+func (view *albumView) Show() Action {
 	if view.album == nil {
-		return
+		res, err := view.app.Library.Album(view.id)
+		if err != nil {
+			getAlbumError(err)
+			return Back()
+		}
+		view.album = res
 	}
 
 	view.setPrompt()
@@ -125,21 +106,40 @@ func (view *albumView) Show(payload ...interface{}) {
 
 	switch evt := evt.(type) {
 	case rofi.BackEvent, rofi.CancelledEvent:
-		view.parent.Show()
+		return Back()
 	case rofi.KeyEvent:
-		switch evt.Key {
-		case view.app.Config.Keybindings.PlayAlbum:
+		action := view.keys.Action(evt.Key)
+		if playerAction(view.app, action) {
+			return Stay()
+		}
+
+		switch action {
+		case keymap.ActionPlayAlbum:
 			view.playAlbum()
-		case view.app.Config.Keybindings.AddToQueue:
-			view.addToQueue(evt.Selection.Value)
-		case view.app.Config.Keybindings.PlayTrack:
+			return Exit()
+		case keymap.ActionQueue:
+			queueTracks(view.app, evt.Selections)
+		case keymap.ActionPlayTrack:
 			view.playTrack(evt.Selection.Value)
+			return Exit()
+		case keymap.ActionLike:
+			likeTracks(view.app, evt.Selections)
+		case keymap.ActionAddToPlaylist:
+			addTracksToPlaylist(view.app, evt.Selections)
+		case keymap.ActionPin:
+			togglePin(view.app, view.album.URI)
+		case keymap.ActionOpenArtist:
+			return openArtist(view.app, view.album.URI)
+		case keymap.ActionCopyLink:
+			copyLink(evt.Selection.Value)
 		}
+
+		return Stay()
+	case rofi.MultiSelectedEvent:
+		playTracks(view.app, evt.Selections)
 	case rofi.SelectedEvent:
 		view.playAlbum(evt.Selection.Value)
 	}
-}
 
-func (view *albumView) SetParent(parent View) {
-	view.parent = parent
+	return Exit()
 }
@@ -0,0 +1,98 @@
+package views
+
+import (
+	"log"
+
+	"github.com/davidborzek/spofi/internal/app"
+	"github.com/davidborzek/spofi/internal/format"
+	"github.com/davidborzek/spofi/internal/keymap"
+	"github.com/davidborzek/spofi/pkg/rofi"
+	"github.com/davidborzek/spofi/pkg/spotify"
+)
+
+type artistView struct {
+	rofi rofi.App
+	app  *app.App
+	keys keymap.Keymap
+
+	artist spotify.Artist
+}
+
+// NewArtistView creates a view for the albums and singles of an artist.
+func NewArtistView(app *app.App, artist spotify.Artist) View {
+	keys := newKeymap(app.Config.Keybindings, artistKeymapID)
+
+	r := rofi.App{
+		Prompt:       format.FormatIcon(app.Config.Icons.Artist, artist.Name),
+		Keybindings:  keys.Keys(),
+		ShowBack:     true,
+		NoCustom:     true,
+		IgnoreCase:   true,
+		RenderMarkup: true,
+		Message:      keybindingsMessage(app, keys),
+	}
+
+	view := &artistView{
+		rofi:   r,
+		app:    app,
+		keys:   keys,
+		artist: artist,
+	}
+
+	return view
+}
+
+func (view *artistView) getAlbums() ([]rofi.Row, error) {
+	result, err := view.app.SpotifyClient.GetArtistAlbums(view.artist.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := format.FormatAlbumRows(
+		result.Items,
+		view.app.Config.Icons.Album,
+	)
+	setAlbumArtwork(view.app, rows, result.Items)
+	return rows, nil
+}
+
+func (view *artistView) Show() Action {
+	rows, err := view.getAlbums()
+	if err != nil {
+		getArtistError(err)
+		return Back()
+	}
+
+	view.rofi.Rows = rows
+
+	evt, err := view.rofi.Run()
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+
+	switch evt := evt.(type) {
+	case rofi.KeyEvent:
+		action := view.keys.Action(evt.Key)
+		if playerAction(view.app, action) {
+			return Stay()
+		}
+
+		switch action {
+		case keymap.ActionPlayAlbum:
+			if err := view.app.Player.PlayContext(evt.Selection.Value); err != nil {
+				playAlbumError(err)
+			}
+			return Exit()
+		case keymap.ActionPin:
+			togglePin(view.app, view.artist.URI)
+		case keymap.ActionCopyLink:
+			copyLink(evt.Selection.Value)
+		}
+
+		return Stay()
+	case rofi.SelectedEvent:
+		return Push(NewAlbumViewByID(view.app, spotify.URIToID(evt.Selection.Value)))
+	}
+
+	return Back()
+}
@@ -0,0 +1,49 @@
+package views
+
+import (
+	"github.com/davidborzek/spofi/internal/app"
+	"github.com/davidborzek/spofi/pkg/rofi"
+	"github.com/davidborzek/spofi/pkg/spotify"
+)
+
+// setArtwork sets the icons of the rows to the cached
+// images when artwork is enabled.
+func setArtwork(app *app.App, rows []rofi.Row, images [][]spotify.Image) {
+	if app.Artwork == nil {
+		return
+	}
+
+	for i, path := range app.Artwork.Paths(images) {
+		rows[i].Icon = path
+	}
+}
+
+// setTrackArtwork sets the icons of the track rows
+// to the album covers of the tracks.
+func setTrackArtwork(app *app.App, rows []rofi.Row, tracks []spotify.Track) {
+	images := make([][]spotify.Image, len(tracks))
+	for i, track := range tracks {
+		images[i] = track.Album.Images
+	}
+	setArtwork(app, rows, images)
+}
+
+// setAlbumArtwork sets the icons of the album rows
+// to the album covers.
+func setAlbumArtwork(app *app.App, rows []rofi.Row, albums []spotify.Album) {
+	images := make([][]spotify.Image, len(albums))
+	for i, album := range albums {
+		images[i] = album.Images
+	}
+	setArtwork(app, rows, images)
+}
+
+// setPlaylistArtwork sets the icons of the playlist rows
+// to the playlist covers.
+func setPlaylistArtwork(app *app.App, rows []rofi.Row, playlists []spotify.Playlist) {
+	images := make([][]spotify.Image, len(playlists))
+	for i, playlist := range playlists {
+		images[i] = playlist.Images
+	}
+	setArtwork(app, rows, images)
+}
@@ -6,22 +6,25 @@ import (
 
 	"github.com/davidborzek/spofi/internal/app"
 	"github.com/davidborzek/spofi/internal/config"
+	"github.com/davidborzek/spofi/internal/format"
 	"github.com/davidborzek/spofi/pkg/rofi"
+	"github.com/davidborzek/spofi/pkg/spotify"
 )
 
 type devicesView struct {
 	rofi rofi.App
 	app  *app.App
 
-	parent View
+	devices []spotify.Device
 }
 
 func NewDevicesView(app *app.App, title string) View {
 	r := rofi.App{
-		Prompt:     title,
-		NoCustom:   true,
-		IgnoreCase: true,
-		ShowBack:   true,
+		Prompt:       title,
+		NoCustom:     true,
+		IgnoreCase:   true,
+		RenderMarkup: true,
+		ShowBack:     true,
 	}
 
 	view := &devicesView{
@@ -38,24 +41,37 @@ func (view *devicesView) getDevices() ([]rofi.Row, error) {
 		return nil, err
 	}
 
+	view.devices = result.Devices
 	rows := make([]rofi.Row, len(result.Devices))
 
 	for i, device := range result.Devices {
 		rows[i] = rofi.Row{
-			Title: device.Name,
-			Value: device.ID,
+			Title:  format.Escape(device.Name),
+			Value:  device.ID,
+			Active: device.ID == view.app.Config.Device.ID,
 		}
 	}
 
 	return rows, nil
 }
 
+// device is an internal implementation to get
+// a loaded device by the id of its row.
+func (view *devicesView) device(id string) (spotify.Device, bool) {
+	for _, d := range view.devices {
+		if d.ID == id {
+			return d, true
+		}
+	}
+	return spotify.Device{}, false
+}
+
 func (view *devicesView) getCurrentDevice() string {
 	var msg string
 	if view.app.Config.Device.Name != "" {
 		msg = fmt.Sprintf(
 			"Current device: %s",
-			view.app.Config.Device.Name,
+			format.Escape(view.app.Config.Device.Name),
 		)
 	} else {
 		msg = "No device selected"
@@ -63,17 +79,16 @@ func (view *devicesView) getCurrentDevice() string {
 	return msg
 }
 
-func (view *devicesView) Show(payload ...interface{}) {
+func (view *devicesView) Show() Action {
 	rows, err := view.getDevices()
 	if err != nil {
 		getDevicesError(err)
-		return
+		return Back()
 	}
 
 	if len(rows) == 0 {
 		noDevicesFoundError()
-		view.parent.Show()
-		return
+		return Back()
 	}
 
 	msg := view.getCurrentDevice()
@@ -87,24 +102,24 @@ func (view *devicesView) Show(payload ...interface{}) {
 	}
 
 	switch evt := evt.(type) {
-	case rofi.BackEvent, rofi.CancelledEvent:
-		view.parent.Show()
 	case rofi.SelectedEvent:
-		view.app.Config.Device = config.SpotifyDevice{
-			ID:   evt.Selection.Value,
-			Name: evt.Selection.Title,
+		device, ok := view.device(evt.Selection.Value)
+		if !ok {
+			return Stay()
 		}
 
-		if err := view.app.Config.Write(); err != nil {
+		err := view.app.Config.SelectDevice(config.SpotifyDevice{
+			ID:   device.ID,
+			Name: device.Name,
+		})
+		if err != nil {
 			selectDeviceError(err)
-			return
+			return Exit()
 		}
 
-		view.app.Player.SetDevice(evt.Selection.Value)
-		view.Show()
+		view.app.Player.SetDevice(device.ID)
+		return Stay()
 	}
-}
 
-func (view *devicesView) SetParent(parent View) {
-	view.parent = parent
+	return Back()
 }
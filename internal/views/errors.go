@@ -1,6 +1,7 @@
 package views
 
 import (
+	"fmt"
 	"log"
 
 	"github.com/davidborzek/spofi/pkg/rofi"
@@ -89,3 +90,58 @@ func searchError(err error) {
 	rofi.Error("Failed to search. Try again.")
 	log.Println(err)
 }
+
+func likeTracksError(err error) {
+	rofi.Error("Failed to like the tracks. Try again.")
+	log.Println(err)
+}
+
+func getPlaylistsError(err error) {
+	rofi.Error("Failed to get playlists. Try again.")
+	log.Println(err)
+}
+
+func addToPlaylistError(err error) {
+	rofi.Error("Failed to add the tracks to the playlist. Try again.")
+	log.Println(err)
+}
+
+func playContextError(err error) {
+	rofi.Error("Failed to play. Try again.")
+	log.Println(err)
+}
+
+func emptyHistoryError() {
+	rofi.Error("Nothing was played yet.")
+}
+
+func pinError(err error) {
+	rofi.Error("Failed to pin the item. Try again.")
+	log.Println(err)
+}
+
+func keybindingsError(errs []error) {
+	rofi.Error("Some keybindings conflict. Run 'spofi config check' for details.")
+	for _, err := range errs {
+		log.Println(err)
+	}
+}
+
+func copyLinkError(err error) {
+	rofi.Error("Failed to copy the link.")
+	log.Println(err)
+}
+
+func getArtistError(err error) {
+	rofi.Error("Failed to get the artist. Try again.")
+	log.Println(err)
+}
+
+func switchAccountError(err error) {
+	rofi.Error("Failed to switch the account. Try again.")
+	log.Println(err)
+}
+
+func profileNotSetUpError(name string) {
+	rofi.Error(fmt.Sprintf("The account of %s is not set up. Run 'spofi setup --profile %s'.", name, name))
+}
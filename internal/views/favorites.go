@@ -0,0 +1,82 @@
+package views
+
+import (
+	"github.com/davidborzek/spofi/internal/app"
+	"github.com/davidborzek/spofi/internal/config"
+	"github.com/davidborzek/spofi/internal/format"
+	"github.com/davidborzek/spofi/internal/history"
+	"github.com/davidborzek/spofi/pkg/rofi"
+	"github.com/davidborzek/spofi/pkg/spotify"
+)
+
+// togglePin is an internal implementation to pin an item
+// to the main menu or to remove the pin when it is pinned.
+func togglePin(app *app.App, uri string) {
+	// The back option and custom input cannot be pinned.
+	if uri == "" {
+		return
+	}
+
+	favorite := config.Favorite{URI: uri}
+	if !app.Config.IsFavorite(uri) {
+		item, err := app.Describe(uri)
+		if err != nil {
+			pinError(err)
+			return
+		}
+		favorite.Name = item.Name
+	}
+
+	app.Config.ToggleFavorite(favorite)
+	if err := app.Config.Write(); err != nil {
+		pinError(err)
+	}
+}
+
+// kindIcon is an internal implementation to get
+// the configured icon of a kind of item.
+func kindIcon(app *app.App, kind history.Kind) string {
+	switch kind {
+	case history.KindAlbum:
+		return app.Config.Icons.Album
+	case history.KindPlaylist:
+		return app.Config.Icons.Playlist
+	case history.KindArtist:
+		return app.Config.Icons.Artist
+	}
+	return app.Config.Icons.Track
+}
+
+// favoriteRows is an internal implementation to
+// build the rows of the pinned items.
+func favoriteRows(app *app.App) []rofi.Row {
+	rows := make([]rofi.Row, len(app.Config.Favorites))
+	for i, f := range app.Config.Favorites {
+		kind := history.KindOf(f.URI)
+		rows[i] = rofi.Row{
+			Title: format.FormatIcon(kindIcon(app, kind), format.Escape(f.Name)),
+			Value: f.URI,
+			Meta:  string(kind),
+		}
+	}
+	return rows
+}
+
+// openFavorite is an internal implementation to open a
+// pinned album or to play any other pinned item.
+func openFavorite(app *app.App, uri string) Action {
+	switch history.KindOf(uri) {
+	case history.KindAlbum:
+		return Push(NewAlbumViewByID(app, spotify.URIToID(uri)))
+	case history.KindTrack:
+		if err := app.Player.PlayTrack(uri); err != nil {
+			playTrackError(err)
+		}
+	default:
+		if err := app.Player.PlayContext(uri); err != nil {
+			playContextError(err)
+		}
+	}
+
+	return Exit()
+}
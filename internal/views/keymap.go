@@ -0,0 +1,173 @@
+package views
+
+import (
+	"github.com/davidborzek/spofi/internal/app"
+	"github.com/davidborzek/spofi/internal/config"
+	"github.com/davidborzek/spofi/internal/format"
+	"github.com/davidborzek/spofi/internal/keymap"
+	"github.com/davidborzek/spofi/pkg/rofi"
+)
+
+// The names of the views with keybindings, which are not
+// already named by a main menu view id. The names scope
+// keybindings to a view in the config (e.g. "album.play-album").
+const (
+	mainKeymapID         = "main"
+	albumKeymapID        = "album"
+	artistKeymapID       = "artist"
+	playlistKeymapID     = "playlist"
+	searchTracksKeymapID = "searchTracks"
+	searchAlbumsKeymapID = "searchAlbums"
+)
+
+// viewActions are the actions of the views with keybindings.
+// The player controls are available in all of them.
+var viewActions = map[string][]string{
+	mainKeymapID: {
+		keymap.ActionPin,
+	},
+	albumKeymapID: {
+		keymap.ActionPlayAlbum,
+		keymap.ActionQueue,
+		keymap.ActionPlayTrack,
+		keymap.ActionLike,
+		keymap.ActionAddToPlaylist,
+		keymap.ActionPin,
+		keymap.ActionOpenArtist,
+		keymap.ActionCopyLink,
+	},
+	artistKeymapID: {
+		keymap.ActionPlayAlbum,
+		keymap.ActionPin,
+		keymap.ActionCopyLink,
+	},
+	playlistsViewID: {
+		keymap.ActionPlayAlbum,
+		keymap.ActionPin,
+		keymap.ActionCopyLink,
+	},
+	playlistKeymapID: {
+		keymap.ActionPlayAlbum,
+		keymap.ActionQueue,
+		keymap.ActionLike,
+		keymap.ActionAddToPlaylist,
+		keymap.ActionPin,
+		keymap.ActionOpenAlbum,
+		keymap.ActionOpenArtist,
+		keymap.ActionCopyLink,
+	},
+	likedTracksViewID: {
+		keymap.ActionNextPage,
+		keymap.ActionPreviousPage,
+		keymap.ActionQueue,
+		keymap.ActionAddToPlaylist,
+		keymap.ActionShowAll,
+		keymap.ActionPin,
+		keymap.ActionOpenAlbum,
+		keymap.ActionOpenArtist,
+		keymap.ActionCopyLink,
+	},
+	recentlyPlayedViewID: {
+		keymap.ActionQueue,
+		keymap.ActionLike,
+		keymap.ActionAddToPlaylist,
+		keymap.ActionPin,
+		keymap.ActionOpenAlbum,
+		keymap.ActionOpenArtist,
+		keymap.ActionCopyLink,
+	},
+	savedAlbumsViewID: {
+		keymap.ActionNextPage,
+		keymap.ActionPreviousPage,
+		keymap.ActionPlayAlbum,
+		keymap.ActionPin,
+		keymap.ActionOpenArtist,
+		keymap.ActionCopyLink,
+	},
+	searchTracksKeymapID: {
+		keymap.ActionQueue,
+		keymap.ActionToggleSearchType,
+		keymap.ActionLike,
+		keymap.ActionAddToPlaylist,
+		keymap.ActionPin,
+		keymap.ActionOpenAlbum,
+		keymap.ActionOpenArtist,
+		keymap.ActionCopyLink,
+	},
+	searchAlbumsKeymapID: {
+		keymap.ActionToggleSearchType,
+		keymap.ActionPlayAlbum,
+		keymap.ActionPin,
+		keymap.ActionOpenArtist,
+		keymap.ActionCopyLink,
+	},
+}
+
+// newKeymap is an internal implementation to create the
+// keymap of a view from the configured keybindings.
+func newKeymap(keybindings config.Keybindings, view string) keymap.Keymap {
+	actions := append(append([]string{}, viewActions[view]...), keymap.PlayerActions...)
+	return keymap.New(view, keybindings, actions...)
+}
+
+// keybindingsMessage is an internal implementation to build the
+// message which shows the bound keys of a view, if enabled.
+func keybindingsMessage(app *app.App, keys keymap.Keymap) string {
+	if !app.Config.ShowKeybindings {
+		return ""
+	}
+
+	bound := keys.Bound()
+	keybindings := make([]format.Keybinding, len(bound))
+	for i, action := range bound {
+		keybindings[i] = format.Keybinding{
+			Key:         keys.Key(action),
+			Description: keymap.Describe(action),
+		}
+	}
+
+	return format.FormatKeybindings(keybindings...)
+}
+
+// Keymaps returns the keymaps of all views
+// with keybindings, e.g. to validate them.
+func Keymaps(keybindings config.Keybindings) []keymap.Keymap {
+	views := []string{
+		mainKeymapID,
+		albumKeymapID,
+		artistKeymapID,
+		playlistsViewID,
+		playlistKeymapID,
+		likedTracksViewID,
+		recentlyPlayedViewID,
+		savedAlbumsViewID,
+		searchTracksKeymapID,
+		searchAlbumsKeymapID,
+	}
+
+	keymaps := make([]keymap.Keymap, len(views))
+	for i, view := range views {
+		keymaps[i] = newKeymap(keybindings, view)
+	}
+	return keymaps
+}
+
+// ValidateKeybindings validates the keybindings of all views.
+// The builtin keybindings of rofi are only checked, when rofi
+// is the menu backend.
+func ValidateKeybindings(keybindings config.Keybindings, backend string) []error {
+	var builtins map[string]string
+	if backend == "" || backend == rofi.BackendRofi {
+		builtins = keymap.RofiBuiltins
+	}
+
+	return keymap.Validate(Keymaps(keybindings), builtins, keybindings)
+}
+
+// CheckKeybindings shows an error for conflicting
+// keybindings of the views, e.g. on start.
+func CheckKeybindings(keybindings config.Keybindings, backend string) {
+	if errs := ValidateKeybindings(keybindings, backend); len(errs) > 0 {
+		keybindingsError(errs)
+	}
+}
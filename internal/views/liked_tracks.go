@@ -3,9 +3,12 @@ package views
 import (
 	"fmt"
 	"log"
+	"strings"
+	"sync"
 
 	"github.com/davidborzek/spofi/internal/app"
 	"github.com/davidborzek/spofi/internal/format"
+	"github.com/davidborzek/spofi/internal/keymap"
 	"github.com/davidborzek/spofi/pkg/rofi"
 	"github.com/davidborzek/spofi/pkg/spotify"
 )
@@ -17,95 +20,182 @@ const (
 type likedTracksView struct {
 	rofi rofi.App
 	app  *app.App
+	keys keymap.Keymap
 
-	parent View
-
+	message    string
 	title      string
 	page       int
 	totalPages int
+
+	// showAll shows all liked tracks in a single list,
+	// so the search is not limited to the current page.
+	showAll bool
+	// load loads all liked tracks, when all are shown.
+	// It is kept until it finished, so showing the menu
+	// again does not start another load.
+	load *likedTracksLoad
 }
 
 func NewLikedTracksView(app *app.App, title string) View {
-	var msg = ""
-	if app.Config.ShowKeybindings {
-		msg = format.FormatKeybindings(
-			format.Keybinding{
-				Key:         app.Config.Keybindings.NextPage,
-				Description: "Next page",
-			},
-			format.Keybinding{
-				Key:         app.Config.Keybindings.PreviousPage,
-				Description: "Previous page",
-			},
-			format.Keybinding{
-				Key:         app.Config.Keybindings.AddToQueue,
-				Description: "Add to queue",
-			},
-		)
-	}
+	keys := newKeymap(app.Config.Keybindings, likedTracksViewID)
+	msg := keybindingsMessage(app, keys)
 
 	r := rofi.App{
-		Prompt: title,
-		Keybindings: []string{
-			app.Config.Keybindings.NextPage,
-			app.Config.Keybindings.PreviousPage,
-			app.Config.Keybindings.AddToQueue,
-		},
-		ShowBack:   true,
-		NoCustom:   true,
-		IgnoreCase: true,
-		Message:    msg,
+		Prompt:       title,
+		Keybindings:  keys.Keys(),
+		ShowBack:     true,
+		NoCustom:     true,
+		IgnoreCase:   true,
+		RenderMarkup: true,
+		MultiSelect:  true,
+		Message:      msg,
 	}
 
 	view := &likedTracksView{
-		rofi:  r,
-		app:   app,
-		page:  1,
-		title: title,
+		rofi:    r,
+		app:     app,
+		keys:    keys,
+		message: msg,
+		page:    1,
+		title:   title,
 	}
 
 	return view
 }
 
 func (view *likedTracksView) getTracks() ([]rofi.Row, error) {
-	currentOffset := (view.page - 1) * likeTracksViewLimit
-
-	result, err := view.app.SpotifyClient.GetLikedTracks(likeTracksViewLimit, currentOffset)
+	saved, err := view.app.Library.LikedTracks()
 	if err != nil {
 		return nil, err
 	}
 
-	view.totalPages = result.Total / likeTracksViewLimit
+	// The library may have changed since the last page was shown.
+	view.totalPages = totalPages(len(saved), likeTracksViewLimit)
+	if view.page > view.totalPages {
+		view.page = view.totalPages
+	}
+
+	start, end := pageBounds(view.page, likeTracksViewLimit, len(saved))
 
-	tracks := make([]spotify.Track, len(result.Items))
-	for i, item := range result.Items {
-		tracks[i] = item.Track
+	tracks := make([]spotify.Track, 0, end-start)
+	for _, item := range saved[start:end] {
+		tracks = append(tracks, item.Track)
 	}
 
 	rows := format.FormatTrackRows(
 		tracks,
 		view.app.Config.Icons.Track,
 	)
+	setTrackArtwork(view.app, rows, tracks)
 	return rows, nil
 }
 
-func (view *likedTracksView) addToQueue(uri string) {
-	err := view.app.Player.AddQueue(uri)
+// likedTracksLoad is an internal implementation to load all
+// liked tracks once, so the menu can be shown again (e.g. after
+// a track was queued) while the tracks are still loaded.
+type likedTracksLoad struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	rows []rofi.Row
+	done bool
+	err  error
+}
 
-	if err != nil {
-		addQueueError(err)
-	}
+// loadAllTracks is an internal implementation to start loading
+// all liked tracks in the background. Artwork is not shown, as
+// it would be downloaded for the whole library.
+func (view *likedTracksView) loadAllTracks() *likedTracksLoad {
+	load := &likedTracksLoad{}
+	load.cond = sync.NewCond(&load.mu)
+
+	go func() {
+		err := view.app.Library.StreamLikedTracks(func(saved []spotify.SavedTrack) {
+			tracks := make([]spotify.Track, len(saved))
+			for i, item := range saved {
+				tracks[i] = item.Track
+			}
+
+			rows := format.FormatTrackRows(tracks, view.app.Config.Icons.Track)
+
+			load.mu.Lock()
+			load.rows = append(load.rows, rows...)
+			load.mu.Unlock()
+			load.cond.Broadcast()
+		})
+
+		load.mu.Lock()
+		load.done = true
+		load.err = err
+		load.mu.Unlock()
+		load.cond.Broadcast()
+	}()
+
+	return load
 }
 
-func (view *likedTracksView) Show(payload ...interface{}) {
-	rows, err := view.getTracks()
-	if err != nil {
-		getTracksError(err)
-		return
+// finished reports whether the load finished
+// and the error, when the loading failed.
+func (load *likedTracksLoad) finished() (bool, error) {
+	load.mu.Lock()
+	defer load.mu.Unlock()
+
+	return load.done, load.err
+}
+
+// stream streams the tracks which were loaded so far
+// and the tracks which are loaded afterwards into the menu.
+func (load *likedTracksLoad) stream(add func(rows ...rofi.Row)) {
+	for offset := 0; ; {
+		load.mu.Lock()
+		for len(load.rows) == offset && !load.done {
+			load.cond.Wait()
+		}
+		loaded, done := load.rows[offset:], load.done
+		load.mu.Unlock()
+
+		if len(loaded) > 0 {
+			add(loaded...)
+			offset += len(loaded)
+		}
+
+		if done && len(loaded) == 0 {
+			return
+		}
 	}
+}
 
-	view.rofi.Prompt = fmt.Sprintf("%s %d/%d", view.title, view.page, view.totalPages)
-	view.rofi.Rows = rows
+func (view *likedTracksView) Show() Action {
+	if view.showAll {
+		view.rofi.Message = view.message
+		if !view.app.Library.HasLikedTracks() {
+			view.rofi.Message = strings.TrimSpace(
+				"Loading liked tracks, the list is updated while loading...\n" + view.message,
+			)
+		}
+
+		view.rofi.Prompt = fmt.Sprintf("%s (all)", view.title)
+		// A load which is still running is shown again instead
+		// of starting another one, a finished load is refreshed.
+		if view.load == nil {
+			view.load = view.loadAllTracks()
+		} else if done, _ := view.load.finished(); done {
+			view.load = view.loadAllTracks()
+		}
+
+		view.rofi.Rows = nil
+		view.rofi.Load = view.load.stream
+	} else {
+		rows, err := view.getTracks()
+		if err != nil {
+			getTracksError(err)
+			return Back()
+		}
+
+		view.rofi.Message = view.message
+		view.rofi.Prompt = fmt.Sprintf("%s %d/%d", view.title, view.page, view.totalPages)
+		view.rofi.Rows = rows
+		view.rofi.Load = nil
+	}
 
 	evt, err := view.rofi.Run()
 	if err != nil {
@@ -114,31 +204,54 @@ func (view *likedTracksView) Show(payload ...interface{}) {
 
 	switch evt := evt.(type) {
 	case rofi.BackEvent, rofi.CancelledEvent:
-		view.page = 1
-		view.parent.Show()
+		// Loading errors are shown when the menu is left, as
+		// the tracks which were loaded so far can still be used.
+		if view.showAll {
+			if _, err := view.load.finished(); err != nil {
+				getTracksError(err)
+			}
+		}
+		return Back()
 	case rofi.KeyEvent:
-		switch evt.Key {
-		case view.app.Config.Keybindings.NextPage:
-			if view.page < view.totalPages {
+		action := view.keys.Action(evt.Key)
+		if playerAction(view.app, action) {
+			return Stay()
+		}
+
+		switch action {
+		case keymap.ActionNextPage:
+			if !view.showAll && view.page < view.totalPages {
 				view.page += 1
 			}
-		case view.app.Config.Keybindings.PreviousPage:
-			if view.page > 1 {
+		case keymap.ActionPreviousPage:
+			if !view.showAll && view.page > 1 {
 				view.page -= 1
 			}
-		case view.app.Config.Keybindings.AddToQueue:
-			view.addToQueue(evt.Selection.Value)
+		case keymap.ActionQueue:
+			queueTracks(view.app, evt.Selections)
+		case keymap.ActionAddToPlaylist:
+			addTracksToPlaylist(view.app, evt.Selections)
+		case keymap.ActionPin:
+			togglePin(view.app, evt.Selection.Value)
+		case keymap.ActionShowAll:
+			view.showAll = !view.showAll
+		case keymap.ActionOpenAlbum:
+			return openAlbum(view.app, evt.Selection.Value)
+		case keymap.ActionOpenArtist:
+			return openArtist(view.app, evt.Selection.Value)
+		case keymap.ActionCopyLink:
+			copyLink(evt.Selection.Value)
 		}
 
-		view.Show()
+		return Stay()
+	case rofi.MultiSelectedEvent:
+		playTracks(view.app, evt.Selections)
 	case rofi.SelectedEvent:
 		err := view.app.Player.PlayTrack(evt.Selection.Value)
 		if err != nil {
 			playTrackError(err)
 		}
 	}
-}
 
-func (view *likedTracksView) SetParent(parent View) {
-	view.parent = parent
+	return Exit()
 }
@@ -3,174 +3,230 @@ package views
 import (
 	"fmt"
 	"log"
-	"os"
 
 	"github.com/davidborzek/spofi/internal/app"
+	"github.com/davidborzek/spofi/internal/config"
 	"github.com/davidborzek/spofi/internal/format"
+	"github.com/davidborzek/spofi/internal/history"
+	"github.com/davidborzek/spofi/internal/keymap"
 	"github.com/davidborzek/spofi/pkg/rofi"
 	"github.com/davidborzek/spofi/pkg/spotify"
 )
 
+// The names of the views which can be shown in the main
+// menu (menu.entries[].view) or opened with --view.
 const (
-	devicesViewID        = "devices_view"
-	playerViewID         = "player_view"
-	likedTracksViewID    = "liked_tracks_view"
-	queueViewID          = "queue_view"
-	recentlyPlayedViewID = "recently_played_view"
-	savedAlbumsViewID    = "saved_albums_view"
-	searchViewID         = "search_view"
+	accountsViewID       = "accounts"
+	devicesViewID        = "devices"
+	playerViewID         = "player"
+	playlistsViewID      = "playlists"
+	likedTracksViewID    = "likedTracks"
+	queueViewID          = "queue"
+	quickViewID          = "quick"
+	recentlyPlayedViewID = "recentlyPlayed"
+	savedAlbumsViewID    = "albums"
+	searchViewID         = "search"
 )
 
+// mainViewEntry is an internal implementation to
+// describe a view which can be opened from the main menu.
+type mainViewEntry struct {
+	title  string
+	icon   func(icons config.IconConfig) string
+	create func(app *app.App, title string) View
+}
+
+var mainViews = map[string]mainViewEntry{
+	playerViewID: {
+		title:  "Player",
+		icon:   func(icons config.IconConfig) string { return icons.Player },
+		create: NewPlayerView,
+	},
+	quickViewID: {
+		title:  "Quick",
+		icon:   func(icons config.IconConfig) string { return icons.Quick },
+		create: NewQuickView,
+	},
+	searchViewID: {
+		title:  "Search",
+		icon:   func(icons config.IconConfig) string { return icons.Search },
+		create: NewSearchView,
+	},
+	likedTracksViewID: {
+		title:  "Liked Tracks",
+		icon:   func(icons config.IconConfig) string { return icons.LikedTracks },
+		create: NewLikedTracksView,
+	},
+	savedAlbumsViewID: {
+		title:  "Albums",
+		icon:   func(icons config.IconConfig) string { return icons.Album },
+		create: NewSavedAlbumsView,
+	},
+	playlistsViewID: {
+		title:  "Playlists",
+		icon:   func(icons config.IconConfig) string { return icons.Playlist },
+		create: NewPlaylistsView,
+	},
+	queueViewID: {
+		title:  "Queue",
+		icon:   func(icons config.IconConfig) string { return icons.Queue },
+		create: NewQueueView,
+	},
+	recentlyPlayedViewID: {
+		title:  "Recently Played",
+		icon:   func(icons config.IconConfig) string { return icons.RecentlyPlayed },
+		create: NewRecentlyPlayedView,
+	},
+	devicesViewID: {
+		title:  "Devices",
+		icon:   func(icons config.IconConfig) string { return icons.Device },
+		create: NewDevicesView,
+	},
+	accountsViewID: {
+		title:  "Switch Account",
+		icon:   func(icons config.IconConfig) string { return icons.Account },
+		create: NewAccountsView,
+	},
+}
+
+// defaultMenuEntries are the entries of the main menu
+// when no layout is configured. The accounts entry is
+// added when there are profiles.
+var defaultMenuEntries = []config.MenuEntry{
+	{View: playerViewID},
+	{View: quickViewID},
+	{View: searchViewID},
+	{View: likedTracksViewID},
+	{View: savedAlbumsViewID},
+	{View: playlistsViewID},
+	{View: queueViewID},
+	{View: recentlyPlayedViewID},
+	{View: devicesViewID},
+}
+
+// NewView creates a view of the main menu by its
+// name, e.g. to start spofi in a specific view.
+func NewView(app *app.App, name string) (View, error) {
+	entry, ok := mainViews[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown view: %s", name)
+	}
+
+	title := format.FormatIcon(entry.icon(app.Config.Icons), entry.title)
+	return entry.create(app, title), nil
+}
+
 type mainView struct {
-	app    *app.App
-	parent View
+	app *app.App
 
 	rofi rofi.App
+	keys keymap.Keymap
+	// entries are the configured entries above the favorites.
+	entries []config.MenuEntry
+}
+
+// menuEntries is an internal implementation to get the
+// configured entries of the main menu with default titles
+// and icons. Invalid entries are skipped.
+func menuEntries(app *app.App) []config.MenuEntry {
+	entries := app.Config.Menu.Entries
+	if len(entries) == 0 {
+		entries = defaultMenuEntries
+		if len(app.Config.Profiles) > 0 {
+			entries = append(entries[:len(entries):len(entries)], config.MenuEntry{View: accountsViewID})
+		}
+	}
+
+	valid := make([]config.MenuEntry, 0, len(entries))
+	for _, entry := range entries {
+		switch {
+		case entry.View != "":
+			view, ok := mainViews[entry.View]
+			if !ok {
+				log.Printf("skipping menu entry with unknown view: %s", entry.View)
+				continue
+			}
+
+			if entry.Title == "" {
+				entry.Title = view.title
+			}
+			if entry.Icon == "" {
+				entry.Icon = view.icon(app.Config.Icons)
+			}
+		case entry.Search != "":
+			if entry.Title == "" {
+				entry.Title = entry.Search
+			}
+			if entry.Icon == "" {
+				entry.Icon = app.Config.Icons.Search
+			}
+		case entry.URI != "":
+			if entry.Title == "" {
+				entry.Title = entry.URI
+			}
+			if entry.Icon == "" {
+				entry.Icon = kindIcon(app, history.KindOf(entry.URI))
+			}
+		default:
+			log.Println("skipping menu entry without view, search or uri")
+			continue
+		}
+
+		valid = append(valid, entry)
+	}
 
-	devicesView        View
-	searchView         View
-	likedTracksView    View
-	queueView          View
-	recentlyPlayedView View
-	searchTracksView   *searchTracksView
-	playerView         View
-	savedAlbumsView    View
+	return valid
 }
 
 func NewMainView(app *app.App) View {
-	devicesViewTitle := format.FormatIcon(
-		app.Config.Icons.Device,
-		"Devices",
-	)
-
-	playerViewTitle := format.FormatIcon(
-		app.Config.Icons.Player,
-		"Player",
-	)
-
-	likedTracksViewTitle := format.FormatIcon(
-		app.Config.Icons.LikedTracks,
-		"Liked Tracks",
-	)
-
-	queueViewTitle := format.FormatIcon(
-		app.Config.Icons.Queue,
-		"Queue",
-	)
-
-	recentlyPlayedViewTitle := format.FormatIcon(
-		app.Config.Icons.RecentlyPlayed,
-		"Recently Played",
-	)
-
-	savedAlbumsViewTitle := format.FormatIcon(
-		app.Config.Icons.Album,
-		"Albums",
-	)
-
-	searchViewTitle := format.FormatIcon(
-		app.Config.Icons.Search,
-		"Search",
-	)
-
-	var msg = ""
-	if app.Config.ShowKeybindings {
-		msg = format.FormatKeybindings(
-			format.Keybinding{
-				Key:         app.Config.Keybindings.TogglePauseResume,
-				Description: "Toggle pause/resume",
-			},
-			format.Keybinding{
-				Key:         app.Config.Keybindings.NextTrack,
-				Description: "Next track",
-			},
-			format.Keybinding{
-				Key:         app.Config.Keybindings.PreviousTrack,
-				Description: "Previous track",
-			},
-			format.Keybinding{
-				Key:         app.Config.Keybindings.ToggleRepeat,
-				Description: "Toggle repeat",
-			},
-			format.Keybinding{
-				Key:         app.Config.Keybindings.ToggleShuffle,
-				Description: "Toggle shuffle",
-			},
-		)
-	}
+	keys := newKeymap(app.Config.Keybindings, mainKeymapID)
 
 	r := rofi.App{
-		IgnoreCase: true,
-		Keybindings: []string{
-			app.Config.Keybindings.TogglePauseResume,
-			app.Config.Keybindings.NextTrack,
-			app.Config.Keybindings.PreviousTrack,
-			app.Config.Keybindings.ToggleRepeat,
-			app.Config.Keybindings.ToggleShuffle,
-		},
-		Rows: []rofi.Row{
-			{
-				Title: playerViewTitle,
-				Value: playerViewID,
-			},
-			{
-				Title: searchViewTitle,
-				Value: searchViewID,
-			},
-			{
-				Title: likedTracksViewTitle,
-				Value: likedTracksViewID,
-			},
-			{
-				Title: savedAlbumsViewTitle,
-				Value: savedAlbumsViewID,
-			},
-			{
-				Title: queueViewTitle,
-				Value: queueViewID,
-			},
-			{
-				Title: recentlyPlayedViewTitle,
-				Value: recentlyPlayedViewID,
-			},
-			{
-				Title: devicesViewTitle,
-				Value: devicesViewID,
-			},
-		},
-		Message: msg,
+		IgnoreCase:   true,
+		RenderMarkup: true,
+		Keybindings:  keys.Keys(),
+		Message:      keybindingsMessage(app, keys),
 	}
 
 	view := &mainView{
-		rofi:               r,
-		app:                app,
-		devicesView:        NewDevicesView(app, devicesViewTitle),
-		searchView:         NewSearchView(app, searchViewTitle),
-		likedTracksView:    NewLikedTracksView(app, likedTracksViewTitle),
-		queueView:          NewQueueView(app, queueViewTitle),
-		recentlyPlayedView: NewRecentlyPlayedView(app, recentlyPlayedViewTitle),
-		searchTracksView:   NewSearchTrackView(app),
-		playerView:         NewPlayerView(app, playerViewTitle),
-		savedAlbumsView:    NewSavedAlbumsView(app, savedAlbumsViewTitle),
+		rofi:    r,
+		app:     app,
+		keys:    keys,
+		entries: menuEntries(app),
 	}
 
-	view.playerView.SetParent(view)
-	view.devicesView.SetParent(view)
-	view.searchView.SetParent(view)
-	view.likedTracksView.SetParent(view)
-	view.queueView.SetParent(view)
-	view.recentlyPlayedView.SetParent(view)
-	view.searchTracksView.SetParent(view)
-	view.savedAlbumsView.SetParent(view)
-
 	return view
 }
 
-func (view *mainView) buildPlayerMessage() string {
+func (view *mainView) buildRows() []rofi.Row {
+	rows := make([]rofi.Row, 0, len(view.entries))
+	for _, entry := range view.entries {
+		rows = append(rows, rofi.Row{
+			Title: format.FormatIcon(entry.Icon, format.Escape(entry.Title)),
+		})
+	}
+
+	return append(rows, favoriteRows(view.app)...)
+}
+
+// open is an internal implementation to open an entry.
+func (view *mainView) open(entry config.MenuEntry) Action {
+	title := format.FormatIcon(entry.Icon, entry.Title)
+
+	switch {
+	case entry.View != "":
+		return Push(mainViews[entry.View].create(view.app, title))
+	case entry.Search != "":
+		return Push(NewSearchTrackView(view.app, entry.Search))
+	}
+
+	return openFavorite(view.app, entry.URI)
+}
+
+func (view *mainView) buildPlayerMessage() (string, error) {
 	player, err := view.app.SpotifyClient.GetPlayer()
 	if err != nil {
-		getPlayerStateError(err)
-		os.Exit(1)
+		return "", err
 	}
 
 	currentlyPlaying := "Nothing is currently playing."
@@ -192,7 +248,7 @@ func (view *mainView) buildPlayerMessage() string {
 			repeat = view.app.Config.Icons.RepeatTrack
 		}
 
-		title := format.FormatTitle(player.Item.Name, player.Item.Artists[0].Name)
+		title := format.FormatTitle(player.Item.Name, format.TrackArtist(player.Item))
 		currentlyPlaying = fmt.Sprintf(
 			"%s | %s | %s %s",
 			status,
@@ -202,12 +258,17 @@ func (view *mainView) buildPlayerMessage() string {
 		)
 	}
 
-	return currentlyPlaying
+	return currentlyPlaying, nil
 }
 
-func (view *mainView) Show(payload ...interface{}) {
-	msg := view.buildPlayerMessage()
+func (view *mainView) Show() Action {
+	msg, err := view.buildPlayerMessage()
+	if err != nil {
+		getPlayerStateError(err)
+		return Exit()
+	}
 	view.rofi.Prompt = msg
+	view.rofi.Rows = view.buildRows()
 
 	evt, err := view.rofi.Run()
 	if err != nil {
@@ -216,53 +277,26 @@ func (view *mainView) Show(payload ...interface{}) {
 
 	switch evt := evt.(type) {
 	case rofi.KeyEvent:
-		switch evt.Key {
-		case view.app.Config.Keybindings.TogglePauseResume:
-			if err := view.app.Player.PlayPause(); err != nil {
-				playPauseError(err)
-			}
-		case view.app.Config.Keybindings.NextTrack:
-			if err := view.app.Player.Next(); err != nil {
-				skipTrackError(err)
-			}
-		case view.app.Config.Keybindings.PreviousTrack:
-			if err := view.app.Player.Previous(); err != nil {
-				previousTrackError(err)
-			}
-		case view.app.Config.Keybindings.ToggleRepeat:
-			if err := view.app.Player.ToggleRepeat(); err != nil {
-				updatePlayerError(err)
-			}
-		case view.app.Config.Keybindings.ToggleShuffle:
-			if err := view.app.Player.ToggleShuffle(); err != nil {
-				updatePlayerError(err)
-			}
+		action := view.keys.Action(evt.Key)
+		if playerAction(view.app, action) {
+			return Stay()
+		}
+
+		if action == keymap.ActionPin && evt.Index >= len(view.entries) {
+			togglePin(view.app, evt.Selection.Value)
 		}
 
-		view.Show()
+		return Stay()
 	case rofi.SelectedEvent:
-		switch evt.Selection.Value {
-		case playerViewID:
-			view.playerView.Show()
-		case devicesViewID:
-			view.devicesView.Show()
-		case searchViewID:
-			view.searchView.Show()
-		case likedTracksViewID:
-			view.likedTracksView.Show()
-		case queueViewID:
-			view.queueView.Show()
-		case recentlyPlayedViewID:
-			view.recentlyPlayedView.Show()
-		case savedAlbumsViewID:
-			view.savedAlbumsView.Show()
-		default:
-			view.searchTracksView.SetQuery(evt.Selection.Title)
-			view.searchTracksView.Show()
+		switch {
+		case evt.Index < 0:
+			return Push(NewSearchTrackView(view.app, evt.Selection.Title))
+		case evt.Index < len(view.entries):
+			return view.open(view.entries[evt.Index])
 		}
+
+		return openFavorite(view.app, evt.Selection.Value)
 	}
-}
 
-func (view *mainView) SetParent(parent View) {
-	view.parent = parent
+	return Exit()
 }
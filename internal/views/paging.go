@@ -0,0 +1,27 @@
+package views
+
+// totalPages returns the number of pages (at least one)
+// of a list with the given length.
+func totalPages(length int, limit int) int {
+	pages := (length + limit - 1) / limit
+	if pages < 1 {
+		return 1
+	}
+	return pages
+}
+
+// pageBounds returns the bounds of a page (starting at 1)
+// in a list with the given length.
+func pageBounds(page int, limit int, length int) (int, int) {
+	start := (page - 1) * limit
+	if start > length {
+		start = length
+	}
+
+	end := start + limit
+	if end > length {
+		end = length
+	}
+
+	return start, end
+}
@@ -3,10 +3,12 @@ package views
 import (
 	"fmt"
 	"log"
+	"strconv"
 
 	"github.com/davidborzek/spofi/internal/app"
 	"github.com/davidborzek/spofi/internal/format"
 	"github.com/davidborzek/spofi/pkg/rofi"
+	"github.com/davidborzek/spofi/pkg/spotify"
 )
 
 const (
@@ -18,9 +20,8 @@ const (
 )
 
 type playerView struct {
-	rofi   rofi.App
-	app    *app.App
-	parent View
+	rofi rofi.App
+	app  *app.App
 }
 
 func NewPlayerView(app *app.App, title string) View {
@@ -40,12 +41,27 @@ func NewPlayerView(app *app.App, title string) View {
 	return view
 }
 
-func (view *playerView) Show(payload ...interface{}) {
+// formatChoices is an internal implementation to format a label
+// followed by its options with the selected option underlined.
+func formatChoices(label string, selected string, options ...string) string {
+	var m format.Markup
+	m.Text(label)
+	for _, option := range options {
+		m.Text(" ")
+		if option == selected {
+			m.Underline(option)
+		} else {
+			m.Text(option)
+		}
+	}
+	return m.String()
+}
+
+func (view *playerView) Show() Action {
 	player, err := view.app.SpotifyClient.GetPlayer()
 	if err != nil {
 		getPlayerStateError(err)
-		view.parent.Show()
-		return
+		return Back()
 	}
 
 	playPauseKey := format.FormatIcon(view.app.Config.Icons.Player, "Nothing is currently playing.")
@@ -58,29 +74,37 @@ func (view *playerView) Show(payload ...interface{}) {
 			playPauseIcon = view.app.Config.Icons.Pause
 		}
 
-		playPauseKey = fmt.Sprintf(
-			"%s | %s %s | %s | %s/%s",
-			playPauseIcon,
-			view.app.Config.Icons.Track,
-			player.Item.Name,
-			player.Item.Artists[0].Name,
-			format.FormatTime(player.ProgressMs),
-			format.FormatTime(player.Item.DurationMs),
-		)
-
-		if player.RepeatState == "off" {
-			toggleRepeatKey = format.FormatIcon(view.app.Config.Icons.RepeatOff, "Repeat <u>off</u> context track")
-		} else if player.RepeatState == "context" {
-			toggleRepeatKey = format.FormatIcon(view.app.Config.Icons.RepeatContext, "Repeat off <u>context</u> track")
-		} else if player.RepeatState == "track" {
-			toggleRepeatKey = format.FormatIcon(view.app.Config.Icons.RepeatTrack, "Repeat off context <u>track</u>")
+		var m format.Markup
+		m.Text(fmt.Sprintf("%s | %s ", playPauseIcon, view.app.Config.Icons.Track)).
+			Bold(player.Item.Name).
+			Text(" | ").
+			Dim(format.TrackArtist(player.Item)).
+			Text(fmt.Sprintf(
+				" | %s/%s",
+				format.FormatTime(player.ProgressMs),
+				format.FormatTime(player.Item.DurationMs),
+			))
+		playPauseKey = m.String()
+
+		repeatIcon := view.app.Config.Icons.RepeatOff
+		if player.RepeatState == spotify.RepeatContext {
+			repeatIcon = view.app.Config.Icons.RepeatContext
+		} else if player.RepeatState == spotify.RepeatTrack {
+			repeatIcon = view.app.Config.Icons.RepeatTrack
 		}
+		toggleRepeatKey = format.FormatIcon(
+			repeatIcon,
+			formatChoices("Repeat", string(player.RepeatState), "off", "context", "track"),
+		)
 
+		shuffleIcon := view.app.Config.Icons.ShuffleOff
 		if player.ShuffleState {
-			toggleShuffleKey = format.FormatIcon(view.app.Config.Icons.ShuffleOn, "Shuffle <u>true</u> false")
-		} else {
-			toggleShuffleKey = format.FormatIcon(view.app.Config.Icons.ShuffleOff, "Shuffle true <u>false</u>")
+			shuffleIcon = view.app.Config.Icons.ShuffleOn
 		}
+		toggleShuffleKey = format.FormatIcon(
+			shuffleIcon,
+			formatChoices("Shuffle", strconv.FormatBool(player.ShuffleState), "true", "false"),
+		)
 	}
 
 	view.rofi.Rows = []rofi.Row{
@@ -112,8 +136,6 @@ func (view *playerView) Show(payload ...interface{}) {
 	}
 
 	switch evt := evt.(type) {
-	case rofi.BackEvent, rofi.CancelledEvent:
-		view.parent.Show()
 	case rofi.SelectedEvent:
 		var err error
 
@@ -134,10 +156,8 @@ func (view *playerView) Show(payload ...interface{}) {
 			updatePlayerError(err)
 		}
 
-		view.Show()
+		return Stay()
 	}
-}
 
-func (view *playerView) SetParent(parent View) {
-	view.parent = parent
+	return Back()
 }
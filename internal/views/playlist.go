@@ -0,0 +1,125 @@
+package views
+
+import (
+	"log"
+
+	"github.com/davidborzek/spofi/internal/app"
+	"github.com/davidborzek/spofi/internal/format"
+	"github.com/davidborzek/spofi/internal/keymap"
+	"github.com/davidborzek/spofi/pkg/rofi"
+	"github.com/davidborzek/spofi/pkg/spotify"
+)
+
+type playlistView struct {
+	rofi rofi.App
+	app  *app.App
+	keys keymap.Keymap
+
+	playlist spotify.Playlist
+}
+
+// NewPlaylistView creates a view for the tracks of a playlist.
+func NewPlaylistView(app *app.App, playlist spotify.Playlist) View {
+	keys := newKeymap(app.Config.Keybindings, playlistKeymapID)
+
+	r := rofi.App{
+		Prompt:       format.FormatTitle(playlist.Name, playlist.Owner.DisplayName),
+		Keybindings:  keys.Keys(),
+		ShowBack:     true,
+		NoCustom:     true,
+		IgnoreCase:   true,
+		RenderMarkup: true,
+		MultiSelect:  true,
+		Message:      keybindingsMessage(app, keys),
+	}
+
+	view := &playlistView{
+		rofi:     r,
+		app:      app,
+		keys:     keys,
+		playlist: playlist,
+	}
+
+	return view
+}
+
+func (view *playlistView) getTracks() ([]rofi.Row, error) {
+	items, err := view.app.Library.PlaylistTracks(view.playlist.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unavailable tracks (e.g. local files) have no id.
+	tracks := make([]spotify.Track, 0, len(items))
+	for _, item := range items {
+		if item.Track.ID != "" {
+			tracks = append(tracks, item.Track)
+		}
+	}
+
+	rows := format.FormatTrackRows(
+		tracks,
+		view.app.Config.Icons.Track,
+	)
+	setTrackArtwork(view.app, rows, tracks)
+	return rows, nil
+}
+
+func (view *playlistView) playPlaylist(uri ...string) {
+	if err := view.app.Player.PlayContext(view.playlist.URI, uri...); err != nil {
+		playContextError(err)
+	}
+}
+
+func (view *playlistView) Show() Action {
+	rows, err := view.getTracks()
+	if err != nil {
+		getTracksError(err)
+		return Back()
+	}
+
+	view.rofi.Rows = rows
+
+	evt, err := view.rofi.Run()
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+
+	switch evt := evt.(type) {
+	case rofi.BackEvent, rofi.CancelledEvent:
+		return Back()
+	case rofi.KeyEvent:
+		action := view.keys.Action(evt.Key)
+		if playerAction(view.app, action) {
+			return Stay()
+		}
+
+		switch action {
+		case keymap.ActionPlayAlbum:
+			view.playPlaylist()
+			return Exit()
+		case keymap.ActionQueue:
+			queueTracks(view.app, evt.Selections)
+		case keymap.ActionLike:
+			likeTracks(view.app, evt.Selections)
+		case keymap.ActionAddToPlaylist:
+			addTracksToPlaylist(view.app, evt.Selections)
+		case keymap.ActionPin:
+			togglePin(view.app, view.playlist.URI)
+		case keymap.ActionOpenAlbum:
+			return openAlbum(view.app, evt.Selection.Value)
+		case keymap.ActionOpenArtist:
+			return openArtist(view.app, evt.Selection.Value)
+		case keymap.ActionCopyLink:
+			copyLink(evt.Selection.Value)
+		}
+
+		return Stay()
+	case rofi.MultiSelectedEvent:
+		playTracks(view.app, evt.Selections)
+	case rofi.SelectedEvent:
+		view.playPlaylist(evt.Selection.Value)
+	}
+
+	return Exit()
+}
@@ -0,0 +1,125 @@
+package views
+
+import (
+	"log"
+
+	"github.com/davidborzek/spofi/internal/app"
+	"github.com/davidborzek/spofi/internal/format"
+	"github.com/davidborzek/spofi/internal/keymap"
+	"github.com/davidborzek/spofi/pkg/rofi"
+	"github.com/davidborzek/spofi/pkg/spotify"
+)
+
+type playlistsView struct {
+	rofi rofi.App
+	app  *app.App
+	keys keymap.Keymap
+
+	playlists []spotify.Playlist
+}
+
+// NewPlaylistsView creates a view for the playlists of the user.
+func NewPlaylistsView(app *app.App, title string) View {
+	keys := newKeymap(app.Config.Keybindings, playlistsViewID)
+
+	r := rofi.App{
+		Prompt:       title,
+		Keybindings:  keys.Keys(),
+		ShowBack:     true,
+		NoCustom:     true,
+		IgnoreCase:   true,
+		RenderMarkup: true,
+		Message:      keybindingsMessage(app, keys),
+	}
+
+	view := &playlistsView{
+		rofi: r,
+		app:  app,
+		keys: keys,
+	}
+
+	return view
+}
+
+func (view *playlistsView) getPlaylists() ([]rofi.Row, error) {
+	playlists, err := view.app.Library.Playlists()
+	if err != nil {
+		return nil, err
+	}
+	view.playlists = playlists
+
+	rows := format.FormatPlaylistRows(
+		playlists,
+		view.app.Config.Icons.Playlist,
+	)
+	setPlaylistArtwork(view.app, rows, playlists)
+	return rows, nil
+}
+
+// playlist is an internal implementation to get
+// a loaded playlist by the id of its row.
+func (view *playlistsView) playlist(id string) (spotify.Playlist, bool) {
+	for _, p := range view.playlists {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return spotify.Playlist{}, false
+}
+
+func (view *playlistsView) Show() Action {
+	rows, err := view.getPlaylists()
+	if err != nil {
+		getPlaylistsError(err)
+		return Back()
+	}
+
+	if len(rows) == 0 {
+		rofi.Error("No playlists found.")
+		return Back()
+	}
+
+	view.rofi.Rows = rows
+
+	evt, err := view.rofi.Run()
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+
+	switch evt := evt.(type) {
+	case rofi.BackEvent, rofi.CancelledEvent:
+		return Back()
+	case rofi.KeyEvent:
+		action := view.keys.Action(evt.Key)
+		if playerAction(view.app, action) {
+			return Stay()
+		}
+
+		playlist, ok := view.playlist(evt.Selection.Value)
+		if !ok {
+			return Stay()
+		}
+
+		switch action {
+		case keymap.ActionPlayAlbum:
+			if err := view.app.Player.PlayContext(playlist.URI); err != nil {
+				playContextError(err)
+			}
+			return Exit()
+		case keymap.ActionPin:
+			togglePin(view.app, playlist.URI)
+		case keymap.ActionCopyLink:
+			copyLink(playlist.URI)
+		}
+
+		return Stay()
+	case rofi.SelectedEvent:
+		if playlist, ok := view.playlist(evt.Selection.Value); ok {
+			return Push(NewPlaylistView(view.app, playlist))
+		}
+
+		return Stay()
+	}
+
+	return Exit()
+}
@@ -6,21 +6,21 @@ import (
 	"github.com/davidborzek/spofi/internal/app"
 	"github.com/davidborzek/spofi/internal/format"
 	"github.com/davidborzek/spofi/pkg/rofi"
+	"github.com/davidborzek/spofi/pkg/spotify"
 )
 
 type queueView struct {
 	rofi rofi.App
 	app  *app.App
-
-	parent View
 }
 
 func NewQueueView(app *app.App, title string) View {
 	r := rofi.App{
-		Prompt:     title,
-		ShowBack:   true,
-		NoCustom:   true,
-		IgnoreCase: true,
+		Prompt:       title,
+		ShowBack:     true,
+		NoCustom:     true,
+		IgnoreCase:   true,
+		RenderMarkup: true,
 	}
 
 	view := &queueView{
@@ -37,24 +37,36 @@ func (view *queueView) getQueue() ([]rofi.Row, error) {
 		return nil, err
 	}
 
+	tracks := result.Queue
+	if result.CurrentlyPlaying != nil {
+		tracks = append([]spotify.Track{*result.CurrentlyPlaying}, tracks...)
+	}
+
 	rows := format.FormatTrackRows(
-		result.Queue,
+		tracks,
 		view.app.Config.Icons.Track,
 	)
+	setTrackArtwork(view.app, rows, tracks)
+
+	// Highlight the currently playing track on top of the queue.
+	if result.CurrentlyPlaying != nil {
+		rows[0].Title = format.Bold(rows[0].Title)
+		rows[0].Active = true
+	}
+
 	return rows, nil
 }
 
-func (view *queueView) Show(payload ...interface{}) {
+func (view *queueView) Show() Action {
 	rows, err := view.getQueue()
 	if err != nil {
 		getQueueError(err)
-		return
+		return Back()
 	}
 
 	if len(rows) == 0 {
 		rofi.Error("Queue is empty.")
-		view.parent.Show()
-		return
+		return Back()
 	}
 
 	view.rofi.Rows = rows
@@ -65,13 +77,9 @@ func (view *queueView) Show(payload ...interface{}) {
 	}
 
 	switch evt.(type) {
-	case rofi.BackEvent, rofi.CancelledEvent:
-		view.parent.Show()
 	case rofi.SelectedEvent:
-		view.Show()
+		return Stay()
 	}
-}
 
-func (view *queueView) SetParent(parent View) {
-	view.parent = parent
+	return Back()
 }
@@ -0,0 +1,87 @@
+package views
+
+import (
+	"log"
+
+	"github.com/davidborzek/spofi/internal/app"
+	"github.com/davidborzek/spofi/internal/format"
+	"github.com/davidborzek/spofi/internal/history"
+	"github.com/davidborzek/spofi/pkg/rofi"
+)
+
+type quickView struct {
+	rofi rofi.App
+	app  *app.App
+}
+
+// NewQuickView creates a view of the items which were
+// played through spofi, ranked by frecency.
+func NewQuickView(app *app.App, title string) View {
+	r := rofi.App{
+		Prompt:       title,
+		ShowBack:     true,
+		NoCustom:     true,
+		IgnoreCase:   true,
+		RenderMarkup: true,
+	}
+
+	view := &quickView{
+		rofi: r,
+		app:  app,
+	}
+
+	return view
+}
+
+func (view *quickView) getEntries() []rofi.Row {
+	entries := view.app.History.Entries()
+
+	data := make([][]string, len(entries))
+	for i, e := range entries {
+		data[i] = []string{e.Name, e.Detail}
+	}
+
+	rows := make([]rofi.Row, len(entries))
+	for i, rawRow := range format.BuildMarkupRows(data, 30) {
+		rows[i] = rofi.Row{
+			Title: format.FormatIcon(kindIcon(view.app, entries[i].Kind), rawRow),
+			Value: entries[i].URI,
+			Meta:  string(entries[i].Kind),
+		}
+	}
+
+	return rows
+}
+
+func (view *quickView) Show() Action {
+	rows := view.getEntries()
+	if len(rows) == 0 {
+		emptyHistoryError()
+		return Back()
+	}
+
+	view.rofi.Rows = rows
+
+	evt, err := view.rofi.Run()
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+
+	switch evt := evt.(type) {
+	case rofi.SelectedEvent:
+		uri := evt.Selection.Value
+		if history.KindOf(uri) == history.KindTrack {
+			if err := view.app.Player.PlayTrack(uri); err != nil {
+				playTrackError(err)
+			}
+			return Exit()
+		}
+
+		if err := view.app.Player.PlayContext(uri); err != nil {
+			playContextError(err)
+		}
+		return Exit()
+	}
+
+	return Back()
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/davidborzek/spofi/internal/app"
 	"github.com/davidborzek/spofi/internal/format"
+	"github.com/davidborzek/spofi/internal/keymap"
 	"github.com/davidborzek/spofi/pkg/rofi"
 	"github.com/davidborzek/spofi/pkg/spotify"
 )
@@ -12,35 +13,27 @@ import (
 type recentlyPlayedView struct {
 	rofi rofi.App
 	app  *app.App
-
-	parent View
+	keys keymap.Keymap
 }
 
 func NewRecentlyPlayedView(app *app.App, title string) View {
-	var msg = ""
-	if app.Config.ShowKeybindings {
-		msg = format.FormatKeybindings(
-			format.Keybinding{
-				Key:         app.Config.Keybindings.AddToQueue,
-				Description: "Add to queue",
-			},
-		)
-	}
+	keys := newKeymap(app.Config.Keybindings, recentlyPlayedViewID)
 
 	r := rofi.App{
-		Prompt: title,
-		Keybindings: []string{
-			app.Config.Keybindings.AddToQueue,
-		},
-		ShowBack:   true,
-		NoCustom:   true,
-		IgnoreCase: true,
-		Message:    msg,
+		Prompt:       title,
+		Keybindings:  keys.Keys(),
+		ShowBack:     true,
+		NoCustom:     true,
+		IgnoreCase:   true,
+		RenderMarkup: true,
+		MultiSelect:  true,
+		Message:      keybindingsMessage(app, keys),
 	}
 
 	view := &recentlyPlayedView{
 		rofi: r,
 		app:  app,
+		keys: keys,
 	}
 
 	return view
@@ -61,20 +54,20 @@ func (view *recentlyPlayedView) getRecentlyPlayedTracks() ([]rofi.Row, error) {
 		tracks,
 		view.app.Config.Icons.Track,
 	)
+	setTrackArtwork(view.app, rows, tracks)
 	return rows, nil
 }
 
-func (view *recentlyPlayedView) Show(payload ...interface{}) {
+func (view *recentlyPlayedView) Show() Action {
 	rows, err := view.getRecentlyPlayedTracks()
 	if err != nil {
 		getRecentlyPlayedTracksError(err)
-		return
+		return Back()
 	}
 
 	if len(rows) == 0 {
 		rofi.Error("No recently played tracks.")
-		view.parent.Show()
-		return
+		return Back()
 	}
 
 	view.rofi.Rows = rows
@@ -86,17 +79,33 @@ func (view *recentlyPlayedView) Show(payload ...interface{}) {
 
 	switch evt := evt.(type) {
 	case rofi.BackEvent, rofi.CancelledEvent:
-		view.parent.Show()
+		return Back()
 	case rofi.KeyEvent:
-		switch evt.Key {
-		case view.app.Config.Keybindings.AddToQueue:
-			err := view.app.Player.AddQueue(evt.Selection.Value)
-			if err != nil {
-				addQueueError(err)
-			}
+		action := view.keys.Action(evt.Key)
+		if playerAction(view.app, action) {
+			return Stay()
+		}
+
+		switch action {
+		case keymap.ActionQueue:
+			queueTracks(view.app, evt.Selections)
+		case keymap.ActionLike:
+			likeTracks(view.app, evt.Selections)
+		case keymap.ActionAddToPlaylist:
+			addTracksToPlaylist(view.app, evt.Selections)
+		case keymap.ActionPin:
+			togglePin(view.app, evt.Selection.Value)
+		case keymap.ActionOpenAlbum:
+			return openAlbum(view.app, evt.Selection.Value)
+		case keymap.ActionOpenArtist:
+			return openArtist(view.app, evt.Selection.Value)
+		case keymap.ActionCopyLink:
+			copyLink(evt.Selection.Value)
 		}
 
-		view.Show()
+		return Stay()
+	case rofi.MultiSelectedEvent:
+		playTracks(view.app, evt.Selections)
 	case rofi.SelectedEvent:
 		err := view.app.Player.PlayTrack(evt.Selection.Value)
 		if err != nil {
@@ -104,8 +113,5 @@ func (view *recentlyPlayedView) Show(payload ...interface{}) {
 		}
 	}
 
-}
-
-func (view *recentlyPlayedView) SetParent(parent View) {
-	view.parent = parent
+	return Exit()
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/davidborzek/spofi/internal/app"
 	"github.com/davidborzek/spofi/internal/format"
+	"github.com/davidborzek/spofi/internal/keymap"
 	"github.com/davidborzek/spofi/pkg/rofi"
 	"github.com/davidborzek/spofi/pkg/spotify"
 )
@@ -17,77 +18,56 @@ const (
 type savedAlbumsView struct {
 	rofi rofi.App
 	app  *app.App
+	keys keymap.Keymap
 
-	parent View
-	albums *spotify.SavedAlbumResponse
+	albums []spotify.SavedAlbum
 
 	title      string
 	page       int
 	totalPages int
-
-	albumView View
 }
 
 func NewSavedAlbumsView(app *app.App, title string) View {
-	var msg = ""
-	if app.Config.ShowKeybindings {
-		msg = format.FormatKeybindings(
-			format.Keybinding{
-				Key:         app.Config.Keybindings.NextPage,
-				Description: "Next page",
-			},
-			format.Keybinding{
-				Key:         app.Config.Keybindings.PreviousPage,
-				Description: "Previous page",
-			},
-			format.Keybinding{
-				Key:         app.Config.Keybindings.PlayAlbum,
-				Description: "Play album",
-			},
-		)
-	}
+	keys := newKeymap(app.Config.Keybindings, savedAlbumsViewID)
 
 	r := rofi.App{
-		Prompt: title,
-		Keybindings: []string{
-			app.Config.Keybindings.NextPage,
-			app.Config.Keybindings.PreviousPage,
-			app.Config.Keybindings.PlayAlbum,
-		},
-		ShowBack:   true,
-		NoCustom:   true,
-		IgnoreCase: true,
-		Message:    msg,
+		Prompt:       title,
+		Keybindings:  keys.Keys(),
+		ShowBack:     true,
+		NoCustom:     true,
+		IgnoreCase:   true,
+		RenderMarkup: true,
+		Message:      keybindingsMessage(app, keys),
 	}
 
 	view := &savedAlbumsView{
-		rofi:      r,
-		app:       app,
-		page:      1,
-		title:     title,
-		albumView: NewAlbumView(app),
+		rofi:  r,
+		app:   app,
+		keys:  keys,
+		page:  1,
+		title: title,
 	}
 
-	view.albumView.SetParent(view)
-
 	return view
 }
 
 func (view *savedAlbumsView) getAlbums() ([]rofi.Row, error) {
-	currentOffset := (view.page - 1) * savedAlbumsViewLimit
-
-	result, err := view.app.SpotifyClient.GetSavedAlbums(savedAlbumsViewLimit, currentOffset)
+	saved, err := view.app.Library.SavedAlbums()
 	if err != nil {
 		return nil, err
 	}
 
-	view.albums = result
-
-	view.totalPages = result.Total / savedAlbumsViewLimit
+	// The library may have changed since the last page was shown.
+	view.totalPages = totalPages(len(saved), savedAlbumsViewLimit)
+	if view.page > view.totalPages {
+		view.page = view.totalPages
+	}
 
-	albums := make([]spotify.Album, len(result.Items))
-	for i, item := range result.Items {
+	start, end := pageBounds(view.page, savedAlbumsViewLimit, len(saved))
+	view.albums = saved[start:end]
 
+	albums := make([]spotify.Album, len(view.albums))
+	for i, item := range view.albums {
 		albums[i] = item.Album.Album
 	}
 
@@ -95,14 +75,15 @@ func (view *savedAlbumsView) getAlbums() ([]rofi.Row, error) {
 		albums,
 		view.app.Config.Icons.Album,
 	)
+	setAlbumArtwork(view.app, rows, albums)
 	return rows, nil
 }
 
-func (view *savedAlbumsView) Show(payload ...interface{}) {
+func (view *savedAlbumsView) Show() Action {
 	rows, err := view.getAlbums()
 	if err != nil {
 		getAlbumsError(err)
-		return
+		return Back()
 	}
 
 	view.rofi.Prompt = fmt.Sprintf("%s %d/%d", view.title, view.page, view.totalPages)
@@ -115,38 +96,46 @@ func (view *savedAlbumsView) Show(payload ...interface{}) {
 
 	switch evt := evt.(type) {
 	case rofi.BackEvent, rofi.CancelledEvent:
-		view.page = 1
-		view.parent.Show()
+		return Back()
 	case rofi.KeyEvent:
-		switch evt.Key {
-		case view.app.Config.Keybindings.NextPage:
+		action := view.keys.Action(evt.Key)
+		if playerAction(view.app, action) {
+			return Stay()
+		}
+
+		switch action {
+		case keymap.ActionNextPage:
 			if view.page < view.totalPages {
 				view.page += 1
 			}
-
-			view.Show()
-		case view.app.Config.Keybindings.PreviousPage:
+		case keymap.ActionPreviousPage:
 			if view.page > 1 {
 				view.page -= 1
 			}
-
-			view.Show()
-		case view.app.Config.Keybindings.PlayAlbum:
+		case keymap.ActionPlayAlbum:
 			err := view.app.Player.PlayContext(evt.Selection.Value)
 			if err != nil {
 				playAlbumError(err)
 			}
+			return Exit()
+		case keymap.ActionPin:
+			togglePin(view.app, evt.Selection.Value)
+		case keymap.ActionOpenArtist:
+			return openArtist(view.app, evt.Selection.Value)
+		case keymap.ActionCopyLink:
+			copyLink(evt.Selection.Value)
 		}
+
+		return Stay()
 	case rofi.SelectedEvent:
-		for _, a := range view.albums.Items {
+		for _, a := range view.albums {
 			if a.Album.URI == evt.Selection.Value {
-				view.albumView.Show(a.Album)
-				return
+				return Push(NewAlbumView(view.app, a.Album))
 			}
 		}
+
+		return Stay()
 	}
-}
 
-func (view *savedAlbumsView) SetParent(parent View) {
-	view.parent = parent
+	return Exit()
 }
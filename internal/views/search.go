@@ -10,10 +10,6 @@ import (
 type searchView struct {
 	rofi rofi.App
 	app  *app.App
-
-	parent View
-
-	filter string
 }
 
 func NewSearchView(app *app.App, title string) View {
@@ -30,33 +26,21 @@ func NewSearchView(app *app.App, title string) View {
 	return view
 }
 
-func (view *searchView) Show(payload ...interface{}) {
-	view.rofi.Filter = view.filter
-
+func (view *searchView) Show() Action {
 	evt, err := view.rofi.Run()
 	if err != nil {
 		log.Fatalln(err.Error())
 	}
 
 	switch evt := evt.(type) {
-	case rofi.BackEvent, rofi.CancelledEvent:
-		view.parent.Show()
 	case rofi.SelectedEvent:
-		view.filter = evt.Selection.Title
-
 		if evt.Selection.Title == "" {
 			rofi.Error("Search cannot be empty.")
-			view.Show()
-			return
+			return Stay()
 		}
 
-		tracks := NewSearchTrackView(view.app)
-		tracks.SetParent(view)
-		tracks.SetQuery(evt.Selection.Title)
-		tracks.Show()
+		return Push(NewSearchTrackView(view.app, evt.Selection.Title))
 	}
-}
 
-func (view *searchView) SetParent(parent View) {
-	view.parent = parent
+	return Back()
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/davidborzek/spofi/internal/app"
 	"github.com/davidborzek/spofi/internal/format"
+	"github.com/davidborzek/spofi/internal/keymap"
 	"github.com/davidborzek/spofi/pkg/rofi"
 	"github.com/davidborzek/spofi/pkg/spotify"
 )
@@ -12,22 +13,13 @@ import (
 type searchAlbumsView struct {
 	rofi rofi.App
 	app  *app.App
-
-	parent View
+	keys keymap.Keymap
 
 	query string
 }
 
-func NewSearchAlbumsView(app *app.App) *searchAlbumsView {
-	var msg = ""
-	if app.Config.ShowKeybindings {
-		msg = format.FormatKeybindings(
-			format.Keybinding{
-				Key:         app.Config.Keybindings.ToggleSearchType,
-				Description: "Toggle search type",
-			},
-		)
-	}
+func NewSearchAlbumsView(app *app.App, query string) View {
+	keys := newKeymap(app.Config.Keybindings, searchAlbumsKeymapID)
 
 	title := format.FormatIcon(
 		app.Config.Icons.Album,
@@ -35,39 +27,32 @@ func NewSearchAlbumsView(app *app.App) *searchAlbumsView {
 	)
 
 	r := rofi.App{
-		Prompt: title,
-		Keybindings: []string{
-			app.Config.Keybindings.ToggleSearchType,
-		},
-		ShowBack:   true,
-		NoCustom:   true,
-		IgnoreCase: true,
-		Message:    msg,
+		Prompt:       title,
+		Keybindings:  keys.Keys(),
+		ShowBack:     true,
+		NoCustom:     true,
+		IgnoreCase:   true,
+		RenderMarkup: true,
+		Message:      keybindingsMessage(app, keys),
 	}
 
 	return &searchAlbumsView{
-		rofi: r,
-		app:  app,
+		rofi:  r,
+		app:   app,
+		keys:  keys,
+		query: query,
 	}
 }
 
-func (view *searchAlbumsView) SetParent(parent View) {
-	view.parent = parent
-}
-
-func (view *searchAlbumsView) SetQuery(query string) {
-	view.query = query
-}
-
-func (view *searchAlbumsView) Show(payload ...interface{}) {
+func (view *searchAlbumsView) Show() Action {
 	if view.query == "" {
-		return
+		return Back()
 	}
 
 	if err := view.search(); err != nil {
 		rofi.Error("Could not search albums.")
 		log.Println(err)
-		return
+		return Back()
 	}
 
 	evt, err := view.rofi.Run()
@@ -76,21 +61,34 @@ func (view *searchAlbumsView) Show(payload ...interface{}) {
 	}
 
 	switch evt := evt.(type) {
-	case rofi.BackEvent, rofi.CancelledEvent:
-		view.parent.Show()
 	case rofi.KeyEvent:
-		switch evt.Key {
-		case view.app.Config.Keybindings.ToggleSearchType:
-			trackSearch := NewSearchTrackView(view.app)
-			trackSearch.SetParent(view.parent)
-			trackSearch.SetQuery(view.query)
-			trackSearch.Show()
+		action := view.keys.Action(evt.Key)
+		if playerAction(view.app, action) {
+			return Stay()
+		}
+
+		switch action {
+		case keymap.ActionToggleSearchType:
+			return Replace(NewSearchTrackView(view.app, view.query))
+		case keymap.ActionPlayAlbum:
+			if err := view.app.Player.PlayContext(evt.Selection.Value); err != nil {
+				playAlbumError(err)
+			}
+			return Exit()
+		case keymap.ActionPin:
+			togglePin(view.app, evt.Selection.Value)
+		case keymap.ActionOpenArtist:
+			return openArtist(view.app, evt.Selection.Value)
+		case keymap.ActionCopyLink:
+			copyLink(evt.Selection.Value)
 		}
+
+		return Stay()
 	case rofi.SelectedEvent:
-		album := NewAlbumView(view.app)
-		album.SetParent(view)
-		album.Show(spotify.URIToID(evt.Selection.Value))
+		return Push(NewAlbumViewByID(view.app, spotify.URIToID(evt.Selection.Value)))
 	}
+
+	return Back()
 }
 
 func (view *searchAlbumsView) search() error {
@@ -103,5 +101,6 @@ func (view *searchAlbumsView) search() error {
 		response.Albums.Items,
 		view.app.Config.Icons.Album,
 	)
+	setAlbumArtwork(view.app, view.rofi.Rows, response.Albums.Items)
 	return nil
 }
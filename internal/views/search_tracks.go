@@ -5,32 +5,20 @@ import (
 
 	"github.com/davidborzek/spofi/internal/app"
 	"github.com/davidborzek/spofi/internal/format"
+	"github.com/davidborzek/spofi/internal/keymap"
 	"github.com/davidborzek/spofi/pkg/rofi"
 )
 
 type searchTracksView struct {
 	rofi rofi.App
 	app  *app.App
-
-	parent View
+	keys keymap.Keymap
 
 	query string
 }
 
-func NewSearchTrackView(app *app.App) *searchTracksView {
-	var msg = ""
-	if app.Config.ShowKeybindings {
-		msg = format.FormatKeybindings(
-			format.Keybinding{
-				Key:         app.Config.Keybindings.ToggleSearchType,
-				Description: "Toggle search type",
-			},
-			format.Keybinding{
-				Key:         app.Config.Keybindings.AddToQueue,
-				Description: "Add to queue",
-			},
-		)
-	}
+func NewSearchTrackView(app *app.App, query string) View {
+	keys := newKeymap(app.Config.Keybindings, searchTracksKeymapID)
 
 	title := format.FormatIcon(
 		app.Config.Icons.Track,
@@ -38,41 +26,34 @@ func NewSearchTrackView(app *app.App) *searchTracksView {
 	)
 
 	r := rofi.App{
-		Prompt: title,
-		Keybindings: []string{
-			app.Config.Keybindings.AddToQueue,
-			app.Config.Keybindings.ToggleSearchType,
-		},
-		ShowBack:   true,
-		NoCustom:   true,
-		IgnoreCase: true,
-		Message:    msg,
+		Prompt:       title,
+		Keybindings:  keys.Keys(),
+		ShowBack:     true,
+		NoCustom:     true,
+		IgnoreCase:   true,
+		RenderMarkup: true,
+		MultiSelect:  true,
+		Message:      keybindingsMessage(app, keys),
 	}
 
 	view := &searchTracksView{
-		rofi: r,
-		app:  app,
+		rofi:  r,
+		app:   app,
+		keys:  keys,
+		query: query,
 	}
 
 	return view
 }
 
-func (view *searchTracksView) SetParent(parent View) {
-	view.parent = parent
-}
-
-func (view *searchTracksView) SetQuery(query string) {
-	view.query = query
-}
-
-func (view *searchTracksView) Show(payload ...interface{}) {
+func (view *searchTracksView) Show() Action {
 	if view.query == "" {
-		return
+		return Back()
 	}
 
 	if err := view.search(); err != nil {
 		searchError(err)
-		return
+		return Back()
 	}
 
 	evt, err := view.rofi.Run()
@@ -82,27 +63,43 @@ func (view *searchTracksView) Show(payload ...interface{}) {
 
 	switch evt := evt.(type) {
 	case rofi.BackEvent, rofi.CancelledEvent:
-		view.parent.Show()
+		return Back()
 	case rofi.KeyEvent:
-		switch evt.Key {
-		case view.app.Config.Keybindings.AddToQueue:
-			err := view.app.Player.AddQueue(evt.Selection.Value)
-			if err != nil {
-				addQueueError(err)
-			}
-			view.Show()
-		case view.app.Config.Keybindings.ToggleSearchType:
-			albumSearch := NewSearchAlbumsView(view.app)
-			albumSearch.SetParent(view.parent)
-			albumSearch.SetQuery(view.query)
-			albumSearch.Show()
+		action := view.keys.Action(evt.Key)
+		if playerAction(view.app, action) {
+			return Stay()
+		}
+
+		switch action {
+		case keymap.ActionQueue:
+			queueTracks(view.app, evt.Selections)
+		case keymap.ActionLike:
+			likeTracks(view.app, evt.Selections)
+		case keymap.ActionAddToPlaylist:
+			addTracksToPlaylist(view.app, evt.Selections)
+		case keymap.ActionPin:
+			togglePin(view.app, evt.Selection.Value)
+		case keymap.ActionToggleSearchType:
+			return Replace(NewSearchAlbumsView(view.app, view.query))
+		case keymap.ActionOpenAlbum:
+			return openAlbum(view.app, evt.Selection.Value)
+		case keymap.ActionOpenArtist:
+			return openArtist(view.app, evt.Selection.Value)
+		case keymap.ActionCopyLink:
+			copyLink(evt.Selection.Value)
 		}
+
+		return Stay()
+	case rofi.MultiSelectedEvent:
+		playTracks(view.app, evt.Selections)
 	case rofi.SelectedEvent:
 		err := view.app.Player.PlayTrack(evt.Selection.Value)
 		if err != nil {
 			playTrackError(err)
 		}
 	}
+
+	return Exit()
 }
 
 func (view *searchTracksView) search() error {
@@ -115,5 +112,6 @@ func (view *searchTracksView) search() error {
 		response.Tracks.Items,
 		view.app.Config.Icons.Track,
 	)
+	setTrackArtwork(view.app, view.rofi.Rows, response.Tracks.Items)
 	return nil
 }
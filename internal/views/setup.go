@@ -0,0 +1,52 @@
+package views
+
+import (
+	"github.com/davidborzek/spofi/internal/app"
+	"github.com/davidborzek/spofi/internal/config"
+	"github.com/davidborzek/spofi/internal/format"
+	"github.com/davidborzek/spofi/internal/theme"
+	"github.com/davidborzek/spofi/pkg/rofi"
+)
+
+// SetupOptions are the command line options,
+// which take precedence over the config.
+type SetupOptions struct {
+	// Theme is a custom theme, which replaces
+	// the theme of the config.
+	Theme string
+	// Menu is the menu backend, which decides
+	// how the keybindings are validated.
+	Menu string
+}
+
+// setupOptions are the options of the last setup, which
+// are kept when switching to another profile.
+var setupOptions SetupOptions
+
+// Setup creates the application context for a config and
+// applies the settings of the config which are shared by all
+// views, i.e. the theme, the row formats and the keybindings.
+// It is used on start and when switching to another profile.
+func Setup(cfg *config.Config, opts SetupOptions) (*app.App, error) {
+	if err := format.SetRowFormats(cfg.Formats); err != nil {
+		return nil, err
+	}
+
+	setupOptions = opts
+
+	switch {
+	case opts.Theme != "":
+		rofi.SetCustomTheme(opts.Theme)
+	case cfg.Theme != "":
+		rofi.SetCustomTheme(cfg.Theme)
+	default:
+		theme.LoadTheme()
+	}
+
+	CheckKeybindings(cfg.Keybindings, opts.Menu)
+
+	a := app.NewApp(cfg)
+	format.SetLikedFunc(a.Library.IsLiked)
+
+	return a, nil
+}
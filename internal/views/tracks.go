@@ -0,0 +1,110 @@
+package views
+
+import (
+	"log"
+
+	"github.com/davidborzek/spofi/internal/app"
+	"github.com/davidborzek/spofi/internal/format"
+	"github.com/davidborzek/spofi/pkg/rofi"
+	"github.com/davidborzek/spofi/pkg/spotify"
+)
+
+// rowValues returns the values (track uris)
+// of the given rows in order.
+func rowValues(rows []rofi.Row) []string {
+	values := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if row.Value != "" {
+			values = append(values, row.Value)
+		}
+	}
+	return values
+}
+
+// playTracks plays the tracks of the given rows in order.
+func playTracks(app *app.App, rows []rofi.Row) {
+	uris := rowValues(rows)
+	if len(uris) == 0 {
+		return
+	}
+
+	if err := app.Player.PlayTracks(uris...); err != nil {
+		playTrackError(err)
+	}
+}
+
+// queueTracks adds the tracks of the given rows
+// to the queue in order.
+func queueTracks(app *app.App, rows []rofi.Row) {
+	for _, uri := range rowValues(rows) {
+		if err := app.Player.AddQueue(uri); err != nil {
+			addQueueError(err)
+			return
+		}
+	}
+}
+
+// likeTracks saves the tracks of the given rows
+// to the liked tracks of the user.
+func likeTracks(app *app.App, rows []rofi.Row) {
+	uris := rowValues(rows)
+	if len(uris) == 0 {
+		return
+	}
+
+	ids := make([]string, len(uris))
+	for i, uri := range uris {
+		ids[i] = spotify.URIToID(uri)
+	}
+
+	if err := app.SpotifyClient.SaveTracks(ids); err != nil {
+		likeTracksError(err)
+		return
+	}
+
+	app.Library.InvalidateLikedTracks()
+}
+
+// addTracksToPlaylist lets the user select a playlist and
+// adds the tracks of the given rows to it in order.
+func addTracksToPlaylist(app *app.App, rows []rofi.Row) {
+	uris := rowValues(rows)
+	if len(uris) == 0 {
+		return
+	}
+
+	playlists, err := app.Library.Playlists()
+	if err != nil {
+		getPlaylistsError(err)
+		return
+	}
+
+	if len(playlists) == 0 {
+		rofi.Error("No playlists found.")
+		return
+	}
+
+	playlistRows := format.FormatPlaylistRows(playlists, app.Config.Icons.Playlist)
+	setPlaylistArtwork(app, playlistRows, playlists)
+
+	r := rofi.App{
+		Prompt:       format.FormatIcon(app.Config.Icons.Playlist, "Add to playlist"),
+		NoCustom:     true,
+		IgnoreCase:   true,
+		RenderMarkup: true,
+		Rows:         playlistRows,
+	}
+
+	evt, err := r.Run()
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+
+	if evt, ok := evt.(rofi.SelectedEvent); ok {
+		if err := app.SpotifyClient.AddPlaylistTracks(evt.Selection.Value, uris); err != nil {
+			addToPlaylistError(err)
+			return
+		}
+		app.Library.InvalidatePlaylist(evt.Selection.Value)
+	}
+}
@@ -3,6 +3,103 @@ package views
 // View represents a view of the application
 // which will be used to show in rofi.
 type View interface {
-	Show(payload ...interface{})
-	SetParent(parent View)
+	// Show shows the view once and returns the
+	// next action of the navigator.
+	Show() Action
+}
+
+type actionKind int
+
+const (
+	actionStay actionKind = iota
+	actionPush
+	actionReplace
+	actionBack
+	actionPopToRoot
+	actionRestart
+	actionExit
+)
+
+// Action tells the navigator what to do
+// after a view was shown.
+type Action struct {
+	kind actionKind
+	view View
+}
+
+// Stay shows the current view again.
+func Stay() Action {
+	return Action{kind: actionStay}
+}
+
+// Push shows a new view on top of the current view.
+func Push(view View) Action {
+	return Action{kind: actionPush, view: view}
+}
+
+// Replace replaces the current view with a new view.
+func Replace(view View) Action {
+	return Action{kind: actionReplace, view: view}
+}
+
+// Back returns to the previous view.
+func Back() Action {
+	return Action{kind: actionBack}
+}
+
+// PopToRoot returns to the first view.
+func PopToRoot() Action {
+	return Action{kind: actionPopToRoot}
+}
+
+// Restart replaces all views with a new
+// view, e.g. after switching the account.
+func Restart(view View) Action {
+	return Action{kind: actionRestart, view: view}
+}
+
+// Exit closes all views.
+func Exit() Action {
+	return Action{kind: actionExit}
+}
+
+// Navigator keeps a stack of views and shows the top
+// view until the stack is empty. Views stay alive while
+// they are on the stack, so their state (page, filter
+// and selected row) is preserved when returning to them.
+type Navigator struct {
+	stack []View
+}
+
+// NewNavigator creates a new navigator with a root view.
+// Additional views are shown on top of the root view, e.g.
+// to start in a view and return to the root view from it.
+func NewNavigator(root View, views ...View) *Navigator {
+	return &Navigator{
+		stack: append([]View{root}, views...),
+	}
+}
+
+// Run shows the views until the user exits
+// or returns from the root view.
+func (n *Navigator) Run() {
+	for len(n.stack) > 0 {
+		top := len(n.stack) - 1
+		action := n.stack[top].Show()
+
+		switch action.kind {
+		case actionPush:
+			n.stack = append(n.stack, action.view)
+		case actionReplace:
+			n.stack[top] = action.view
+		case actionBack:
+			n.stack = n.stack[:top]
+		case actionPopToRoot:
+			n.stack = n.stack[:1]
+		case actionRestart:
+			n.stack = []View{action.view}
+		case actionExit:
+			n.stack = nil
+		}
+	}
 }
@@ -0,0 +1,1005 @@
+package views
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/davidborzek/spofi/internal/app"
+	"github.com/davidborzek/spofi/internal/artwork"
+	"github.com/davidborzek/spofi/internal/config"
+	"github.com/davidborzek/spofi/internal/format"
+	"github.com/davidborzek/spofi/internal/history"
+	"github.com/davidborzek/spofi/internal/keymap"
+	"github.com/davidborzek/spofi/internal/library"
+	"github.com/davidborzek/spofi/internal/player"
+	"github.com/davidborzek/spofi/pkg/rofi"
+	"github.com/davidborzek/spofi/pkg/rofi/rofitest"
+	"github.com/davidborzek/spofi/pkg/spotify"
+	"github.com/davidborzek/spofi/pkg/spotify/spotifytest"
+)
+
+var (
+	testTracks = []spotify.Track{
+		spotifytest.NewTrack("track-1", "Intro", "Artist"),
+		spotifytest.NewTrack("track-2", "Second Song", "Artist"),
+		spotifytest.NewTrack("track-3", "Outro", "Artist"),
+	}
+
+	testAlbum = spotifytest.NewAlbum("album-1", "First Album", "Artist", testTracks...)
+
+	otherTrack = spotifytest.NewTrack("track-4", "Other", "Someone")
+)
+
+type testEnv struct {
+	app *app.App
+	srv *spotifytest.Server
+}
+
+// newTestEnv creates an application context backed by the
+// fake spotify server and a config in a temporary directory.
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(dir, "state"))
+	t.Setenv("HOME", dir)
+
+	if err := os.MkdirAll(filepath.Join(dir, "spofi"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	raw := []byte("spotify:\n  clientId: id\n  clientSecret: secret\n  refreshToken: token\n")
+	if err := os.WriteFile(filepath.Join(dir, "spofi", "spofi.yaml"), raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := spotifytest.NewServer()
+	t.Cleanup(srv.Close)
+
+	srv.AddDevice(spotifytest.NewDevice("device-1", "Laptop"))
+	srv.AddDevice(spotifytest.NewDevice("device-2", "Phone"))
+	srv.SaveAlbums(testAlbum)
+	srv.LikeTracks(testTracks...)
+	srv.SetRecentlyPlayed(testTracks[2], testTracks[0])
+	srv.AddTracks(otherTrack)
+	srv.AddPlaylist(spotifytest.NewPlaylist("playlist-1", "Focus"))
+
+	sp := srv.NewClient()
+
+	a := &app.App{
+		Config:        cfg,
+		SpotifyClient: sp,
+		Library:       library.Open(sp, ""),
+		History:       history.Open(""),
+	}
+	a.Player = history.NewPlayer(player.New(sp, ""), a.History, a.Describe)
+
+	return &testEnv{
+		srv: srv,
+		app: a,
+	}
+}
+
+// run runs the main view with the given script.
+func (env *testEnv) run(t *testing.T, steps ...rofitest.Step) *rofitest.Launcher {
+	t.Helper()
+
+	l := rofitest.New(t, steps...)
+	rofi.SetLauncher(l)
+	t.Cleanup(func() {
+		rofi.SetLauncher(rofi.ExecLauncher{})
+	})
+
+	NewNavigator(NewMainView(env.app)).Run()
+	env.app.Library.Wait()
+
+	if l.Remaining() != 0 {
+		t.Fatalf("%d steps were not replayed", l.Remaining())
+	}
+
+	return l
+}
+
+func (env *testEnv) mainRow(icon string, title string) rofitest.Step {
+	return rofitest.Select(format.FormatIcon(icon, title))
+}
+
+// defaultKey returns the default key of an action.
+func defaultKey(action string) string {
+	a, _ := keymap.Lookup(action)
+	return a.Keys[0]
+}
+
+func trackRow(icon string, i int) string {
+	return format.FormatTrackRows(testTracks, icon)[i].Title
+}
+
+func albumRow(icon string) string {
+	return format.FormatAlbumRows([]spotify.Album{testAlbum.Album}, icon)[0].Title
+}
+
+func TestNavigation(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(t *testing.T, env *testEnv)
+		steps  func(env *testEnv) []rofitest.Step
+		verify func(t *testing.T, env *testEnv, l *rofitest.Launcher)
+	}{
+		{
+			name: "cancel main view",
+			steps: func(env *testEnv) []rofitest.Step {
+				return []rofitest.Step{rofitest.Cancel()}
+			},
+		},
+		{
+			name: "play liked track",
+			steps: func(env *testEnv) []rofitest.Step {
+				icons := env.app.Config.Icons
+				return []rofitest.Step{
+					env.mainRow(icons.LikedTracks, "Liked Tracks"),
+					rofitest.Select(trackRow(icons.Track, 1)),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				if p := env.srv.Player(); p == nil || p.Item.ID != "track-2" {
+					t.Fatalf("expected track-2 to be playing, got %+v", p)
+				}
+			},
+		},
+		{
+			name: "queue liked track and go back",
+			steps: func(env *testEnv) []rofitest.Step {
+				cfg := env.app.Config
+				return []rofitest.Step{
+					env.mainRow(cfg.Icons.LikedTracks, "Liked Tracks"),
+					rofitest.Key(defaultKey(keymap.ActionQueue), trackRow(cfg.Icons.Track, 2)),
+					rofitest.Back(),
+					rofitest.Cancel(),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				if q := env.srv.Queue(); len(q) != 1 || q[0] != "spotify:track:track-3" {
+					t.Fatalf("unexpected queue %v", q)
+				}
+
+				menus := l.Menus()
+				if len(menus) != 4 || len(menus[1].Rows) != 4 || menus[1].Rows[0] != ".." {
+					t.Fatalf("unexpected menus %+v", menus)
+				}
+			},
+		},
+		{
+			name: "queue multiple liked tracks in order",
+			steps: func(env *testEnv) []rofitest.Step {
+				cfg := env.app.Config
+				return []rofitest.Step{
+					env.mainRow(cfg.Icons.LikedTracks, "Liked Tracks"),
+					rofitest.KeyMany(defaultKey(keymap.ActionQueue),
+						trackRow(cfg.Icons.Track, 2),
+						trackRow(cfg.Icons.Track, 0),
+					),
+					rofitest.Cancel(),
+					rofitest.Cancel(),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				q := env.srv.Queue()
+				if len(q) != 2 || q[0] != "spotify:track:track-3" || q[1] != "spotify:track:track-1" {
+					t.Fatalf("unexpected queue %v", q)
+				}
+			},
+		},
+		{
+			name: "play multiple liked tracks",
+			steps: func(env *testEnv) []rofitest.Step {
+				icons := env.app.Config.Icons
+				return []rofitest.Step{
+					env.mainRow(icons.LikedTracks, "Liked Tracks"),
+					rofitest.SelectMany(trackRow(icons.Track, 1), trackRow(icons.Track, 2)),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				if p := env.srv.Player(); p == nil || p.Item.ID != "track-2" {
+					t.Fatalf("expected track-2 to be playing, got %+v", p)
+				}
+			},
+		},
+		{
+			name: "search all liked tracks",
+			setup: func(t *testing.T, env *testEnv) {
+				for i := 0; i < 12; i++ {
+					id := fmt.Sprintf("extra-%d", i)
+					env.srv.LikeTracks(spotifytest.NewTrack(id, "Extra "+id, "Artist"))
+				}
+			},
+			steps: func(env *testEnv) []rofitest.Step {
+				cfg := env.app.Config
+				liked := env.srv.LikedTracks()
+				page := format.FormatTrackRows(liked[:10], cfg.Icons.Track)
+				all := format.FormatTrackRows(liked, cfg.Icons.Track)
+				return []rofitest.Step{
+					env.mainRow(cfg.Icons.LikedTracks, "Liked Tracks"),
+					rofitest.Key(defaultKey(keymap.ActionShowAll), page[0].Title),
+					rofitest.Select(all[14].Title),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				if p := env.srv.Player(); p == nil || p.Item.ID != "extra-11" {
+					t.Fatalf("expected extra-11 to be playing, got %+v", p)
+				}
+
+				menus := l.Menus()
+				if len(menus[1].Rows) != 11 || len(menus[2].Rows) != 16 || !strings.HasSuffix(menus[2].Prompt, "Liked Tracks (all)") {
+					t.Fatalf("unexpected menus %+v", menus)
+				}
+			},
+		},
+		{
+			name: "open artist of liked track and play album",
+			steps: func(env *testEnv) []rofitest.Step {
+				cfg := env.app.Config
+				return []rofitest.Step{
+					env.mainRow(cfg.Icons.LikedTracks, "Liked Tracks"),
+					rofitest.Key(defaultKey(keymap.ActionOpenArtist), trackRow(cfg.Icons.Track, 0)),
+					rofitest.Key(defaultKey(keymap.ActionPlayAlbum), albumRow(cfg.Icons.Album)),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				if c := env.srv.Context(); c != testAlbum.URI {
+					t.Fatalf("expected the album to be playing, got %q", c)
+				}
+
+				menus := l.Menus()
+				if len(menus) != 3 || !strings.Contains(menus[2].Prompt, "Artist") {
+					t.Fatalf("unexpected menus %+v", menus)
+				}
+			},
+		},
+		{
+			name: "add album tracks to playlist",
+			steps: func(env *testEnv) []rofitest.Step {
+				cfg := env.app.Config
+				return []rofitest.Step{
+					env.mainRow(cfg.Icons.Album, "Albums"),
+					rofitest.Select(albumRow(cfg.Icons.Album)),
+					rofitest.KeyMany(defaultKey(keymap.ActionAddToPlaylist),
+						trackRow(cfg.Icons.Track, 0),
+						trackRow(cfg.Icons.Track, 1),
+					),
+					rofitest.Select(format.FormatIcon(cfg.Icons.Playlist, "Focus")),
+					rofitest.Cancel(),
+					rofitest.Cancel(),
+					rofitest.Cancel(),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				p, _ := env.srv.Playlist("playlist-1")
+				if len(p.Tracks) != 2 || p.Tracks[0].ID != "track-1" || p.Tracks[1].ID != "track-2" {
+					t.Fatalf("unexpected playlist tracks %+v", p.Tracks)
+				}
+			},
+		},
+		{
+			name: "like searched tracks",
+			steps: func(env *testEnv) []rofitest.Step {
+				cfg := env.app.Config
+				return []rofitest.Step{
+					rofitest.Input("other"),
+					rofitest.Key(defaultKey(keymap.ActionLike), format.FormatTrackRows([]spotify.Track{otherTrack}, cfg.Icons.Track)[0].Title),
+					rofitest.Cancel(),
+					rofitest.Cancel(),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				if liked := env.srv.LikedTracks(); len(liked) != 4 || liked[0].ID != "track-4" {
+					t.Fatalf("unexpected liked tracks %+v", liked)
+				}
+			},
+		},
+		{
+			name: "play album from saved albums",
+			steps: func(env *testEnv) []rofitest.Step {
+				icons := env.app.Config.Icons
+				return []rofitest.Step{
+					env.mainRow(icons.Album, "Albums"),
+					rofitest.Select(albumRow(icons.Album)),
+					rofitest.Select(trackRow(icons.Track, 2)),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				if env.srv.Context() != testAlbum.URI {
+					t.Fatalf("unexpected context %q", env.srv.Context())
+				}
+				if p := env.srv.Player(); p.Item.ID != "track-3" {
+					t.Fatalf("expected track-3 to be playing, got %s", p.Item.ID)
+				}
+			},
+		},
+		{
+			name: "back from album view",
+			steps: func(env *testEnv) []rofitest.Step {
+				icons := env.app.Config.Icons
+				return []rofitest.Step{
+					env.mainRow(icons.Album, "Albums"),
+					rofitest.Select(albumRow(icons.Album)),
+					rofitest.Back(),
+					rofitest.Back(),
+					rofitest.Cancel(),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				menus := l.Menus()
+				if len(menus[2].Rows) != 4 {
+					t.Fatalf("expected album view with 3 tracks, got %v", menus[2].Rows)
+				}
+			},
+		},
+		{
+			name: "search tracks and toggle to albums",
+			steps: func(env *testEnv) []rofitest.Step {
+				cfg := env.app.Config
+				return []rofitest.Step{
+					env.mainRow(cfg.Icons.Search, "Search"),
+					rofitest.Input("album"),
+					rofitest.Key(defaultKey(keymap.ActionToggleSearchType), ".."),
+					rofitest.Select(albumRow(cfg.Icons.Album)),
+					rofitest.Key(defaultKey(keymap.ActionPlayAlbum), trackRow(cfg.Icons.Track, 0)),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				if env.srv.Context() != testAlbum.URI {
+					t.Fatalf("unexpected context %q", env.srv.Context())
+				}
+			},
+		},
+		{
+			name: "search from main view input",
+			steps: func(env *testEnv) []rofitest.Step {
+				icons := env.app.Config.Icons
+				return []rofitest.Step{
+					rofitest.Input("song"),
+					rofitest.Select(format.FormatTrackRows(testTracks[1:2], icons.Track)[0].Title),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				if p := env.srv.Player(); p == nil || p.Item.ID != "track-2" {
+					t.Fatalf("expected track-2 to be playing, got %+v", p)
+				}
+			},
+		},
+		{
+			name: "empty search shows error",
+			steps: func(env *testEnv) []rofitest.Step {
+				return []rofitest.Step{
+					env.mainRow(env.app.Config.Icons.Search, "Search"),
+					rofitest.Input(""),
+					rofitest.Cancel(),
+					rofitest.Cancel(),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				if errs := l.Errors(); len(errs) != 1 || errs[0] != "Search cannot be empty." {
+					t.Fatalf("unexpected errors %v", errs)
+				}
+			},
+		},
+		{
+			name: "search filter is restored when going back",
+			steps: func(env *testEnv) []rofitest.Step {
+				return []rofitest.Step{
+					env.mainRow(env.app.Config.Icons.Search, "Search"),
+					rofitest.Input("song"),
+					rofitest.Back(),
+					rofitest.Back(),
+					rofitest.Cancel(),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				if filter := l.Menus()[3].Filter; filter != "song" {
+					t.Fatalf("expected filter %q to be restored, got %q", "song", filter)
+				}
+			},
+		},
+		{
+			name: "fetch error returns to main view",
+			setup: func(t *testing.T, env *testEnv) {
+				env.srv.InjectFault(spotifytest.Fault{Path: "/me/tracks", Status: 500, Times: 1})
+			},
+			steps: func(env *testEnv) []rofitest.Step {
+				return []rofitest.Step{
+					env.mainRow(env.app.Config.Icons.LikedTracks, "Liked Tracks"),
+					rofitest.Cancel(),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				if errs := l.Errors(); len(errs) != 1 {
+					t.Fatalf("expected one error, got %v", errs)
+				}
+			},
+		},
+		{
+			name: "switch to an account which is not set up",
+			setup: func(t *testing.T, env *testEnv) {
+				env.app.Config.Profiles = map[string]config.Profile{"work": {}}
+			},
+			steps: func(env *testEnv) []rofitest.Step {
+				icons := env.app.Config.Icons
+				return []rofitest.Step{
+					env.mainRow(icons.Account, "Switch Account"),
+					rofitest.Select(format.FormatIcon(icons.Account, "work")),
+					rofitest.Back(),
+					rofitest.Cancel(),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				want := "The account of work is not set up. Run 'spofi setup --profile work'."
+				if errs := l.Errors(); len(errs) != 1 || errs[0] != want {
+					t.Fatalf("unexpected errors %v", errs)
+				}
+
+				if env.app.Config.Profile() != config.DefaultProfile {
+					t.Fatalf("expected the default profile, got %s", env.app.Config.Profile())
+				}
+
+				if opts := l.Menus()[1].RowOptions[1]; opts["active"] != "true" {
+					t.Fatalf("expected the default profile to be active, got %v", opts)
+				}
+			},
+		},
+		{
+			name: "select device",
+			steps: func(env *testEnv) []rofitest.Step {
+				return []rofitest.Step{
+					env.mainRow(env.app.Config.Icons.Device, "Devices"),
+					rofitest.Select("Phone"),
+					rofitest.Back(),
+					rofitest.Cancel(),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				if env.app.Config.Device.ID != "device-2" {
+					t.Fatalf("expected device-2 to be selected, got %+v", env.app.Config.Device)
+				}
+
+				if msg := l.Menus()[2].Message; msg != "Current device: Phone" {
+					t.Fatalf("unexpected message %q", msg)
+				}
+
+				if opts := l.Menus()[2].RowOptions[2]; opts["active"] != "true" {
+					t.Fatalf("expected the selected device to be active, got %v", opts)
+				}
+			},
+		},
+		{
+			name: "album art icons",
+			setup: func(t *testing.T, env *testEnv) {
+				t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+				images := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Write([]byte("cover"))
+				}))
+				t.Cleanup(images.Close)
+
+				cache, err := artwork.NewCache()
+				if err != nil {
+					t.Fatal(err)
+				}
+				env.app.Artwork = cache
+
+				album := spotifytest.NewAlbum("album-2", "Covered", "Artist")
+				album.Images = []spotify.Image{{URL: images.URL + "/cover", Width: 64, Height: 64}}
+				env.srv.SaveAlbums(album)
+			},
+			steps: func(env *testEnv) []rofitest.Step {
+				return []rofitest.Step{
+					env.mainRow(env.app.Config.Icons.Album, "Albums"),
+					rofitest.Back(),
+					rofitest.Cancel(),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				menu := l.Menus()[1]
+				if !menu.ShowIcons {
+					t.Fatalf("expected icons to be shown, got args %v", menu.Args)
+				}
+
+				if icon := menu.RowOptions[1]["icon"]; icon != "" {
+					t.Fatalf("expected no icon for an album without images, got %q", icon)
+				}
+
+				raw, err := os.ReadFile(menu.RowOptions[2]["icon"])
+				if err != nil || string(raw) != "cover" {
+					t.Fatalf("expected cached cover, got %q (%v)", raw, err)
+				}
+			},
+		},
+		{
+			name: "recently played",
+			steps: func(env *testEnv) []rofitest.Step {
+				icons := env.app.Config.Icons
+				return []rofitest.Step{
+					env.mainRow(icons.RecentlyPlayed, "Recently Played"),
+					rofitest.SelectIndex(2),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				if p := env.srv.Player(); p == nil || p.Item.ID != "track-1" {
+					t.Fatalf("expected track-1 to be playing, got %+v", p)
+				}
+			},
+		},
+		{
+			name: "replay album from quick view",
+			setup: func(t *testing.T, env *testEnv) {
+				if err := env.app.Player.PlayTrack(otherTrack.URI); err != nil {
+					t.Fatal(err)
+				}
+				for i := 0; i < 2; i++ {
+					if err := env.app.Player.PlayContext(testAlbum.URI); err != nil {
+						t.Fatal(err)
+					}
+				}
+			},
+			steps: func(env *testEnv) []rofitest.Step {
+				return []rofitest.Step{
+					env.mainRow(env.app.Config.Icons.Quick, "Quick"),
+					rofitest.SelectIndex(1),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				if env.srv.Context() != testAlbum.URI {
+					t.Fatalf("unexpected context %q", env.srv.Context())
+				}
+
+				rows := l.Menus()[1].Rows
+				if len(rows) != 3 || !strings.Contains(rows[1], "First Album") || !strings.Contains(rows[2], "Other") {
+					t.Fatalf("unexpected quick rows %v", rows)
+				}
+
+				if entries := env.app.History.Entries(); entries[0].Count != 3 {
+					t.Fatalf("expected the replay to be recorded, got %+v", entries)
+				}
+			},
+		},
+		{
+			name: "replay artist from quick view",
+			setup: func(t *testing.T, env *testEnv) {
+				if err := env.app.Player.PlayContext("spotify:artist:Artist"); err != nil {
+					t.Fatal(err)
+				}
+			},
+			steps: func(env *testEnv) []rofitest.Step {
+				return []rofitest.Step{
+					env.mainRow(env.app.Config.Icons.Quick, "Quick"),
+					rofitest.SelectIndex(1),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				entries := env.app.History.Entries()
+				if len(entries) != 1 || entries[0].Kind != history.KindArtist || entries[0].Name != "Artist" || entries[0].Count != 2 {
+					t.Fatalf("expected the artist to be recorded, got %+v", entries)
+				}
+
+				if env.srv.Context() != "spotify:artist:Artist" {
+					t.Fatalf("unexpected context %q", env.srv.Context())
+				}
+			},
+		},
+		{
+			name: "empty quick view",
+			steps: func(env *testEnv) []rofitest.Step {
+				return []rofitest.Step{
+					env.mainRow(env.app.Config.Icons.Quick, "Quick"),
+					rofitest.Cancel(),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				if errs := l.Errors(); len(errs) != 1 || errs[0] != "Nothing was played yet." {
+					t.Fatalf("unexpected errors %v", errs)
+				}
+			},
+		},
+		{
+			name: "pin album to main menu",
+			steps: func(env *testEnv) []rofitest.Step {
+				cfg := env.app.Config
+				favorite := format.FormatIcon(cfg.Icons.Album, "First Album")
+				return []rofitest.Step{
+					env.mainRow(cfg.Icons.Album, "Albums"),
+					rofitest.Key(defaultKey(keymap.ActionPin), albumRow(cfg.Icons.Album)),
+					rofitest.Back(),
+					rofitest.Select(favorite),
+					rofitest.Select(trackRow(cfg.Icons.Track, 0)),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				cfg, err := config.LoadConfig()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				want := []config.Favorite{{Name: "First Album", URI: testAlbum.URI}}
+				if !reflect.DeepEqual(cfg.Favorites, want) {
+					t.Fatalf("unexpected favorites %+v", cfg.Favorites)
+				}
+
+				if env.srv.Context() != testAlbum.URI {
+					t.Fatalf("unexpected context %q", env.srv.Context())
+				}
+			},
+		},
+		{
+			name: "pin artist to main menu",
+			steps: func(env *testEnv) []rofitest.Step {
+				cfg := env.app.Config
+				return []rofitest.Step{
+					env.mainRow(cfg.Icons.LikedTracks, "Liked Tracks"),
+					rofitest.Key(defaultKey(keymap.ActionOpenArtist), trackRow(cfg.Icons.Track, 0)),
+					rofitest.Key(defaultKey(keymap.ActionPin), albumRow(cfg.Icons.Album)),
+					rofitest.Back(),
+					rofitest.Back(),
+					rofitest.Cancel(),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				want := []config.Favorite{{Name: "Artist", URI: "spotify:artist:Artist"}}
+				if !reflect.DeepEqual(env.app.Config.Favorites, want) {
+					t.Fatalf("unexpected favorites %+v", env.app.Config.Favorites)
+				}
+			},
+		},
+		{
+			name: "pin playlist and play a track of it",
+			setup: func(t *testing.T, env *testEnv) {
+				env.srv.AddPlaylist(spotifytest.NewPlaylist("playlist-2", "Run", testTracks...))
+			},
+			steps: func(env *testEnv) []rofitest.Step {
+				cfg := env.app.Config
+				playlist := format.FormatIcon(cfg.Icons.Playlist, "Run")
+				return []rofitest.Step{
+					env.mainRow(cfg.Icons.Playlist, "Playlists"),
+					rofitest.Key(defaultKey(keymap.ActionPin), playlist),
+					rofitest.Select(playlist),
+					rofitest.Select(trackRow(cfg.Icons.Track, 1)),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				want := []config.Favorite{{Name: "Run", URI: "spotify:playlist:playlist-2"}}
+				if !reflect.DeepEqual(env.app.Config.Favorites, want) {
+					t.Fatalf("unexpected favorites %+v", env.app.Config.Favorites)
+				}
+
+				if p := env.srv.Player(); p == nil || p.Item.ID != "track-2" || env.srv.Context() != "spotify:playlist:playlist-2" {
+					t.Fatalf("expected track-2 of the playlist to be playing, got %+v", p)
+				}
+			},
+		},
+		{
+			name: "unpin favorite",
+			setup: func(t *testing.T, env *testEnv) {
+				env.app.Config.Favorites = []config.Favorite{{Name: "Other", URI: otherTrack.URI}}
+			},
+			steps: func(env *testEnv) []rofitest.Step {
+				cfg := env.app.Config
+				return []rofitest.Step{
+					rofitest.Key(defaultKey(keymap.ActionPin), format.FormatIcon(cfg.Icons.Track, "Other")),
+					rofitest.Cancel(),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				menus := l.Menus()
+				if len(menus[0].Rows) != len(menus[1].Rows)+1 {
+					t.Fatalf("expected the favorite to be removed, got %v", menus[1].Rows)
+				}
+
+				if len(env.app.Config.Favorites) != 0 {
+					t.Fatalf("unexpected favorites %+v", env.app.Config.Favorites)
+				}
+			},
+		},
+		{
+			name: "configured menu layout",
+			setup: func(t *testing.T, env *testEnv) {
+				env.app.Config.Menu.Entries = []config.MenuEntry{
+					{View: "devices", Title: "Outputs"},
+					{Search: "song", Title: "Songs"},
+					{URI: testAlbum.URI, Title: "Album"},
+					{View: "unknown"},
+				}
+			},
+			steps: func(env *testEnv) []rofitest.Step {
+				icons := env.app.Config.Icons
+				return []rofitest.Step{
+					rofitest.Select(format.FormatIcon(icons.Search, "Songs")),
+					rofitest.Cancel(),
+					rofitest.Select(format.FormatIcon(icons.Album, "Album")),
+					rofitest.Cancel(),
+					rofitest.Cancel(),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				icons := env.app.Config.Icons
+				menus := l.Menus()
+
+				want := []string{
+					format.FormatIcon(icons.Device, "Outputs"),
+					format.FormatIcon(icons.Search, "Songs"),
+					format.FormatIcon(icons.Album, "Album"),
+				}
+				if !reflect.DeepEqual(menus[0].Rows, want) {
+					t.Fatalf("unexpected main rows %v", menus[0].Rows)
+				}
+
+				if len(menus[1].Rows) != 2 || menus[3].Prompt != format.FormatTitle("First Album", "Artist") {
+					t.Fatalf("unexpected menus %+v", menus)
+				}
+			},
+		},
+		{
+			name: "empty queue",
+			steps: func(env *testEnv) []rofitest.Step {
+				return []rofitest.Step{
+					env.mainRow(env.app.Config.Icons.Queue, "Queue"),
+					rofitest.Cancel(),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				if errs := l.Errors(); len(errs) != 1 || errs[0] != "Queue is empty." {
+					t.Fatalf("unexpected errors %v", errs)
+				}
+			},
+		},
+		{
+			name: "queue highlights current track",
+			setup: func(t *testing.T, env *testEnv) {
+				if err := env.app.Player.PlayContext(testAlbum.URI); err != nil {
+					t.Fatal(err)
+				}
+			},
+			steps: func(env *testEnv) []rofitest.Step {
+				return []rofitest.Step{
+					env.mainRow(env.app.Config.Icons.Queue, "Queue"),
+					rofitest.Cancel(),
+					rofitest.Cancel(),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				current := format.FormatTrackRows(testTracks[:1], env.app.Config.Icons.Track)[0].Title
+
+				menu := l.Menus()[1]
+				if len(menu.Rows) != 2 || menu.Rows[1] != format.Bold(current) {
+					t.Fatalf("unexpected rows %q", menu.Rows)
+				}
+
+				if menu.RowOptions[1]["active"] != "true" {
+					t.Fatalf("expected the current track to be active, got %v", menu.RowOptions[1])
+				}
+			},
+		},
+		{
+			name: "episode in the player",
+			setup: func(t *testing.T, env *testEnv) {
+				episode := spotify.Track{
+					ID:   "episode-1",
+					URI:  "spotify:episode:episode-1",
+					Name: "Episode",
+					Type: "episode",
+					Show: spotify.Show{Name: "Show"},
+				}
+				env.srv.AddTracks(episode)
+
+				if err := env.app.SpotifyClient.PlayTrack(episode.URI, ""); err != nil {
+					t.Fatal(err)
+				}
+			},
+			steps: func(env *testEnv) []rofitest.Step {
+				return []rofitest.Step{
+					env.mainRow(env.app.Config.Icons.Player, "Player"),
+					rofitest.Back(),
+					rofitest.Cancel(),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				menus := l.Menus()
+				if !strings.Contains(menus[0].Prompt, format.FormatTitle("Episode", "Show")) {
+					t.Fatalf("expected the show of the episode, got %q", menus[0].Prompt)
+				}
+
+				if !strings.Contains(menus[1].Rows[1], format.Dim("Show")) {
+					t.Fatalf("expected the show of the episode, got %q", menus[1].Rows[1])
+				}
+			},
+		},
+		{
+			name: "player controls",
+			setup: func(t *testing.T, env *testEnv) {
+				if err := env.app.Player.PlayContext(testAlbum.URI); err != nil {
+					t.Fatal(err)
+				}
+			},
+			steps: func(env *testEnv) []rofitest.Step {
+				cfg := env.app.Config
+				return []rofitest.Step{
+					rofitest.Key(defaultKey(keymap.ActionToggleShuffle), format.FormatIcon(cfg.Icons.Player, "Player")),
+					env.mainRow(cfg.Icons.Player, "Player"),
+					rofitest.Select(format.FormatIcon(cfg.Icons.Next, "Next")),
+					rofitest.Back(),
+					rofitest.Cancel(),
+				}
+			},
+			verify: func(t *testing.T, env *testEnv, l *rofitest.Launcher) {
+				p := env.srv.Player()
+				if !p.ShuffleState || p.Item.ID != "track-2" {
+					t.Fatalf("unexpected player state %+v", p)
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			env := newTestEnv(t)
+
+			if tc.setup != nil {
+				tc.setup(t, env)
+			}
+
+			l := env.run(t, tc.steps(env)...)
+
+			if tc.verify != nil {
+				tc.verify(t, env, l)
+			}
+		})
+	}
+}
+
+// stubView is a view which returns a fixed sequence of actions.
+type stubView struct {
+	name    string
+	shown   *[]string
+	actions []Action
+}
+
+func (v *stubView) Show() Action {
+	*v.shown = append(*v.shown, v.name)
+	action := v.actions[0]
+	v.actions = v.actions[1:]
+	return action
+}
+
+func TestNewView(t *testing.T) {
+	env := newTestEnv(t)
+
+	l := rofitest.New(t, rofitest.Back(), rofitest.Cancel())
+	rofi.SetLauncher(l)
+	t.Cleanup(func() {
+		rofi.SetLauncher(rofi.ExecLauncher{})
+	})
+
+	view, err := NewView(env.app, "devices")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The view is shown first and returns to the main view.
+	NewNavigator(NewMainView(env.app), view).Run()
+
+	menus := l.Menus()
+	if len(menus) != 2 || !strings.HasSuffix(menus[0].Prompt, "Devices") {
+		t.Fatalf("unexpected menus %+v", menus)
+	}
+
+	if _, err := NewView(env.app, "unknown"); err == nil {
+		t.Fatal("expected an error for an unknown view")
+	}
+}
+
+func TestLikedTracksLoadError(t *testing.T) {
+	tests := []struct {
+		name   string
+		steps  []rofitest.Step
+		menus  int
+		errors int
+	}{
+		{
+			// The error is reported when the menu is left.
+			name:   "cancel",
+			steps:  []rofitest.Step{rofitest.Cancel()},
+			menus:  1,
+			errors: 1,
+		},
+		{
+			// The action is handled although the loading failed.
+			name: "show pages",
+			steps: []rofitest.Step{
+				rofitest.Key(defaultKey(keymap.ActionShowAll), ".."),
+				rofitest.Cancel(),
+			},
+			menus: 2,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.srv.InjectFault(spotifytest.Fault{Path: "/me/tracks", Status: 500, Times: 1})
+
+			l := rofitest.New(t, tc.steps...)
+			rofi.SetLauncher(l)
+			t.Cleanup(func() {
+				rofi.SetLauncher(rofi.ExecLauncher{})
+			})
+
+			view := NewLikedTracksView(env.app, "Liked Tracks").(*likedTracksView)
+			view.showAll = true
+
+			NewNavigator(view).Run()
+
+			if menus := l.Menus(); len(menus) != tc.menus {
+				t.Fatalf("expected %d menus, got %+v", tc.menus, menus)
+			}
+
+			if errs := l.Errors(); len(errs) != tc.errors {
+				t.Fatalf("expected %d errors, got %v", tc.errors, errs)
+			}
+		})
+	}
+}
+
+func TestNavigator(t *testing.T) {
+	var shown []string
+	view := func(name string, actions ...Action) View {
+		return &stubView{name: name, shown: &shown, actions: actions}
+	}
+
+	replaced := view("replaced", PopToRoot())
+	child := view("child", Stay(), Replace(replaced))
+	grandchild := view("grandchild", Back())
+	root := view("root", Push(child), Push(view("other", Push(grandchild), Exit())), Stay())
+
+	NewNavigator(root).Run()
+
+	want := []string{"root", "child", "child", "replaced", "root", "other", "grandchild", "other"}
+	if !reflect.DeepEqual(shown, want) {
+		t.Fatalf("expected %v, got %v", want, shown)
+	}
+}
+
+func TestKeybindings(t *testing.T) {
+	if errs := ValidateKeybindings(nil, rofi.BackendRofi); len(errs) != 0 {
+		t.Fatalf("expected the default keybindings to be valid, got %v", errs)
+	}
+
+	keybindings := config.Keybindings{
+		keymap.ActionShowAll: {defaultKey(keymap.ActionQueue)},
+		keymap.ActionPin:     {"Alt+f"},
+	}
+
+	// Pin clashes with rofi in all 10 views with keybindings.
+	errs := ValidateKeybindings(keybindings, rofi.BackendRofi)
+	if len(errs) != 11 {
+		t.Fatalf("expected 11 conflicts, got %v", errs)
+	}
+
+	if errs := ValidateKeybindings(keybindings, rofi.BackendFzf); len(errs) != 1 {
+		t.Fatalf("expected one conflict without rofi builtins, got %v", errs)
+	}
+
+	// The player controls are only bound in the main view by default.
+	if key := newKeymap(nil, albumKeymapID).Key(keymap.ActionPreviousTrack); key != "" {
+		t.Fatalf("expected previous track to be unbound in the album view, got %q", key)
+	}
+}
@@ -0,0 +1,73 @@
+// Package xdg resolves the paths of the files which
+// spofi keeps in the xdg state and cache dirs.
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// dirName is the directory of spofi
+// in the state and cache dirs.
+const dirName = "spofi"
+
+// StateDir returns the state dir of spofi based on
+// $XDG_STATE_HOME, which defaults to ~/.local/state.
+func StateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, dirName), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".local", "state", dirName), nil
+}
+
+// CacheDir returns the cache dir of spofi
+// in the os user cache dir.
+func CacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, dirName), nil
+}
+
+// StateFile returns the path of a file of a profile in the
+// state dir, e.g. state-work.json for state.json and the
+// profile work. The default profile has an empty name.
+func StateFile(name string, profile string) (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, profileFileName(name, profile)), nil
+}
+
+// CacheFile returns the path of a file of a
+// profile in the cache dir (see StateFile).
+func CacheFile(name string, profile string) (string, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, profileFileName(name, profile)), nil
+}
+
+// profileFileName is an internal implementation to get the
+// name of a file of a profile, e.g. state-work.json.
+func profileFileName(name string, profile string) string {
+	if profile == "" {
+		return name
+	}
+
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "-" + profile + ext
+}
@@ -0,0 +1,32 @@
+package xdg
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStateFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", dir)
+
+	tests := []struct {
+		name    string
+		profile string
+		want    string
+	}{
+		{"state.json", "", "state.json"},
+		{"state.json", "work", "state-work.json"},
+		{"history.json", "work", "history-work.json"},
+	}
+
+	for _, tc := range tests {
+		path, err := StateFile(tc.name, tc.profile)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if want := filepath.Join(dir, "spofi", tc.want); path != want {
+			t.Errorf("expected %s, got %s", want, path)
+		}
+	}
+}
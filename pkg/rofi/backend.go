@@ -0,0 +1,329 @@
+package rofi
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	BackendRofi   = "rofi"
+	BackendWofi   = "wofi"
+	BackendFuzzel = "fuzzel"
+	BackendBemenu = "bemenu"
+	BackendDmenu  = "dmenu"
+	BackendFzf    = "fzf"
+)
+
+var (
+	backend  Backend  = &rofiBackend{}
+	launcher Launcher = ExecLauncher{}
+
+	markupTagRegex = regexp.MustCompile(`<[^>]*>`)
+)
+
+// Menu is the backend independent description of a menu.
+type Menu struct {
+	// Prompt is the prompt of the menu.
+	Prompt string
+	// Message is the message of the menu.
+	Message string
+	// Filter is the initial filter of the menu.
+	Filter string
+	// IgnoreCase defines the case-insensitivity of the search.
+	IgnoreCase bool
+	// NoCustom disables custom input.
+	NoCustom bool
+	// RenderMarkup enables pango markup rendering of the rows.
+	RenderMarkup bool
+	// MultiSelect allows to select multiple rows.
+	MultiSelect bool
+	// Keybindings are the custom keybindings of the menu.
+	Keybindings []string
+	// ShowIcons enables the icons of the rows.
+	ShowIcons bool
+	// Rows are the rows of the menu.
+	Rows []Row
+	// Stream optionally streams more rows, which are appended
+	// to the rows while the menu is shown. It is closed when
+	// all rows were sent. Backends must always drain it.
+	Stream <-chan []Row
+	// SelectedRow is the initially selected row.
+	SelectedRow int
+}
+
+// Selection is a selected row or the custom input of a menu.
+type Selection struct {
+	// Index is the index of the selected row in the menu
+	// rows or -1 for custom input.
+	Index int
+	// Text is the title of the selected row or the custom input.
+	Text string
+}
+
+// Result is the result of a shown menu.
+type Result struct {
+	// Selections are the selected rows or the custom input.
+	Selections []Selection
+	// Key is the index of the pressed custom keybinding
+	// or -1 when no keybinding was pressed.
+	Key int
+	// Cancelled is true when the menu was cancelled.
+	Cancelled bool
+	// Filter is the filter (user input) when the menu was
+	// closed. Backends which cannot report the filter
+	// return the initial filter of the menu.
+	Filter string
+}
+
+// Backend shows menus using a specific menu program.
+type Backend interface {
+	// Show shows a menu and waits for the user input.
+	Show(menu Menu) (Result, error)
+	// Error shows an error message.
+	Error(msg string) error
+}
+
+// SetBackend globally sets the backend
+// which is used to show all rofi views.
+func SetBackend(b Backend) {
+	backend = b
+}
+
+// NewBackend creates a backend by its name.
+func NewBackend(name string) (Backend, error) {
+	switch name {
+	case "", BackendRofi:
+		return &rofiBackend{}, nil
+	case BackendWofi:
+		return newWofiBackend(), nil
+	case BackendFuzzel:
+		return newFuzzelBackend(), nil
+	case BackendBemenu:
+		return newBemenuBackend(), nil
+	case BackendDmenu:
+		return newDmenuBackend(), nil
+	case BackendFzf:
+		return &fzfBackend{}, nil
+	}
+
+	return nil, fmt.Errorf("unknown menu backend: %s", name)
+}
+
+// SetLauncher globally sets the launcher
+// which is used to run all menu programs.
+func SetLauncher(l Launcher) {
+	launcher = l
+}
+
+// Launcher launches a menu program with the given arguments
+// and input and returns its output and exit status.
+type Launcher interface {
+	Launch(name string, args []string, input io.Reader) (string, int, error)
+}
+
+// ExecLauncher launches the menu program as a process.
+type ExecLauncher struct{}
+
+func (ExecLauncher) Launch(name string, args []string, input io.Reader) (string, int, error) {
+	var out bytes.Buffer
+
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+
+	// The input is copied manually instead of using cmd.Stdin,
+	// so waiting for the program does not wait for a streamed
+	// input which is still loading.
+	var stdin io.WriteCloser
+	if input != nil {
+		var err error
+		if stdin, err = cmd.StdinPipe(); err != nil {
+			return "", 0, err
+		}
+	}
+
+	if err := cmd.Start(); err != nil {
+		return "", 0, err
+	}
+
+	if stdin != nil {
+		go func() {
+			io.Copy(stdin, input)
+			stdin.Close()
+		}()
+	}
+
+	err := cmd.Wait()
+
+	status := 0
+	if err != nil {
+		if exiterr, ok := err.(*exec.ExitError); ok {
+			status = exiterr.ExitCode()
+		} else {
+			return "", 0, err
+		}
+	}
+
+	return out.String(), status, nil
+}
+
+// stripMarkup is an internal implementation to remove
+// pango markup for backends without markup support.
+func stripMarkup(s string) string {
+	return html.UnescapeString(markupTagRegex.ReplaceAllString(s, ""))
+}
+
+// rowInput is an internal implementation to write the rows of a
+// menu line by line as menu input using the given backend specific
+// line format. Streamed rows are written as soon as they arrive.
+type rowInput struct {
+	io.Reader
+
+	pipe *io.PipeReader
+
+	mu   sync.Mutex
+	rows []Row
+}
+
+func newRowInput(menu Menu, line func(i int, row Row) string) *rowInput {
+	in := &rowInput{
+		Reader: strings.NewReader(formatRows(menu.Rows, 0, line)),
+		rows:   menu.Rows,
+	}
+
+	if menu.Stream == nil {
+		return in
+	}
+
+	r, w := io.Pipe()
+	in.rows = append([]Row{}, menu.Rows...)
+	go in.stream(in.Reader, menu.Stream, w, line)
+	in.Reader, in.pipe = r, r
+
+	return in
+}
+
+// stream is an internal implementation to write the streamed rows.
+// The rows are added before they are written, so every index the
+// backend outputs can be resolved. After the menu was closed, the
+// writes fail but the stream is still drained.
+func (in *rowInput) stream(static io.Reader, stream <-chan []Row, w *io.PipeWriter, line func(i int, row Row) string) {
+	_, err := io.Copy(w, static)
+	for rows := range stream {
+		in.mu.Lock()
+		offset := len(in.rows)
+		in.rows = append(in.rows, rows...)
+		in.mu.Unlock()
+
+		if err == nil {
+			_, err = io.WriteString(w, formatRows(rows, offset, line))
+		}
+	}
+
+	w.Close()
+}
+
+// Rows returns the rows which were written so far.
+func (in *rowInput) Rows() []Row {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	return in.rows
+}
+
+// Close stops writing streamed rows, e.g. when the menu was closed.
+func (in *rowInput) Close() {
+	if in.pipe != nil {
+		in.pipe.Close()
+	}
+}
+
+// formatRows is an internal implementation to format rows
+// as menu input starting at the given row index.
+func formatRows(rows []Row, offset int, line func(i int, row Row) string) string {
+	var b strings.Builder
+	for i, row := range rows {
+		fmt.Fprintln(&b, line(offset+i, row))
+	}
+	return b.String()
+}
+
+// displayTitle is an internal implementation to get
+// the title of a row as displayed by a backend.
+func displayTitle(row Row, markup bool) string {
+	if !markup {
+		return stripMarkup(row.Title)
+	}
+	return row.Title
+}
+
+// rowOptions is an internal implementation to append
+// the rofi row options (icon, meta, nonselectable, active
+// and urgent) to the title of a row.
+// See rofi-dmenu(5) for the format.
+func rowOptions(title string, row Row, icons bool) string {
+	var opts []string
+	if icons && row.Icon != "" {
+		opts = append(opts, "icon", row.Icon)
+	}
+
+	if row.Meta != "" {
+		opts = append(opts, "meta", row.Meta)
+	}
+
+	if row.NonSelectable {
+		opts = append(opts, "nonselectable", "true")
+	}
+
+	if row.Active {
+		opts = append(opts, "active", "true")
+	}
+
+	if row.Urgent {
+		opts = append(opts, "urgent", "true")
+	}
+
+	if len(opts) == 0 {
+		return title
+	}
+
+	return title + "\x00" + strings.Join(opts, "\x1f")
+}
+
+// matchRow is an internal implementation to map the output
+// of a backend without index support back to a row.
+// Rows with the same title always resolve to the first one.
+func matchRow(rows []Row, out string, markup bool) Selection {
+	for i, row := range rows {
+		if displayTitle(row, markup) == out {
+			return Selection{Index: i, Text: row.Title}
+		}
+	}
+
+	return Selection{Index: -1, Text: out}
+}
+
+// parseIndexed is an internal implementation to parse an
+// output line in the format "<index><sep><text>".
+func parseIndexed(rows []Row, line string, sep string) (Selection, bool) {
+	rawIndex, text, _ := strings.Cut(line, sep)
+
+	index, err := strconv.Atoi(rawIndex)
+	if err != nil {
+		return Selection{}, false
+	}
+
+	if index >= 0 && index < len(rows) {
+		return Selection{Index: index, Text: rows[index].Title}, true
+	}
+
+	return Selection{Index: -1, Text: text}, true
+}
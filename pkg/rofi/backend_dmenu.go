@@ -0,0 +1,159 @@
+package rofi
+
+import (
+	"strings"
+)
+
+// dmenuBackend shows menus using a dmenu compatible program
+// (dmenu, bemenu, wofi, fuzzel). These programs do not support
+// custom keybindings, so keybindings are ignored and only
+// selections and cancellations are reported.
+type dmenuBackend struct {
+	// command is the name of the menu program.
+	command string
+	// markup defines if the program can render pango markup.
+	markup bool
+	// index defines if the program outputs the index of the selection.
+	index bool
+	// icons defines if the program supports rofi style row icons.
+	icons bool
+	// args builds the program specific arguments for a menu.
+	args func(menu Menu) []string
+}
+
+// dmenuArgs is an internal implementation to build the
+// arguments shared by dmenu and bemenu.
+func dmenuArgs(menu Menu) []string {
+	args := []string{"-l", "15"}
+
+	if menu.Prompt != "" {
+		args = append(args, "-p", stripMarkup(menu.Prompt))
+	}
+
+	if menu.IgnoreCase {
+		args = append(args, "-i")
+	}
+
+	return args
+}
+
+func newDmenuBackend() Backend {
+	return &dmenuBackend{
+		command: BackendDmenu,
+		args:    dmenuArgs,
+	}
+}
+
+func newBemenuBackend() Backend {
+	return &dmenuBackend{
+		command: BackendBemenu,
+		args:    dmenuArgs,
+	}
+}
+
+func newWofiBackend() Backend {
+	return &dmenuBackend{
+		command: BackendWofi,
+		markup:  true,
+		args: func(menu Menu) []string {
+			// Disable the cache, so wofi does not reorder
+			// the rows by usage.
+			args := []string{"--dmenu", "--cache-file", "/dev/null"}
+
+			if menu.Prompt != "" {
+				args = append(args, "--prompt", stripMarkup(menu.Prompt))
+			}
+
+			if menu.IgnoreCase {
+				args = append(args, "--insensitive")
+			}
+
+			if menu.RenderMarkup {
+				args = append(args, "--allow-markup")
+			}
+
+			return args
+		},
+	}
+}
+
+func newFuzzelBackend() Backend {
+	return &dmenuBackend{
+		command: BackendFuzzel,
+		index:   true,
+		icons:   true,
+		args: func(menu Menu) []string {
+			args := []string{"--dmenu", "--index"}
+
+			if menu.Prompt != "" {
+				args = append(args, "--prompt", stripMarkup(menu.Prompt)+" ")
+			}
+
+			if menu.Filter != "" {
+				args = append(args, "--search", menu.Filter)
+			}
+
+			return args
+		},
+	}
+}
+
+func (b *dmenuBackend) Show(menu Menu) (Result, error) {
+	markup := b.markup && menu.RenderMarkup
+
+	input := newRowInput(menu, func(_ int, row Row) string {
+		title := displayTitle(row, markup)
+		if b.icons && menu.ShowIcons && row.Icon != "" {
+			return rowOptions(title, Row{Icon: row.Icon}, true)
+		}
+		return title
+	})
+	defer input.Close()
+
+	out, status, err := launcher.Launch(b.command, b.args(menu), input)
+	if err != nil {
+		return Result{}, err
+	}
+
+	rows := input.Rows()
+
+	if status != statusSelected {
+		return Result{Key: -1, Cancelled: true, Filter: menu.Filter}, nil
+	}
+
+	out = strings.TrimSpace(out)
+	selection := matchRow(rows, out, markup)
+	if b.index {
+		if s, ok := parseIndexed(rows, out, " "); ok {
+			selection = s
+		}
+	}
+
+	// dmenu compatible programs always accept custom input,
+	// so it is treated as cancellation when disabled.
+	if selection.Index < 0 && menu.NoCustom {
+		return Result{Key: -1, Cancelled: true, Filter: menu.Filter}, nil
+	}
+
+	return Result{
+		Selections: []Selection{selection},
+		Key:        -1,
+		Filter:     menu.Filter,
+	}, nil
+}
+
+func (b *dmenuBackend) Error(msg string) error {
+	menu := Menu{
+		Prompt: "Error",
+		Rows:   []Row{{Title: msg}},
+	}
+
+	_, _, err := launcher.Launch(
+		b.command,
+		b.args(menu),
+		newRowInput(menu, func(_ int, row Row) string {
+			return displayTitle(row, false)
+		}),
+	)
+	return err
+}
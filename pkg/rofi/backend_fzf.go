@@ -0,0 +1,154 @@
+package rofi
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// The exit status of fzf when no row matched the query.
+	fzfStatusNoMatch = 1
+	// The exit status of fzf when it was interrupted.
+	fzfStatusInterrupted = 130
+)
+
+// fzfBackend shows menus in the terminal using fzf.
+// Custom keybindings are reported using --expect.
+type fzfBackend struct{}
+
+// fzfKey is an internal implementation to convert a
+// rofi keybinding (e.g. Alt+Right) to a fzf key (e.g. alt-right).
+// Multiple rofi keys separated by comma are supported.
+func fzfKey(key string) string {
+	keys := strings.Split(key, ",")
+	for i, k := range keys {
+		k = strings.ToLower(strings.TrimSpace(k))
+		k = strings.ReplaceAll(k, "control+", "ctrl-")
+		k = strings.ReplaceAll(k, "mod1+", "alt-")
+		keys[i] = strings.ReplaceAll(k, "+", "-")
+	}
+
+	return strings.Join(keys, ",")
+}
+
+func (b *fzfBackend) parseArgs(menu Menu) ([]string, map[string]int) {
+	// Rows are prefixed with their index which is hidden
+	// from the user, so selections can be resolved by index.
+	args := []string{
+		"--layout", "reverse",
+		"--no-sort",
+		"--delimiter", "\t",
+		"--with-nth", "2..",
+	}
+
+	if menu.Prompt != "" {
+		args = append(args, "--prompt", stripMarkup(menu.Prompt)+"> ")
+	}
+
+	if menu.Message != "" {
+		args = append(args, "--header", stripMarkup(menu.Message))
+	}
+
+	if menu.Filter != "" {
+		args = append(args, "--query", menu.Filter)
+	}
+
+	if menu.IgnoreCase {
+		args = append(args, "-i")
+	}
+
+	// The query is always printed, so it can be used
+	// as custom input and restored as filter.
+	args = append(args, "--print-query")
+
+	if menu.MultiSelect {
+		args = append(args, "--multi")
+	}
+
+	keys := map[string]int{}
+	if len(menu.Keybindings) > 0 {
+		expect := make([]string, len(menu.Keybindings))
+		for i, key := range menu.Keybindings {
+			expect[i] = fzfKey(key)
+			for _, k := range strings.Split(expect[i], ",") {
+				keys[k] = i
+			}
+		}
+
+		args = append(args, "--expect", strings.Join(expect, ","))
+	}
+
+	return args, keys
+}
+
+func (b *fzfBackend) Show(menu Menu) (Result, error) {
+	args, keys := b.parseArgs(menu)
+
+	input := newRowInput(menu, func(i int, row Row) string {
+		return fmt.Sprintf("%d\t%s", i, displayTitle(row, false))
+	})
+	defer input.Close()
+
+	out, status, err := launcher.Launch(BackendFzf, args, input)
+	if err != nil {
+		return Result{}, err
+	}
+
+	rows := input.Rows()
+
+	if status != statusSelected && status != fzfStatusNoMatch {
+		if status == fzfStatusInterrupted {
+			return Result{Key: -1, Cancelled: true}, nil
+		}
+
+		return Result{}, fmt.Errorf("received invalid fzf status: %d", status)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	next := func() string {
+		if len(lines) == 0 {
+			return ""
+		}
+		line := lines[0]
+		lines = lines[1:]
+		return line
+	}
+
+	query := next()
+	res := Result{Key: -1, Filter: query}
+
+	if len(menu.Keybindings) > 0 {
+		if i, ok := keys[next()]; ok {
+			res.Key = i
+		}
+	}
+
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		selection, ok := parseIndexed(rows, line, "\t")
+		if !ok {
+			return Result{}, fmt.Errorf("received invalid fzf output: %s", line)
+		}
+
+		res.Selections = append(res.Selections, selection)
+	}
+
+	if status == fzfStatusNoMatch || len(res.Selections) == 0 {
+		if menu.NoCustom {
+			return Result{Key: -1, Cancelled: true}, nil
+		}
+
+		res.Selections = []Selection{{Index: -1, Text: query}}
+	}
+
+	return res, nil
+}
+
+func (b *fzfBackend) Error(msg string) error {
+	_, err := fmt.Fprintf(os.Stderr, "spofi: %s\n", stripMarkup(msg))
+	return err
+}
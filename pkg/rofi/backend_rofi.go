@@ -0,0 +1,151 @@
+package rofi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// The exit status of rofi when a entry was selected.
+	statusSelected = 0
+	// The exit status of rofi when the selection was cancelled.
+	statusCancelled = 1
+	// The exit status of rofi when the first custom keybinding was pressed.
+	statusKbCustom = 10
+)
+
+// rofiFormat is the output format of rofi: index, filter and
+// text separated by tabs.
+const rofiFormat = "i\tf\ts"
+
+// rofiBackend shows menus using rofi in dmenu mode.
+type rofiBackend struct{}
+
+func (b *rofiBackend) parseArgs(menu Menu) []string {
+	// Output the index, the filter and the text of the selection,
+	// so rows with the same title can be distinguished, custom
+	// input (index -1) is still available and the filter can be
+	// restored when the menu is shown again.
+	args := []string{
+		"-dmenu",
+		"-format", rofiFormat,
+	}
+
+	if customTheme != "" {
+		args = append(args, "-theme")
+		args = append(args, customTheme)
+	}
+
+	if menu.Prompt != "" {
+		args = append(args, "-p")
+		args = append(args, menu.Prompt)
+	}
+
+	if menu.Message != "" {
+		args = append(args, "-mesg")
+		args = append(args, menu.Message)
+	}
+
+	if menu.Filter != "" {
+		args = append(args, "-filter")
+		args = append(args, menu.Filter)
+	}
+
+	if menu.IgnoreCase {
+		args = append(args, "-i")
+	}
+
+	if menu.NoCustom {
+		args = append(args, "-no-custom")
+	}
+
+	if menu.RenderMarkup {
+		args = append(args, "-markup-rows")
+	}
+
+	if menu.MultiSelect {
+		args = append(args, "-multi-select")
+	}
+
+	if menu.ShowIcons {
+		args = append(args, "-show-icons")
+	}
+
+	for i, key := range menu.Keybindings {
+		args = append(args, fmt.Sprintf("-kb-custom-%d", i+1))
+		args = append(args, key)
+	}
+
+	args = append(args, "-selected-row")
+	args = append(args, strconv.Itoa(menu.SelectedRow))
+
+	return args
+}
+
+func (b *rofiBackend) Show(menu Menu) (Result, error) {
+	input := newRowInput(menu, func(_ int, row Row) string {
+		return rowOptions(row.Title, row, menu.ShowIcons)
+	})
+	defer input.Close()
+
+	out, status, err := launcher.Launch(BackendRofi, b.parseArgs(menu), input)
+	if err != nil {
+		return Result{}, err
+	}
+
+	rows := input.Rows()
+
+	res := Result{
+		Key:    -1,
+		Filter: menu.Filter,
+	}
+
+	// The lines are not trimmed, as empty custom
+	// input results in a line with only separators.
+	for _, line := range strings.Split(out, "\n") {
+		if line == "" {
+			continue
+		}
+
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			return Result{}, fmt.Errorf("received invalid rofi output: %s", line)
+		}
+
+		selection, ok := parseIndexed(rows, fields[0]+"\t"+fields[2], "\t")
+		if !ok {
+			return Result{}, fmt.Errorf("received invalid rofi output: %s", line)
+		}
+
+		res.Filter = fields[1]
+		res.Selections = append(res.Selections, selection)
+	}
+
+	switch {
+	case status == statusSelected:
+		return res, nil
+	case status == statusCancelled:
+		res.Cancelled = true
+		return res, nil
+	case status >= statusKbCustom:
+		res.Key = status - statusKbCustom
+		return res, nil
+	}
+
+	return Result{}, fmt.Errorf("received invalid rofi status: %d", status)
+}
+
+func (b *rofiBackend) Error(msg string) error {
+	args := []string{
+		"-e", msg,
+	}
+
+	if customTheme != "" {
+		args = append(args, "-theme")
+		args = append(args, customTheme)
+	}
+
+	_, _, err := launcher.Launch(BackendRofi, args, nil)
+	return err
+}
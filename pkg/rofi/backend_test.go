@@ -0,0 +1,478 @@
+package rofi
+
+import (
+	"io"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+type cannedLauncher struct {
+	out    string
+	status int
+
+	name string
+	args []string
+	rows string
+}
+
+func (l *cannedLauncher) Launch(name string, args []string, input io.Reader) (string, int, error) {
+	l.name = name
+	l.args = args
+	if input != nil {
+		raw, _ := io.ReadAll(input)
+		l.rows = string(raw)
+	}
+	return l.out, l.status, nil
+}
+
+func useLauncher(t *testing.T, l Launcher) {
+	t.Cleanup(func() {
+		SetLauncher(ExecLauncher{})
+	})
+	SetLauncher(l)
+}
+
+func TestFzfKey(t *testing.T) {
+	tests := map[string]string{
+		"Alt+d":           "alt-d",
+		"Alt+Right":       "alt-right",
+		"Alt+space":       "alt-space",
+		"Control+x":       "ctrl-x",
+		"Mod1+q,Alt+w":    "alt-q,alt-w",
+		"Shift+Left":      "shift-left",
+		"Control+Shift+a": "ctrl-shift-a",
+	}
+
+	for in, want := range tests {
+		if got := fzfKey(in); got != want {
+			t.Errorf("fzfKey(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestBackends(t *testing.T) {
+	menu := Menu{
+		Prompt:       "<b>Tracks</b>",
+		NoCustom:     true,
+		RenderMarkup: true,
+		Keybindings:  []string{"Alt+d", "Alt+Right"},
+		Rows:         []Row{{Title: ".."}, {Title: "Rock &amp; Roll"}, {Title: "<i>Other</i>"}},
+	}
+
+	tests := []struct {
+		name    string
+		backend string
+		menu    Menu
+		out     string
+		status  int
+		want    Result
+	}{
+		{
+			name:    "rofi selection",
+			backend: BackendRofi,
+			menu:    menu,
+			out:     "2\t\t<i>Other</i>\n",
+			want:    Result{Selections: []Selection{{Index: 2, Text: "<i>Other</i>"}}, Key: -1},
+		},
+		{
+			name:    "rofi custom key",
+			backend: BackendRofi,
+			menu:    menu,
+			out:     "2\t\t<i>Other</i>\n",
+			status:  11,
+			want:    Result{Selections: []Selection{{Index: 2, Text: "<i>Other</i>"}}, Key: 1},
+		},
+		{
+			name:    "rofi multi select",
+			backend: BackendRofi,
+			menu:    menu,
+			out:     "1\t\tRock &amp; Roll\n2\t\t<i>Other</i>\n",
+			want: Result{Selections: []Selection{
+				{Index: 1, Text: "Rock &amp; Roll"},
+				{Index: 2, Text: "<i>Other</i>"},
+			}, Key: -1},
+		},
+		{
+			name:    "rofi custom input",
+			backend: BackendRofi,
+			menu:    Menu{Rows: []Row{{Title: "a"}}},
+			out:     "-1\tmy search\tmy search\n",
+			want:    Result{Selections: []Selection{{Index: -1, Text: "my search"}}, Key: -1, Filter: "my search"},
+		},
+		{
+			name:    "rofi duplicate titles",
+			backend: BackendRofi,
+			menu:    Menu{Rows: []Row{{Title: "Intro"}, {Title: "Intro"}}},
+			out:     "1\t\tIntro\n",
+			want:    Result{Selections: []Selection{{Index: 1, Text: "Intro"}}, Key: -1},
+		},
+		{
+			name:    "rofi cancelled",
+			backend: BackendRofi,
+			menu:    menu,
+			status:  1,
+			want:    Result{Key: -1, Cancelled: true},
+		},
+		{
+			name:    "dmenu maps stripped markup to row",
+			backend: BackendDmenu,
+			menu:    menu,
+			out:     "Rock & Roll\n",
+			want:    Result{Selections: []Selection{{Index: 1, Text: "Rock &amp; Roll"}}, Key: -1},
+		},
+		{
+			name:    "dmenu custom input is cancelled with no custom",
+			backend: BackendDmenu,
+			menu:    menu,
+			out:     "something\n",
+			want:    Result{Key: -1, Cancelled: true},
+		},
+		{
+			name:    "wofi keeps markup",
+			backend: BackendWofi,
+			menu:    menu,
+			out:     "<i>Other</i>\n",
+			want:    Result{Selections: []Selection{{Index: 2, Text: "<i>Other</i>"}}, Key: -1},
+		},
+		{
+			name:    "fuzzel index",
+			backend: BackendFuzzel,
+			menu:    menu,
+			out:     "1\n",
+			want:    Result{Selections: []Selection{{Index: 1, Text: "Rock &amp; Roll"}}, Key: -1},
+		},
+		{
+			name:    "fuzzel cancelled",
+			backend: BackendFuzzel,
+			menu:    menu,
+			status:  2,
+			want:    Result{Key: -1, Cancelled: true},
+		},
+		{
+			name:    "fzf expected key",
+			backend: BackendFzf,
+			menu:    menu,
+			out:     "\nalt-right\n2\tOther\n",
+			want:    Result{Selections: []Selection{{Index: 2, Text: "<i>Other</i>"}}, Key: 1},
+		},
+		{
+			name:    "fzf enter",
+			backend: BackendFzf,
+			menu:    menu,
+			out:     "\n\n2\tOther\n",
+			want:    Result{Selections: []Selection{{Index: 2, Text: "<i>Other</i>"}}, Key: -1},
+		},
+		{
+			name:    "fzf custom query",
+			backend: BackendFzf,
+			menu:    Menu{Rows: []Row{{Title: ".."}}},
+			out:     "my search\n",
+			status:  fzfStatusNoMatch,
+			want:    Result{Selections: []Selection{{Index: -1, Text: "my search"}}, Key: -1, Filter: "my search"},
+		},
+		{
+			name:    "fzf multi select with key",
+			backend: BackendFzf,
+			menu:    menu,
+			out:     "rock\nalt-d\n1\tRock & Roll\n2\tOther\n",
+			want: Result{Selections: []Selection{
+				{Index: 1, Text: "Rock &amp; Roll"},
+				{Index: 2, Text: "<i>Other</i>"},
+			}, Key: 0, Filter: "rock"},
+		},
+		{
+			name:    "fzf interrupted",
+			backend: BackendFzf,
+			menu:    menu,
+			status:  fzfStatusInterrupted,
+			want:    Result{Key: -1, Cancelled: true},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			l := &cannedLauncher{out: tc.out, status: tc.status}
+			useLauncher(t, l)
+
+			b, err := NewBackend(tc.backend)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := b.Show(tc.menu)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if l.name != tc.backend {
+				t.Fatalf("expected %s to be launched, got %s", tc.backend, l.name)
+			}
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRowOptions(t *testing.T) {
+	l := &cannedLauncher{out: "1\t\tb\n"}
+	useLauncher(t, l)
+
+	app := App{
+		Rows: []Row{
+			{Title: "header", NonSelectable: true},
+			{Title: "b", Value: "2", Icon: "/tmp/b.jpg", Meta: "album 2001", Active: true},
+			{Title: "c", Value: "3", Urgent: true},
+		},
+	}
+
+	evt, err := app.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if evt.(SelectedEvent).Selection.Value != "2" {
+		t.Fatalf("unexpected event %+v", evt)
+	}
+
+	wantRows := "header\x00nonselectable\x1ftrue\n" +
+		"b\x00icon\x1f/tmp/b.jpg\x1fmeta\x1falbum 2001\x1factive\x1ftrue\n" +
+		"c\x00urgent\x1ftrue\n"
+	if l.rows != wantRows {
+		t.Fatalf("unexpected rows %q", l.rows)
+	}
+
+	if !reflect.DeepEqual(l.args[len(l.args)-3:], []string{"-show-icons", "-selected-row", "0"}) {
+		t.Fatalf("expected -show-icons in args %v", l.args)
+	}
+
+	// Backends without support for row options only show the titles.
+	SetBackend(newDmenuBackend())
+	t.Cleanup(func() {
+		SetBackend(&rofiBackend{})
+	})
+
+	l.out = "c\n"
+	if _, err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if l.rows != "header\nb\nc\n" {
+		t.Fatalf("unexpected rows %q", l.rows)
+	}
+}
+
+func TestNewBackendUnknown(t *testing.T) {
+	if _, err := NewBackend("unknown"); err == nil {
+		t.Fatal("expected an error for an unknown backend")
+	}
+}
+
+func TestAppRun(t *testing.T) {
+	l := &cannedLauncher{out: "2\t\tb\n", status: 10}
+	useLauncher(t, l)
+
+	app := App{
+		ShowBack:    true,
+		Keybindings: []string{"Alt+d"},
+		Rows: []Row{
+			{Title: "a", Value: "1"},
+			{Title: "b", Value: "2"},
+		},
+	}
+
+	evt, err := app.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := KeyEvent{
+		Selection:  Row{Title: "b", Value: "2"},
+		Selections: []Row{{Title: "b", Value: "2"}},
+		Index:      1,
+		Key:        "Alt+d",
+	}
+	if !reflect.DeepEqual(evt, want) {
+		t.Fatalf("expected %+v, got %+v", want, evt)
+	}
+
+	if l.rows != "..\na\nb\n" {
+		t.Fatalf("unexpected rows %q", l.rows)
+	}
+
+	l.out, l.status = "0\t\t..\n", 0
+	evt, err = app.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := evt.(BackEvent); !ok {
+		t.Fatalf("expected back event, got %+v", evt)
+	}
+
+	app.MultiSelect = true
+	l.out, l.status = "0\t\t..\n2\t\tb\n1\t\ta\n", 0
+	evt, err = app.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantMulti := MultiSelectedEvent{Selections: []Row{
+		{Title: "b", Value: "2"},
+		{Title: "a", Value: "1"},
+	}}
+	if !reflect.DeepEqual(evt, wantMulti) {
+		t.Fatalf("expected %+v, got %+v", wantMulti, evt)
+	}
+
+	if l.args[3] != "-multi-select" {
+		t.Fatalf("expected -multi-select in args %v", l.args)
+	}
+
+	app.MultiSelect = false
+	app.Rows = []Row{
+		{Title: "Intro", Value: "1"},
+		{Title: "Intro", Value: "2"},
+	}
+	l.out, l.status = "2\t\tIntro\n", 0
+	evt, err = app.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantSelected := SelectedEvent{Selection: Row{Title: "Intro", Value: "2"}, Index: 1}
+	if !reflect.DeepEqual(evt, wantSelected) {
+		t.Fatalf("expected %+v, got %+v", wantSelected, evt)
+	}
+
+	l.out = "-1\tsomething\tsomething\n"
+	evt, err = app.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantCustom := SelectedEvent{Selection: Row{Title: "something"}, Index: -1}
+	if !reflect.DeepEqual(evt, wantCustom) {
+		t.Fatalf("expected %+v, got %+v", wantCustom, evt)
+	}
+
+	// The filter is restored when the menu is shown again.
+	l.status = 1
+	if _, err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(l.args[3:5], []string{"-filter", "something"}) {
+		t.Fatalf("expected the filter to be restored, got args %v", l.args)
+	}
+}
+
+func TestAppRunLoad(t *testing.T) {
+	l := &cannedLauncher{out: "3\t\tc\n", status: 0}
+	useLauncher(t, l)
+
+	app := App{
+		ShowBack: true,
+		Rows:     []Row{{Title: "a", Value: "1"}},
+		Load: func(add func(rows ...Row)) {
+			add(Row{Title: "b", Value: "2"})
+			add(Row{Title: "c", Value: "3"})
+		},
+	}
+
+	evt, err := app.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := SelectedEvent{Selection: Row{Title: "c", Value: "3"}, Index: 2}
+	if !reflect.DeepEqual(evt, want) {
+		t.Fatalf("expected %+v, got %+v", want, evt)
+	}
+
+	if l.rows != "..\na\nb\nc\n" {
+		t.Fatalf("unexpected rows %q", l.rows)
+	}
+}
+
+// sequenceLauncher returns the outputs in order
+// and records the rows of every launch.
+type sequenceLauncher struct {
+	outs []string
+	rows []string
+}
+
+func (l *sequenceLauncher) Launch(name string, args []string, input io.Reader) (string, int, error) {
+	raw, _ := io.ReadAll(input)
+	l.rows = append(l.rows, string(raw))
+
+	out := l.outs[0]
+	l.outs = l.outs[1:]
+	return out, 0, nil
+}
+
+func TestAppRunNonSelectable(t *testing.T) {
+	l := &sequenceLauncher{outs: []string{"0\t\theader\n", "1\t\tb\n"}}
+	useLauncher(t, l)
+
+	loads := 0
+	app := App{
+		Rows: []Row{{Title: "header", NonSelectable: true}},
+		Load: func(add func(rows ...Row)) {
+			loads++
+			add(Row{Title: "b", Value: "2"})
+		},
+	}
+
+	evt, err := app.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := SelectedEvent{Selection: Row{Title: "b", Value: "2"}, Index: 1}
+	if !reflect.DeepEqual(evt, want) {
+		t.Fatalf("expected %+v, got %+v", want, evt)
+	}
+
+	// The menu is shown again with the loaded rows,
+	// without running the loader again.
+	if loads != 1 {
+		t.Fatalf("expected the loader to run once, got %d", loads)
+	}
+
+	if len(l.rows) != 2 || l.rows[1] != "header\x00nonselectable\x1ftrue\nb\n" {
+		t.Fatalf("unexpected rows %q", l.rows)
+	}
+}
+
+func TestExecLauncherStream(t *testing.T) {
+	if _, err := exec.LookPath("head"); err != nil {
+		t.Skip("head is not available")
+	}
+
+	stream := make(chan []Row)
+	input := newRowInput(Menu{
+		Rows:   []Row{{Title: "a"}},
+		Stream: stream,
+	}, func(_ int, row Row) string {
+		return row.Title
+	})
+
+	// The program exits before the stream is complete,
+	// so launching must not wait for the stream.
+	out, _, err := ExecLauncher{}.Launch("head", []string{"-n", "1"}, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input.Close()
+
+	if out != "a\n" {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	// The stream is still drained after the menu was closed.
+	stream <- []Row{{Title: "b"}}
+	close(stream)
+}
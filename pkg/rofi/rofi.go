@@ -1,20 +1,15 @@
 package rofi
 
 import (
-	"bytes"
 	"fmt"
-	"os/exec"
-	"strconv"
-	"strings"
+	"sync"
 )
 
 const (
-	// The exit status of rofi when a entry was selected.
-	statusSelected = 0
-	// The exit status of rofi when the selection was cancelled.
-	statusCancelled = 1
-	// The exit status of rofi when the first custom keybinding was pressed.
-	statusKbCustom = 10
+	// indexCustom is the index of custom input.
+	indexCustom = -1
+	// indexBack is the index of the back option.
+	indexBack = -2
 )
 
 var (
@@ -33,6 +28,15 @@ type Event interface{}
 // SelectedEvent appears when the user selected a entry.
 type SelectedEvent struct {
 	Selection Row
+	// Index is the index of the selection in the rows
+	// of the app or -1 when the user entered custom input.
+	Index int
+}
+
+// MultiSelectedEvent appears when the user
+// accepted multiple marked entries.
+type MultiSelectedEvent struct {
+	Selections []Row
 }
 
 // CancelledEvent appears when the user cancels a selection.
@@ -44,13 +48,29 @@ type BackEvent struct{}
 // KeyEvent appears whe the user presses a custom keybinding.
 type KeyEvent struct {
 	Selection Row
-	Key       string
+	// Selections are all marked entries when multi select
+	// is enabled, otherwise it only contains the selection.
+	Selections []Row
+	// Index is the index of the selection in the rows
+	// of the app or -1 when the user entered custom input.
+	Index int
+	Key   string
 }
 
 // Row represents the entries of a rofi menu.
 type Row struct {
 	Title string
 	Value string
+	// Icon is the name or path of the row icon.
+	Icon string
+	// Meta are hidden search keywords of the row.
+	Meta string
+	// NonSelectable prevents the row from being selected.
+	NonSelectable bool
+	// Active highlights the row as active.
+	Active bool
+	// Urgent highlights the row as urgent.
+	Urgent bool
 }
 
 // App represent a rofi app.
@@ -59,7 +79,9 @@ type App struct {
 	Prompt string
 	// Message is the message of the rofi menu.
 	Message string
-	// Filter is the filter of the rofi menu.
+	// Filter is the filter of the rofi menu. It is updated
+	// with the user input after each run, so the filter is
+	// restored when the menu is shown again.
 	Filter string
 	// IgnoreCase defines the case-insensitivity of the search.
 	IgnoreCase bool
@@ -67,162 +89,255 @@ type App struct {
 	NoCustom bool
 	// RenderMarkup enables markup rendering.
 	RenderMarkup bool
+	// MultiSelect allows to mark and select multiple entries.
+	MultiSelect bool
 	// ShowBack shows the back (..) option.
 	ShowBack bool
 	// Keybindings are the custom keybindings of the rofi menu.
 	Keybindings []string
 	// Rows are the rows of the rofi menu.
 	Rows []Row
+	// Load optionally loads more rows while the menu is shown,
+	// e.g. a large list which is fetched page by page. The
+	// rows passed to add are appended to the menu rows.
+	Load func(add func(rows ...Row))
 
 	previousSelection int
 }
 
-func (a *App) parseArgs() []string {
-	args := []string{
-		"-dmenu",
+// buildMenu builds the backend independent menu description
+// of the app for the given rows. Loading indicates that more
+// rows are streamed while the menu is shown.
+func (a *App) buildMenu(entries []Row, loading bool) Menu {
+	selected := a.previousSelection
+	// Skip back button and select next entry
+	// when entries are available (or loaded).
+	if a.ShowBack && (len(entries) > 0 || loading) {
+		selected++
 	}
 
-	if customTheme != "" {
-		args = append(args, "-theme")
-		args = append(args, customTheme)
+	rows := make([]Row, 0, len(entries)+1)
+	if a.ShowBack {
+		rows = append(rows, Row{Title: ".."})
 	}
 
-	if a.Prompt != "" {
-		args = append(args, "-p")
-		args = append(args, a.Prompt)
+	showIcons := false
+	for _, entry := range entries {
+		rows = append(rows, entry)
+		showIcons = showIcons || entry.Icon != ""
 	}
 
-	if a.Message != "" {
-		args = append(args, "-mesg")
-		args = append(args, a.Message)
+	return Menu{
+		Prompt:       a.Prompt,
+		Message:      a.Message,
+		Filter:       a.Filter,
+		IgnoreCase:   a.IgnoreCase,
+		NoCustom:     a.NoCustom,
+		RenderMarkup: a.RenderMarkup,
+		MultiSelect:  a.MultiSelect,
+		ShowIcons:    showIcons,
+		Keybindings:  a.Keybindings,
+		Rows:         rows,
+		SelectedRow:  selected,
 	}
+}
 
-	if a.Filter != "" {
-		args = append(args, "-filter")
-		args = append(args, a.Filter)
+// resolveSelection maps a backend selection to a row of the app.
+// The returned index is the index in the app rows, -1 for
+// custom input and -2 for the back option.
+func (a *App) resolveSelection(rows []Row, selection Selection) (Row, int) {
+	index := selection.Index
+	if index >= 0 && a.ShowBack {
+		index--
 	}
 
-	if a.IgnoreCase {
-		args = append(args, "-i")
+	if index == -1 && a.ShowBack && selection.Index == 0 {
+		return Row{Title: ".."}, indexBack
 	}
 
-	if a.NoCustom {
-		args = append(args, "-no-custom")
+	if index < 0 || index >= len(rows) {
+		return Row{Title: selection.Text}, indexCustom
 	}
 
-	if a.RenderMarkup {
-		args = append(args, "-markup-rows")
-	}
+	return rows[index], index
+}
+
+// loader is an internal implementation to run the loader of an
+// app once. The loaded rows are kept, so the menu can be shown
+// again while the rows are still loaded.
+type loader struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	rows []Row
+	done bool
+}
 
-	for i, key := range a.Keybindings {
-		args = append(args, fmt.Sprintf("-kb-custom-%d", i+1))
-		args = append(args, key)
+// load is an internal implementation to start the loader of the app.
+func (a *App) load() *loader {
+	l := &loader{
+		rows: append([]Row{}, a.Rows...),
+		done: a.Load == nil,
 	}
+	l.cond = sync.NewCond(&l.mu)
 
-	selected := a.previousSelection
-	// Skip back button and select next entry
-	// when entries are available.
-	if a.ShowBack && len(a.Rows) > 0 {
-		selected++
+	if a.Load == nil {
+		return l
 	}
 
-	args = append(args, "-selected-row")
-	args = append(args, strconv.Itoa(selected))
+	load := a.Load
+	go func() {
+		load(func(loaded ...Row) {
+			l.mu.Lock()
+			l.rows = append(l.rows, loaded...)
+			l.mu.Unlock()
+			l.cond.Broadcast()
+		})
+
+		l.mu.Lock()
+		l.done = true
+		l.mu.Unlock()
+		l.cond.Broadcast()
+	}()
+
+	return l
+}
+
+// Rows returns the rows which were loaded so far.
+func (l *loader) Rows() []Row {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 
-	return args
+	return l.rows
 }
 
-// findSelection searches a row in the known app rows.
-// If no row was found then a new
-// row with only `Title` will be returned.
-func (a *App) findSelection(title string) (Row, int) {
-	var selection *Row
-	index := 0
-	for i, entry := range a.Rows {
-		if entry.Title == title {
-			selection = &entry
-			index = i
-			break
-		}
+// stream returns the rows which were loaded so far and a
+// channel which streams the rows loaded afterwards. The
+// channel is nil when the loader already finished.
+func (l *loader) stream() ([]Row, <-chan []Row) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.done {
+		return l.rows, nil
 	}
 
-	if selection == nil {
-		selection = &Row{
-			Title: title,
+	stream := make(chan []Row)
+	go func(offset int) {
+		defer close(stream)
+
+		for {
+			l.mu.Lock()
+			for len(l.rows) == offset && !l.done {
+				l.cond.Wait()
+			}
+			loaded, done := l.rows[offset:], l.done
+			l.mu.Unlock()
+
+			if len(loaded) == 0 && done {
+				return
+			}
+
+			stream <- loaded
+			offset += len(loaded)
 		}
-	}
+	}(len(l.rows))
 
-	return *selection, index
+	return l.rows, stream
 }
 
 // Run runs the rofi menu and returns a Event.
 func (a *App) Run() (Event, error) {
-	args := a.parseArgs()
+	l := a.load()
+
+	var (
+		res        Result
+		selections []Row
+		indexes    []int
+	)
+
+	// Not every backend supports non-selectable rows, so they
+	// are ignored here. When only non-selectable rows were
+	// selected, the menu is shown again with the loaded rows.
+	for len(selections) == 0 {
+		rows, stream := l.stream()
+		menu := a.buildMenu(rows, stream != nil)
+		menu.Stream = stream
+
+		var err error
+		res, err = backend.Show(menu)
+		if err != nil {
+			return nil, err
+		}
 
-	cmd := exec.Command("rofi", args...)
-	buf := bytes.NewBufferString("")
+		if res.Cancelled {
+			return CancelledEvent{}, nil
+		}
 
-	if a.ShowBack {
-		fmt.Fprintln(buf, "..")
-	}
+		a.Filter = res.Filter
 
-	for _, entry := range a.Rows {
-		fmt.Fprintln(buf, entry.Title)
-	}
+		if len(res.Selections) == 0 {
+			res.Selections = []Selection{{Index: -1}}
+		}
 
-	cmd.Stdin = buf
-	out, err := cmd.CombinedOutput()
+		for _, s := range res.Selections {
+			row, index := a.resolveSelection(l.Rows(), s)
+			if row.NonSelectable {
+				continue
+			}
 
-	status := 0
-	if err != nil {
-		if exiterr, ok := err.(*exec.ExitError); ok {
-			status = exiterr.ExitCode()
-		} else {
-			return nil, err
+			selections = append(selections, row)
+			indexes = append(indexes, index)
 		}
 	}
 
-	key := strings.TrimSpace(string(out))
-	selection, index := a.findSelection(key)
-	a.previousSelection = index
+	// Ignore a marked back option when other entries are selected.
+	if len(selections) > 1 {
+		filtered, filteredIndexes := selections[:0:0], indexes[:0:0]
+		for i, index := range indexes {
+			if index != indexBack {
+				filtered = append(filtered, selections[i])
+				filteredIndexes = append(filteredIndexes, index)
+			}
+		}
+		selections, indexes = filtered, filteredIndexes
+	}
 
-	if status == statusSelected {
-		if a.ShowBack && selection.Title == ".." && index == 0 {
-			return BackEvent{}, nil
+	selection, index := selections[0], indexes[0]
+	if index >= 0 {
+		a.previousSelection = index
+	}
+
+	if res.Key >= 0 {
+		if res.Key >= len(a.Keybindings) {
+			return nil, fmt.Errorf("received invalid keybinding: %d", res.Key)
 		}
 
-		return SelectedEvent{
-			Selection: selection,
+		return KeyEvent{
+			Selection:  selection,
+			Selections: selections,
+			Index:      index,
+			Key:        a.Keybindings[res.Key],
 		}, nil
 	}
 
-	if status == statusCancelled {
-		return CancelledEvent{}, nil
+	if len(selections) > 1 {
+		return MultiSelectedEvent{
+			Selections: selections,
+		}, nil
 	}
 
-	if status >= statusKbCustom {
-		return KeyEvent{
-			Selection: selection,
-			Key:       a.Keybindings[status-statusKbCustom],
-		}, nil
+	if index == indexBack {
+		return BackEvent{}, nil
 	}
 
-	return nil, fmt.Errorf("received invalid rofi status: %d", status)
+	return SelectedEvent{
+		Selection: selection,
+		Index:     index,
+	}, nil
 }
 
 // Error displays a rofi error view
 // with a given message.
 func Error(msg string) error {
-	args := []string{
-		"-e", msg,
-	}
-
-	if customTheme != "" {
-		args = append(args, "-theme")
-		args = append(args, customTheme)
-	}
-
-	cmd := exec.Command("rofi", args...)
-	_, err := cmd.CombinedOutput()
-	return err
+	return backend.Error(msg)
 }
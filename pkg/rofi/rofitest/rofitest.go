@@ -0,0 +1,340 @@
+// Package rofitest provides a scripted rofi launcher
+// to test rofi views without the rofi binary.
+package rofitest
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	statusSelected  = 0
+	statusCancelled = 1
+	statusKbCustom  = 10
+)
+
+type stepKind int
+
+const (
+	stepSelect stepKind = iota
+	stepSelectIndex
+	stepInput
+	stepKey
+	stepCancel
+	stepSelectMany
+)
+
+// Step represents a scripted user interaction with a menu.
+type Step struct {
+	kind   stepKind
+	title  string
+	titles []string
+	index  int
+	key    string
+}
+
+// Select selects the row with the given title.
+func Select(title string) Step {
+	return Step{kind: stepSelect, title: title}
+}
+
+// SelectIndex selects the row at the given index.
+func SelectIndex(index int) Step {
+	return Step{kind: stepSelectIndex, index: index}
+}
+
+// SelectMany marks the rows with the given titles
+// and accepts them (requires -multi-select).
+func SelectMany(titles ...string) Step {
+	return Step{kind: stepSelectMany, titles: titles}
+}
+
+// Back selects the back (..) option.
+func Back() Step {
+	return Select("..")
+}
+
+// Input enters a custom text and accepts it.
+func Input(text string) Step {
+	return Step{kind: stepInput, title: text}
+}
+
+// Key presses a custom keybinding while the
+// row with the given title is highlighted.
+func Key(key string, title string) Step {
+	return Step{kind: stepKey, key: key, titles: []string{title}}
+}
+
+// KeyMany presses a custom keybinding while the rows with
+// the given titles are marked (requires -multi-select).
+func KeyMany(key string, titles ...string) Step {
+	return Step{kind: stepKey, key: key, titles: titles}
+}
+
+// Cancel cancels the menu.
+func Cancel() Step {
+	return Step{kind: stepCancel}
+}
+
+// Menu represents a menu which was shown by rofi.
+type Menu struct {
+	// Command is the name of the launched menu program.
+	Command string
+	// Args are the raw arguments rofi was called with.
+	Args []string
+	// Prompt is the prompt (-p) of the menu.
+	Prompt string
+	// Message is the message (-mesg) of the menu.
+	Message string
+	// Filter is the filter (-filter) of the menu.
+	Filter string
+	// Keybindings are the custom keybindings (-kb-custom-N) of the menu.
+	Keybindings []string
+	// SelectedRow is the initially selected row (-selected-row).
+	SelectedRow int
+	// MultiSelect is true when multi select (-multi-select) is enabled.
+	MultiSelect bool
+	// Format is the output format (-format) of the menu.
+	Format string
+	// ShowIcons is true when icons (-show-icons) are enabled.
+	ShowIcons bool
+	// Rows are the titles of the rows passed to rofi.
+	Rows []string
+	// RowOptions are the row options (e.g. icon or meta) of each row.
+	RowOptions []map[string]string
+}
+
+// Launcher is a rofi.Launcher which replays a sequence of
+// steps and records every menu and error it received.
+// When the script is exhausted, all further menus are cancelled
+// and the test fails.
+type Launcher struct {
+	t testing.TB
+
+	mu     sync.Mutex
+	steps  []Step
+	menus  []Menu
+	errors []string
+}
+
+// New creates a new scripted launcher replaying the given steps.
+func New(t testing.TB, steps ...Step) *Launcher {
+	return &Launcher{
+		t:     t,
+		steps: steps,
+	}
+}
+
+// Menus returns all menus shown so far.
+func (l *Launcher) Menus() []Menu {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]Menu{}, l.menus...)
+}
+
+// Errors returns all error messages shown so far.
+func (l *Launcher) Errors() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]string{}, l.errors...)
+}
+
+// Remaining returns the number of steps which were not replayed yet.
+func (l *Launcher) Remaining() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.steps)
+}
+
+func (l *Launcher) Launch(name string, args []string, input io.Reader) (string, int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i, arg := range args {
+		if arg == "-e" && i+1 < len(args) {
+			l.errors = append(l.errors, args[i+1])
+			return "", statusSelected, nil
+		}
+	}
+
+	menu := parseMenu(args, input)
+	menu.Command = name
+	l.menus = append(l.menus, menu)
+
+	if len(l.steps) == 0 {
+		l.t.Errorf("rofitest: unexpected menu %q with rows %v", menu.Prompt, menu.Rows)
+		return "", statusCancelled, nil
+	}
+
+	step := l.steps[0]
+	l.steps = l.steps[1:]
+
+	return l.replay(menu, step)
+}
+
+func (l *Launcher) replay(menu Menu, step Step) (string, int, error) {
+	switch step.kind {
+	case stepCancel:
+		return "", statusCancelled, nil
+	case stepInput:
+		return menu.output(-1, step.title, step.title), statusSelected, nil
+	case stepSelectIndex:
+		if step.index < 0 || step.index >= len(menu.Rows) {
+			l.t.Errorf("rofitest: row %d out of range in menu %q", step.index, menu.Prompt)
+			return "", statusCancelled, nil
+		}
+		return menu.output(step.index, menu.Filter, menu.Rows[step.index]), statusSelected, nil
+	case stepSelect:
+		index, ok := l.rowIndex(menu, step.title)
+		if !ok {
+			return "", statusCancelled, nil
+		}
+		return menu.output(index, menu.Filter, step.title), statusSelected, nil
+	case stepSelectMany:
+		out, ok := l.mark(menu, step.titles)
+		if !ok {
+			return "", statusCancelled, nil
+		}
+		return out, statusSelected, nil
+	case stepKey:
+		out, ok := l.mark(menu, step.titles)
+		if !ok {
+			return "", statusCancelled, nil
+		}
+
+		for i, key := range menu.Keybindings {
+			if key == step.key {
+				return out, statusKbCustom + i, nil
+			}
+		}
+
+		l.t.Errorf("rofitest: key %q not bound in menu %q (bound: %v)", step.key, menu.Prompt, menu.Keybindings)
+		return "", statusCancelled, nil
+	}
+
+	return "", statusCancelled, nil
+}
+
+// mark builds the output for the given rows and verifies
+// that multiple rows are only marked with multi select.
+func (l *Launcher) mark(menu Menu, titles []string) (string, bool) {
+	if len(titles) > 1 && !menu.MultiSelect {
+		l.t.Errorf("rofitest: multi select is disabled in menu %q", menu.Prompt)
+		return "", false
+	}
+
+	out := ""
+	for _, title := range titles {
+		index, ok := l.rowIndex(menu, title)
+		if !ok {
+			return "", false
+		}
+		out += menu.output(index, menu.Filter, title)
+	}
+
+	return out, true
+}
+
+// rowIndex returns the index of the first row with the given title.
+func (l *Launcher) rowIndex(menu Menu, title string) (int, bool) {
+	for i, row := range menu.Rows {
+		if row == title {
+			return i, true
+		}
+	}
+
+	l.t.Errorf("rofitest: row %q not found in menu %q (rows: %v)", title, menu.Prompt, menu.Rows)
+	return 0, false
+}
+
+// output formats a selection like rofi does for the requested
+// -format (only "i", "f" and "s" are supported, other characters
+// are copied as is).
+func (m Menu) output(index int, filter string, title string) string {
+	if m.Format == "" {
+		return title + "\n"
+	}
+
+	var b strings.Builder
+	for _, c := range m.Format {
+		switch c {
+		case 'i':
+			b.WriteString(strconv.Itoa(index))
+		case 'f':
+			b.WriteString(filter)
+		case 's':
+			b.WriteString(title)
+		default:
+			b.WriteRune(c)
+		}
+	}
+
+	return b.String() + "\n"
+}
+
+func parseMenu(args []string, input io.Reader) Menu {
+	menu := Menu{
+		Args: args,
+	}
+
+	for i := 0; i < len(args); i++ {
+		next := ""
+		if i+1 < len(args) {
+			next = args[i+1]
+		}
+
+		switch {
+		case args[i] == "-p":
+			menu.Prompt = next
+			i++
+		case args[i] == "-mesg":
+			menu.Message = next
+			i++
+		case args[i] == "-filter":
+			menu.Filter = next
+			i++
+		case args[i] == "-format":
+			menu.Format = next
+			i++
+		case args[i] == "-selected-row":
+			menu.SelectedRow, _ = strconv.Atoi(next)
+			i++
+		case strings.HasPrefix(args[i], "-kb-custom-"):
+			menu.Keybindings = append(menu.Keybindings, next)
+			i++
+		case args[i] == "-multi-select":
+			menu.MultiSelect = true
+		case args[i] == "-show-icons":
+			menu.ShowIcons = true
+		case args[i] == "-theme":
+			i++
+		}
+	}
+
+	if input != nil {
+		scanner := bufio.NewScanner(input)
+		for scanner.Scan() {
+			title, rawOpts, _ := strings.Cut(scanner.Text(), "\x00")
+
+			opts := map[string]string{}
+			if rawOpts != "" {
+				fields := strings.Split(rawOpts, "\x1f")
+				for j := 0; j+1 < len(fields); j += 2 {
+					opts[fields[j]] = fields[j+1]
+				}
+			}
+
+			menu.Rows = append(menu.Rows, title)
+			menu.RowOptions = append(menu.RowOptions, opts)
+		}
+	}
+
+	return menu
+}
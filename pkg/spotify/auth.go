@@ -2,14 +2,12 @@ package spotify
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
-	"time"
 )
 
 const (
@@ -21,27 +19,41 @@ const (
 type AuthorizationCodeGrantResponse struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
 }
 
 // RefreshTokenResponse represents the spotify response
-// for refreshing an access token.
+// for refreshing an access token. RefreshToken is only
+// set when the refresh token was rotated.
 type RefreshTokenResponse struct {
-	AccessToken string `json:"access_token"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
 }
 
 // AuthClient represent a spotify authentication client
-// to authenticate the user using oauth.
+// to authenticate the user using oauth. Without a client
+// secret, the client is a public client using PKCE.
 type AuthClient interface {
-	// BuildAuthUrl builds the oauth url with the
-	// clientId, the redirect uri and the scopes.
-	BuildAuthUrl() string
+	// BuildAuthUrl builds the oauth url with the clientId,
+	// the redirect uri, the scopes and the state, which is
+	// sent back to the redirect uri.
+	BuildAuthUrl(state string) string
+
+	// BuildPKCEAuthUrl builds the oauth url of the
+	// authorization code flow with PKCE for a code challenge.
+	BuildPKCEAuthUrl(state string, codeChallenge string) string
 
 	//GetTokenPair requests a new token pair.
 	GetTokenPair(code string) (*AuthorizationCodeGrantResponse, error)
 
+	// GetPKCETokenPair requests a new token pair with the
+	// code verifier of the authorization code flow with PKCE.
+	GetPKCETokenPair(code string, codeVerifier string) (*AuthorizationCodeGrantResponse, error)
+
 	// RequestRefreshedToken refreshes an access token
 	// using a refresh token.
-	RequestRefreshedToken(refreshToken string) (string, error)
+	RequestRefreshedToken(refreshToken string) (*RefreshTokenResponse, error)
 }
 
 type authClient struct {
@@ -49,6 +61,7 @@ type authClient struct {
 	clientSecret string
 	redirectUri  string
 	scopes       []string
+	baseUrl      string
 
 	httpClient *http.Client
 }
@@ -60,41 +73,62 @@ func NewAuthClient(
 	clientSecret string,
 	redirectUri string,
 	scopes []string,
+	opts ...Option,
 ) AuthClient {
+	o := newOptions(opts)
+
 	return &authClient{
 		clientId:     clientId,
 		clientSecret: clientSecret,
 		redirectUri:  redirectUri,
 		scopes:       scopes,
+		baseUrl:      o.authBaseUrl,
 
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		httpClient: o.httpClient,
 	}
 }
 
-func (c *authClient) BuildAuthUrl() string {
+// authUrlValues is an internal implementation to
+// build the query of the oauth url.
+func (c *authClient) authUrlValues(state string) url.Values {
 	q := url.Values{}
 	q.Add("response_type", "code")
 	q.Add("client_id", c.clientId)
 	q.Add("redirect_uri", c.redirectUri)
 
+	if state != "" {
+		q.Add("state", state)
+	}
+
 	if len(c.scopes) > 0 {
 		q.Add("scope", strings.Join(c.scopes, ","))
 	}
 
-	return fmt.Sprintf("%s/authorize?%s", spotifyAuthBaseUrl, q.Encode())
+	return q
 }
 
-func (c *authClient) GetTokenPair(code string) (*AuthorizationCodeGrantResponse, error) {
-	data := url.Values{}
-	data.Add("grant_type", "authorization_code")
-	data.Add("code", code)
+func (c *authClient) BuildAuthUrl(state string) string {
+	return fmt.Sprintf("%s/authorize?%s", c.baseUrl, c.authUrlValues(state).Encode())
+}
+
+func (c *authClient) BuildPKCEAuthUrl(state string, codeChallenge string) string {
+	q := c.authUrlValues(state)
+	q.Add("code_challenge_method", "S256")
+	q.Add("code_challenge", codeChallenge)
+
+	return fmt.Sprintf("%s/authorize?%s", c.baseUrl, q.Encode())
+}
+
+// requestToken is an internal implementation to request
+// a token from the accounts service. The client secret is
+// only sent by confidential clients.
+func (c *authClient) requestToken(data url.Values, v interface{}) error {
 	data.Add("client_id", c.clientId)
-	data.Add("client_secret", c.clientSecret)
-	data.Add("redirect_uri", c.redirectUri)
+	if c.clientSecret != "" {
+		data.Add("client_secret", c.clientSecret)
+	}
 
-	url := fmt.Sprintf("%s/api/token", spotifyAuthBaseUrl)
+	url := fmt.Sprintf("%s/api/token", c.baseUrl)
 
 	req, err := http.NewRequest(
 		http.MethodPost,
@@ -102,7 +136,7 @@ func (c *authClient) GetTokenPair(code string) (*AuthorizationCodeGrantResponse,
 		strings.NewReader(data.Encode()),
 	)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
@@ -110,69 +144,53 @@ func (c *authClient) GetTokenPair(code string) (*AuthorizationCodeGrantResponse,
 
 	res, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer res.Body.Close()
 
 	if res.StatusCode >= 400 {
-		fmt.Println(res.StatusCode)
-		return nil, errors.New("request failed")
+		return newError(res)
 	}
 
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var tokenResponse AuthorizationCodeGrantResponse
-	if err := json.Unmarshal(body, &tokenResponse); err != nil {
-		return nil, err
-	}
+	return json.Unmarshal(body, v)
+}
 
-	return &tokenResponse, nil
+func (c *authClient) GetTokenPair(code string) (*AuthorizationCodeGrantResponse, error) {
+	return c.GetPKCETokenPair(code, "")
 }
 
-func (c *authClient) RequestRefreshedToken(refreshToken string) (string, error) {
+func (c *authClient) GetPKCETokenPair(code string, codeVerifier string) (*AuthorizationCodeGrantResponse, error) {
 	data := url.Values{}
-	data.Add("grant_type", "refresh_token")
-	data.Add("refresh_token", refreshToken)
-	data.Add("client_id", c.clientId)
-	data.Add("client_secret", c.clientSecret)
-
-	url := fmt.Sprintf("%s/api/token", spotifyAuthBaseUrl)
+	data.Add("grant_type", "authorization_code")
+	data.Add("code", code)
+	data.Add("redirect_uri", c.redirectUri)
 
-	req, err := http.NewRequest(
-		http.MethodPost,
-		url,
-		strings.NewReader(data.Encode()),
-	)
-	if err != nil {
-		return "", err
+	if codeVerifier != "" {
+		data.Add("code_verifier", codeVerifier)
 	}
 
-	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Add("Content-Length", strconv.Itoa(len(data.Encode())))
-
-	res, err := c.httpClient.Do(req)
-	if err != nil {
-		return "", err
+	var tokenResponse AuthorizationCodeGrantResponse
+	if err := c.requestToken(data, &tokenResponse); err != nil {
+		return nil, err
 	}
-	defer res.Body.Close()
 
-	if res.StatusCode >= 400 {
-		fmt.Println(res.StatusCode)
-		return "", errors.New("request failed")
-	}
+	return &tokenResponse, nil
+}
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return "", err
-	}
+func (c *authClient) RequestRefreshedToken(refreshToken string) (*RefreshTokenResponse, error) {
+	data := url.Values{}
+	data.Add("grant_type", "refresh_token")
+	data.Add("refresh_token", refreshToken)
 
 	var tokenResponse RefreshTokenResponse
-	if err := json.Unmarshal(body, &tokenResponse); err != nil {
-		return "", err
+	if err := c.requestToken(data, &tokenResponse); err != nil {
+		return nil, err
 	}
 
-	return tokenResponse.AccessToken, nil
+	return &tokenResponse, nil
 }
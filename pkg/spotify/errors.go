@@ -0,0 +1,81 @@
+package spotify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	// ReasonNoActiveDevice is the reason of a failed player
+	// command when no device is active.
+	ReasonNoActiveDevice = "NO_ACTIVE_DEVICE"
+)
+
+// Error represents a failed spotify api request.
+type Error struct {
+	// Status is the http status code of the response.
+	Status int
+	// Message is the error message returned by spotify.
+	Message string
+	// Reason is the optional player error reason
+	// (e.g. NO_ACTIVE_DEVICE).
+	Reason string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("spotify: request failed with status %d", e.Status)
+	}
+
+	return fmt.Sprintf("spotify: %s (status %d)", e.Message, e.Status)
+}
+
+// errorResponse represents the error object
+// of the spotify web api.
+type errorResponse struct {
+	Error struct {
+		Status  int    `json:"status"`
+		Message string `json:"message"`
+		Reason  string `json:"reason"`
+	} `json:"error"`
+}
+
+// newError is an internal implementation to build
+// an Error from a failed http response.
+func newError(res *http.Response) error {
+	defer res.Body.Close()
+
+	e := &Error{
+		Status: res.StatusCode,
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return e
+	}
+
+	var data errorResponse
+	if err := json.Unmarshal(body, &data); err == nil {
+		e.Message = data.Error.Message
+		e.Reason = data.Error.Reason
+	}
+
+	return e
+}
+
+// IsStatusErr checks if the error is a spotify
+// api error with the given http status code.
+func IsStatusErr(err error, status int) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Status == status
+}
+
+// IsNoActiveDeviceErr checks if the error means
+// that no active device was found for a player command.
+func IsNoActiveDeviceErr(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Reason == ReasonNoActiveDevice
+}
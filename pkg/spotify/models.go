@@ -34,10 +34,17 @@ type SearchAlbumResult struct {
 	PagingResult
 }
 
+type Image struct {
+	URL    string `json:"url"`
+	Height int    `json:"height"`
+	Width  int    `json:"width"`
+}
+
 type Artist struct {
-	ID   string `json:"id"`
-	URI  string `json:"uri"`
-	Name string `json:"name"`
+	ID     string  `json:"id"`
+	URI    string  `json:"uri"`
+	Name   string  `json:"name"`
+	Images []Image `json:"images"`
 }
 
 type Album struct {
@@ -45,19 +52,38 @@ type Album struct {
 	ID          string   `json:"id"`
 	URI         string   `json:"uri"`
 	Name        string   `json:"name"`
+	Images      []Image  `json:"images"`
 	ReleaseDate string   `json:"release_date"`
 	TotalTracks int      `json:"total_tracks"`
 }
 
+// Track is a track or a podcast episode, as the player, queue
+// and recently played endpoints return both. Episodes (type
+// episode) have a show and a release date instead of an album
+// and artists.
 type Track struct {
 	Album       Album    `json:"album"`
 	Artists     []Artist `json:"artists"`
 	DiscNumber  int      `json:"disc_number"`
 	DurationMs  int      `json:"duration_ms"`
+	Explicit    bool     `json:"explicit"`
 	ID          string   `json:"id"`
 	URI         string   `json:"uri"`
 	Name        string   `json:"name"`
 	TrackNumber int      `json:"track_number"`
+	Type        string   `json:"type"`
+	Show        Show     `json:"show"`
+	ReleaseDate string   `json:"release_date"`
+}
+
+// IsEpisode checks if the track is a podcast episode.
+func (t Track) IsEpisode() bool {
+	return t.Type == "episode"
+}
+
+type Show struct {
+	Name      string `json:"name"`
+	Publisher string `json:"publisher"`
 }
 
 type Player struct {
@@ -69,15 +95,20 @@ type Player struct {
 	IsPlaying    bool        `json:"is_playing"`
 }
 
+type SavedTrack struct {
+	AddedAt string `json:"added_at"`
+	Track   Track  `json:"track"`
+}
+
 type LikeTracksResponse struct {
-	Items []struct {
-		Track Track `json:"track"`
-	} `json:"items"`
+	Items []SavedTrack `json:"items"`
 	PagingResult
 }
 
 type QueueResponse struct {
-	Queue []Track `json:"queue"`
+	// CurrentlyPlaying is the playing track or nil.
+	CurrentlyPlaying *Track  `json:"currently_playing"`
+	Queue            []Track `json:"queue"`
 }
 
 type RecentlyPlayedResponse struct {
@@ -94,9 +125,50 @@ type AlbumWithTracks struct {
 	} `json:"tracks"`
 }
 
+type SavedAlbum struct {
+	AddedAt string          `json:"added_at"`
+	Album   AlbumWithTracks `json:"album"`
+}
+
 type SavedAlbumResponse struct {
-	Items []struct {
-		Album AlbumWithTracks `json:"album"`
-	} `json:"items"`
+	Items []SavedAlbum `json:"items"`
+	PagingResult
+}
+
+type ArtistAlbumsResponse struct {
+	Items []Album `json:"items"`
+	PagingResult
+}
+
+type User struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name"`
+}
+
+type Playlist struct {
+	ID            string  `json:"id"`
+	URI           string  `json:"uri"`
+	Name          string  `json:"name"`
+	Owner         User    `json:"owner"`
+	Collaborative bool    `json:"collaborative"`
+	Images        []Image `json:"images"`
+	SnapshotID    string  `json:"snapshot_id"`
+	Tracks        struct {
+		Total int `json:"total"`
+	} `json:"tracks"`
+}
+
+type PlaylistsResponse struct {
+	Items []Playlist `json:"items"`
+	PagingResult
+}
+
+type PlaylistTrack struct {
+	AddedAt string `json:"added_at"`
+	Track   Track  `json:"track"`
+}
+
+type PlaylistTracksResponse struct {
+	Items []PlaylistTrack `json:"items"`
 	PagingResult
 }
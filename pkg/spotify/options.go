@@ -0,0 +1,76 @@
+package spotify
+
+import "net/http"
+
+// Option configures optional settings of the
+// spotify api and authentication clients.
+type Option func(*options)
+
+type options struct {
+	apiBaseUrl  string
+	authBaseUrl string
+	httpClient  *http.Client
+
+	token   Token
+	onToken func(token Token)
+}
+
+// WithApiBaseUrl overrides the base url of the
+// spotify web api (default: https://api.spotify.com/v1).
+func WithApiBaseUrl(url string) Option {
+	return func(o *options) {
+		o.apiBaseUrl = url
+	}
+}
+
+// WithAuthBaseUrl overrides the base url of the
+// spotify accounts service (default: https://accounts.spotify.com).
+func WithAuthBaseUrl(url string) Option {
+	return func(o *options) {
+		o.authBaseUrl = url
+	}
+}
+
+// WithHTTPClient overrides the http client used
+// for all requests.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithToken sets an access token which was saved
+// earlier, so it is used until it expires instead
+// of requesting a new one.
+func WithToken(token Token) Option {
+	return func(o *options) {
+		o.token = token
+	}
+}
+
+// WithTokenHandler sets a handler which is called with every
+// new access token and its (possibly rotated) refresh token,
+// so they can be saved.
+func WithTokenHandler(handler func(token Token)) Option {
+	return func(o *options) {
+		o.onToken = handler
+	}
+}
+
+// newOptions is an internal implementation to
+// apply the given options over the defaults.
+func newOptions(opts []Option) *options {
+	o := &options{
+		apiBaseUrl:  spotifyApiBaseUrl,
+		authBaseUrl: spotifyAuthBaseUrl,
+		httpClient: &http.Client{
+			Timeout: httpTimeout,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
@@ -0,0 +1,43 @@
+package spotify
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// codeVerifierLength is the number of random bytes of a code
+// verifier, which is encoded to 86 characters (43-128 are allowed).
+const codeVerifierLength = 64
+
+// stateLength is the number of random bytes of a state.
+const stateLength = 16
+
+// randomString is an internal implementation to generate
+// a url safe string of n random bytes.
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// NewCodeVerifier generates a random code verifier
+// for the authorization code flow with PKCE.
+func NewCodeVerifier() (string, error) {
+	return randomString(codeVerifierLength)
+}
+
+// NewState generates a random state, which binds the
+// oauth callback to the authorization request.
+func NewState() (string, error) {
+	return randomString(stateLength)
+}
+
+// CodeChallenge returns the S256 code challenge of a code verifier.
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
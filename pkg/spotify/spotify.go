@@ -3,13 +3,13 @@ package spotify
 import (
 	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -30,6 +30,10 @@ type Client interface {
 	// a given device.
 	PlayTrack(uri string, deviceId string) error
 
+	// PlayTracks plays the given tracks in order
+	// on a given device.
+	PlayTracks(uris []string, deviceId string) error
+
 	// GetLikedTracks fetches the liked tracks
 	// of the user.
 	GetLikedTracks(limit int, offset int) (*LikeTracksResponse, error)
@@ -75,11 +79,42 @@ type Client interface {
 
 	// GetAlbum fetches a album by id.
 	GetAlbum(id string) (*AlbumWithTracks, error)
+
+	// GetTrack fetches a track by id.
+	GetTrack(id string) (*Track, error)
+
+	// GetArtist fetches an artist by id.
+	GetArtist(id string) (*Artist, error)
+
+	// GetArtistAlbums fetches the albums and
+	// singles of an artist by id.
+	GetArtistAlbums(id string) (*ArtistAlbumsResponse, error)
+
+	// SaveTracks saves the given tracks by id
+	// to the liked tracks of the user.
+	SaveTracks(ids []string) error
+
+	// GetPlaylists fetches the playlists of the user.
+	GetPlaylists(limit int, offset int) (*PlaylistsResponse, error)
+
+	// GetPlaylistTracks fetches the tracks of a playlist.
+	GetPlaylistTracks(playlistId string, limit int, offset int) (*PlaylistTracksResponse, error)
+
+	// AddPlaylistTracks adds the given tracks to the end of a playlist.
+	AddPlaylistTracks(playlistId string, uris []string) error
 }
 
 type client struct {
 	refreshToken string
-	accessToken  string
+	baseUrl      string
+
+	// mu guards the tokens, as requests
+	// may run concurrently.
+	mu    sync.Mutex
+	token Token
+
+	// onToken is called with every new access token.
+	onToken func(token Token)
 
 	authClient AuthClient
 	httpClient *http.Client
@@ -103,39 +138,94 @@ func URIToID(uri string) string {
 	return split[2]
 }
 
+// URIToURL converts a uri (e.g. spotify:track:<id>)
+// to the url of the spotify web player.
+func URIToURL(uri string) string {
+	split := strings.Split(uri, ":")
+	if len(split) != 3 {
+		return ""
+	}
+	return fmt.Sprintf("https://open.spotify.com/%s/%s", split[1], split[2])
+}
+
 // NewClient creates a new spotify web
 // api client.
 func NewClient(
 	refreshToken string,
 	clientId string,
 	clientSecret string,
+	opts ...Option,
 ) Client {
+	o := newOptions(opts)
+
 	return &client{
 		refreshToken: refreshToken,
+		baseUrl:      o.apiBaseUrl,
 		authClient: NewAuthClient(
-			clientId, clientSecret, "", []string{},
+			clientId, clientSecret, "", []string{}, opts...,
 		),
-		httpClient: &http.Client{
-			Timeout: httpTimeout,
-		},
+		httpClient: o.httpClient,
+		token:      o.token,
+		onToken:    o.onToken,
+	}
+}
+
+// accessToken is an internal implementation to get a valid
+// access token. A new one is requested when no token exists
+// or the token expires soon.
+func (c *client) accessToken() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token.Valid() {
+		return c.token.AccessToken, nil
+	}
+
+	res, err := c.authClient.RequestRefreshedToken(c.refreshToken)
+	if err != nil {
+		return "", err
+	}
+
+	// The refresh token of a PKCE client is rotated on
+	// every refresh, so the old one can't be used again.
+	if res.RefreshToken != "" {
+		c.refreshToken = res.RefreshToken
+	}
+
+	c.token = newToken(res.AccessToken, res.ExpiresIn)
+	c.token.RefreshToken = c.refreshToken
+
+	if c.onToken != nil {
+		c.onToken(c.token)
+	}
+
+	return c.token.AccessToken, nil
+}
+
+// expireToken is an internal implementation to drop an
+// access token which was rejected by the api, unless
+// another request already replaced it.
+func (c *client) expireToken(accessToken string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token.AccessToken == accessToken {
+		c.token = Token{}
 	}
 }
 
 // doRequestWithToken is am internal implementation to
-// execute the request with an access token.
-// It requests a new one when no token exists.
-func (c *client) doRequestWithToken(req *http.Request) (*http.Response, error) {
-	if c.accessToken == "" {
-		token, err := c.authClient.RequestRefreshedToken(c.refreshToken)
-		if err != nil {
-			return nil, err
-		}
-		c.accessToken = token
+// execute the request with a valid access token.
+func (c *client) doRequestWithToken(req *http.Request) (*http.Response, string, error) {
+	accessToken, err := c.accessToken()
+	if err != nil {
+		return nil, "", err
 	}
 
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.accessToken))
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
 
-	return c.httpClient.Do(req)
+	res, err := c.httpClient.Do(req)
+	return res, accessToken, err
 }
 
 // doRequest is an internal implementation to execute
@@ -143,17 +233,35 @@ func (c *client) doRequestWithToken(req *http.Request) (*http.Response, error) {
 // when the token is expired.
 // It also returns an error for status code >= 400.
 func (c *client) doRequest(req *http.Request) (*http.Response, error) {
-	res, err := c.doRequestWithToken(req)
+	res, accessToken, err := c.doRequestWithToken(req)
+	if err != nil {
+		return nil, err
+	}
 
+	// The token may be revoked before it expires.
 	if res.StatusCode == http.StatusUnauthorized {
-		c.accessToken = ""
-		return c.doRequestWithToken(req)
-	} else if res.StatusCode >= 400 {
-		fmt.Println(res.StatusCode)
-		return nil, errors.New("failed")
+		res.Body.Close()
+		c.expireToken(accessToken)
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		res, _, err = c.doRequestWithToken(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if res.StatusCode >= 400 {
+		return nil, newError(res)
 	}
 
-	return res, err
+	return res, nil
 }
 
 // getResult is an internal implementation to read
@@ -170,7 +278,7 @@ func (c *client) getResult(res *http.Response, dest interface{}) error {
 }
 
 func (c *client) GetDevices() (*DeviceResponse, error) {
-	url := fmt.Sprintf("%s/me/player/devices", spotifyApiBaseUrl)
+	url := fmt.Sprintf("%s/me/player/devices", c.baseUrl)
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
@@ -196,7 +304,7 @@ func (c *client) Search(q string, searchType string) (*SearchResponse, error) {
 	// TODO: make limit adjustable
 	params.Add("limit", "10")
 
-	url := fmt.Sprintf("%s/search?%s", spotifyApiBaseUrl, params.Encode())
+	url := fmt.Sprintf("%s/search?%s", c.baseUrl, params.Encode())
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
@@ -216,7 +324,7 @@ func (c *client) Search(q string, searchType string) (*SearchResponse, error) {
 }
 
 func (c *client) GetPlayer() (*Player, error) {
-	url := fmt.Sprintf("%s/me/player", spotifyApiBaseUrl)
+	url := fmt.Sprintf("%s/me/player", c.baseUrl)
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
@@ -240,7 +348,11 @@ func (c *client) GetPlayer() (*Player, error) {
 }
 
 func (c *client) PlayTrack(uri string, deviceId string) error {
-	u := fmt.Sprintf("%s/me/player/play", spotifyApiBaseUrl)
+	return c.PlayTracks([]string{uri}, deviceId)
+}
+
+func (c *client) PlayTracks(uris []string, deviceId string) error {
+	u := fmt.Sprintf("%s/me/player/play", c.baseUrl)
 
 	if deviceId != "" {
 		params := url.Values{}
@@ -249,7 +361,7 @@ func (c *client) PlayTrack(uri string, deviceId string) error {
 	}
 
 	reqBody := map[string]interface{}{
-		"uris": []string{uri},
+		"uris": uris,
 	}
 
 	jsonData, err := json.Marshal(reqBody)
@@ -271,7 +383,7 @@ func (c *client) GetLikedTracks(limit int, offset int) (*LikeTracksResponse, err
 	params.Add("limit", strconv.Itoa(limit))
 	params.Add("offset", strconv.Itoa(offset))
 
-	u := fmt.Sprintf("%s/me/tracks?%s", spotifyApiBaseUrl, params.Encode())
+	u := fmt.Sprintf("%s/me/tracks?%s", c.baseUrl, params.Encode())
 
 	req, err := http.NewRequest(http.MethodGet, u, nil)
 	if err != nil {
@@ -299,7 +411,7 @@ func (c *client) AddQueue(uri string, deviceId string) error {
 		params.Add("device_id", deviceId)
 	}
 
-	u := fmt.Sprintf("%s/me/player/queue?%s", spotifyApiBaseUrl, params.Encode())
+	u := fmt.Sprintf("%s/me/player/queue?%s", c.baseUrl, params.Encode())
 
 	req, err := http.NewRequest(http.MethodPost, u, nil)
 	if err != nil {
@@ -311,7 +423,7 @@ func (c *client) AddQueue(uri string, deviceId string) error {
 }
 
 func (c *client) Pause(deviceId string) error {
-	u := fmt.Sprintf("%s/me/player/pause", spotifyApiBaseUrl)
+	u := fmt.Sprintf("%s/me/player/pause", c.baseUrl)
 
 	if deviceId != "" {
 		params := url.Values{}
@@ -329,7 +441,7 @@ func (c *client) Pause(deviceId string) error {
 }
 
 func (c *client) Play(deviceId string) error {
-	u := fmt.Sprintf("%s/me/player/play", spotifyApiBaseUrl)
+	u := fmt.Sprintf("%s/me/player/play", c.baseUrl)
 
 	if deviceId != "" {
 		params := url.Values{}
@@ -347,7 +459,7 @@ func (c *client) Play(deviceId string) error {
 }
 
 func (c *client) Next(deviceId string) error {
-	u := fmt.Sprintf("%s/me/player/next", spotifyApiBaseUrl)
+	u := fmt.Sprintf("%s/me/player/next", c.baseUrl)
 
 	if deviceId != "" {
 		params := url.Values{}
@@ -365,7 +477,7 @@ func (c *client) Next(deviceId string) error {
 }
 
 func (c *client) Previous(deviceId string) error {
-	u := fmt.Sprintf("%s/me/player/previous", spotifyApiBaseUrl)
+	u := fmt.Sprintf("%s/me/player/previous", c.baseUrl)
 
 	if deviceId != "" {
 		params := url.Values{}
@@ -383,7 +495,7 @@ func (c *client) Previous(deviceId string) error {
 }
 
 func (c *client) GetQueue() (*QueueResponse, error) {
-	u := fmt.Sprintf("%s/me/player/queue", spotifyApiBaseUrl)
+	u := fmt.Sprintf("%s/me/player/queue", c.baseUrl)
 
 	req, err := http.NewRequest(http.MethodGet, u, nil)
 	if err != nil {
@@ -404,7 +516,7 @@ func (c *client) GetQueue() (*QueueResponse, error) {
 }
 
 func (c *client) GetRecentlyPlayedTracks() (*RecentlyPlayedResponse, error) {
-	u := fmt.Sprintf("%s/me/player/recently-played", spotifyApiBaseUrl)
+	u := fmt.Sprintf("%s/me/player/recently-played", c.baseUrl)
 
 	req, err := http.NewRequest(http.MethodGet, u, nil)
 	if err != nil {
@@ -429,7 +541,7 @@ func (c *client) GetSavedAlbums(limit int, offset int) (*SavedAlbumResponse, err
 	params.Add("limit", strconv.Itoa(limit))
 	params.Add("offset", strconv.Itoa(offset))
 
-	u := fmt.Sprintf("%s/me/albums?%s", spotifyApiBaseUrl, params.Encode())
+	u := fmt.Sprintf("%s/me/albums?%s", c.baseUrl, params.Encode())
 
 	req, err := http.NewRequest(http.MethodGet, u, nil)
 	if err != nil {
@@ -450,7 +562,7 @@ func (c *client) GetSavedAlbums(limit int, offset int) (*SavedAlbumResponse, err
 }
 
 func (c *client) PlayContext(contextUri string, deviceId string, uri ...string) error {
-	u := fmt.Sprintf("%s/me/player/play", spotifyApiBaseUrl)
+	u := fmt.Sprintf("%s/me/player/play", c.baseUrl)
 
 	if deviceId != "" {
 		params := url.Values{}
@@ -490,7 +602,7 @@ func (c *client) SetShuffleState(deviceId string, state bool) error {
 		params.Add("device_id", deviceId)
 	}
 
-	u := fmt.Sprintf("%s/me/player/shuffle?%s", spotifyApiBaseUrl, params.Encode())
+	u := fmt.Sprintf("%s/me/player/shuffle?%s", c.baseUrl, params.Encode())
 
 	req, err := http.NewRequest(http.MethodPut, u, nil)
 	if err != nil {
@@ -509,7 +621,7 @@ func (c *client) SetRepeatMode(deviceId string, state RepeatState) error {
 		params.Add("device_id", deviceId)
 	}
 
-	u := fmt.Sprintf("%s/me/player/repeat?%s", spotifyApiBaseUrl, params.Encode())
+	u := fmt.Sprintf("%s/me/player/repeat?%s", c.baseUrl, params.Encode())
 
 	req, err := http.NewRequest(http.MethodPut, u, nil)
 	if err != nil {
@@ -521,7 +633,7 @@ func (c *client) SetRepeatMode(deviceId string, state RepeatState) error {
 }
 
 func (c *client) GetAlbum(id string) (*AlbumWithTracks, error) {
-	u := fmt.Sprintf("%s/albums/%s", spotifyApiBaseUrl, id)
+	u := fmt.Sprintf("%s/albums/%s", c.baseUrl, id)
 
 	req, err := http.NewRequest(http.MethodGet, u, nil)
 	if err != nil {
@@ -540,3 +652,156 @@ func (c *client) GetAlbum(id string) (*AlbumWithTracks, error) {
 
 	return &data, nil
 }
+
+func (c *client) GetTrack(id string) (*Track, error) {
+	u := fmt.Sprintf("%s/tracks/%s", c.baseUrl, id)
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var data Track
+	if err := c.getResult(res, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+func (c *client) GetArtist(id string) (*Artist, error) {
+	u := fmt.Sprintf("%s/artists/%s", c.baseUrl, id)
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var data Artist
+	if err := c.getResult(res, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+func (c *client) GetArtistAlbums(id string) (*ArtistAlbumsResponse, error) {
+	params := url.Values{}
+	params.Add("include_groups", "album,single")
+	params.Add("limit", "50")
+
+	u := fmt.Sprintf("%s/artists/%s/albums?%s", c.baseUrl, id, params.Encode())
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var data ArtistAlbumsResponse
+	if err := c.getResult(res, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+func (c *client) SaveTracks(ids []string) error {
+	params := url.Values{}
+	params.Add("ids", strings.Join(ids, ","))
+
+	u := fmt.Sprintf("%s/me/tracks?%s", c.baseUrl, params.Encode())
+
+	req, err := http.NewRequest(http.MethodPut, u, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.doRequest(req)
+	return err
+}
+
+func (c *client) GetPlaylists(limit int, offset int) (*PlaylistsResponse, error) {
+	params := url.Values{}
+	params.Add("limit", strconv.Itoa(limit))
+	params.Add("offset", strconv.Itoa(offset))
+
+	u := fmt.Sprintf("%s/me/playlists?%s", c.baseUrl, params.Encode())
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var data PlaylistsResponse
+	if err := c.getResult(res, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+func (c *client) GetPlaylistTracks(playlistId string, limit int, offset int) (*PlaylistTracksResponse, error) {
+	params := url.Values{}
+	params.Add("limit", strconv.Itoa(limit))
+	params.Add("offset", strconv.Itoa(offset))
+
+	u := fmt.Sprintf("%s/playlists/%s/tracks?%s", c.baseUrl, playlistId, params.Encode())
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var data PlaylistTracksResponse
+	if err := c.getResult(res, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+func (c *client) AddPlaylistTracks(playlistId string, uris []string) error {
+	u := fmt.Sprintf("%s/playlists/%s/tracks", c.baseUrl, playlistId)
+
+	reqBody := map[string]interface{}{
+		"uris": uris,
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+
+	_, err = c.doRequest(req)
+	return err
+}
@@ -0,0 +1,616 @@
+package spotify_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/davidborzek/spofi/pkg/spotify"
+	"github.com/davidborzek/spofi/pkg/spotify/spotifytest"
+)
+
+func newServer(t *testing.T) *spotifytest.Server {
+	t.Helper()
+
+	srv := spotifytest.NewServer()
+	t.Cleanup(srv.Close)
+
+	srv.AddDevice(spotifytest.NewDevice("device-1", "Laptop"))
+	srv.AddDevice(spotifytest.NewDevice("device-2", "Phone"))
+
+	srv.SaveAlbums(spotifytest.NewAlbum("album-1", "First Album", "Artist",
+		spotifytest.NewTrack("track-1", "Intro", "Artist"),
+		spotifytest.NewTrack("track-2", "Second Song", "Artist"),
+		spotifytest.NewTrack("track-3", "Outro", "Artist"),
+	))
+
+	srv.AddPlaylist(spotifytest.NewPlaylist("playlist-1", "Focus"))
+
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		srv.LikeTracks(spotifytest.NewTrack("liked-"+id, "Liked "+id, "Artist"))
+	}
+
+	return srv
+}
+
+func TestClient(t *testing.T) {
+	tests := []struct {
+		name   string
+		call   func(c spotify.Client) error
+		verify func(t *testing.T, srv *spotifytest.Server)
+	}{
+		{
+			name: "play track",
+			call: func(c spotify.Client) error {
+				return c.PlayTrack("spotify:track:track-2", "")
+			},
+			verify: func(t *testing.T, srv *spotifytest.Server) {
+				p := srv.Player()
+				if p == nil || p.Item.ID != "track-2" || !p.IsPlaying {
+					t.Fatalf("expected track-2 to be playing, got %+v", p)
+				}
+			},
+		},
+		{
+			name: "play track on device",
+			call: func(c spotify.Client) error {
+				return c.PlayTrack("spotify:track:track-1", "device-2")
+			},
+			verify: func(t *testing.T, srv *spotifytest.Server) {
+				if p := srv.Player(); p.Device.ID != "device-2" {
+					t.Fatalf("expected playback on device-2, got %s", p.Device.ID)
+				}
+			},
+		},
+		{
+			name: "play context with offset",
+			call: func(c spotify.Client) error {
+				return c.PlayContext("spotify:album:album-1", "", "spotify:track:track-3")
+			},
+			verify: func(t *testing.T, srv *spotifytest.Server) {
+				if srv.Context() != "spotify:album:album-1" {
+					t.Fatalf("unexpected context %q", srv.Context())
+				}
+				if p := srv.Player(); p.Item.ID != "track-3" {
+					t.Fatalf("expected track-3 to be playing, got %s", p.Item.ID)
+				}
+			},
+		},
+		{
+			name: "play tracks",
+			call: func(c spotify.Client) error {
+				if err := c.PlayTracks([]string{"spotify:track:track-3", "spotify:track:track-1"}, ""); err != nil {
+					return err
+				}
+				return c.Next("")
+			},
+			verify: func(t *testing.T, srv *spotifytest.Server) {
+				if p := srv.Player(); p.Item.ID != "track-1" {
+					t.Fatalf("expected track-1 to be playing, got %s", p.Item.ID)
+				}
+			},
+		},
+		{
+			name: "save tracks",
+			call: func(c spotify.Client) error {
+				return c.SaveTracks([]string{"track-1", "liked-a"})
+			},
+			verify: func(t *testing.T, srv *spotifytest.Server) {
+				liked := srv.LikedTracks()
+				if len(liked) != 6 || liked[0].ID != "track-1" {
+					t.Fatalf("unexpected liked tracks %+v", liked)
+				}
+			},
+		},
+		{
+			name: "add playlist tracks",
+			call: func(c spotify.Client) error {
+				playlists, err := c.GetPlaylists(10, 0)
+				if err != nil {
+					return err
+				}
+				if len(playlists.Items) != 1 {
+					t.Fatalf("expected 1 playlist, got %d", len(playlists.Items))
+				}
+				return c.AddPlaylistTracks(playlists.Items[0].ID, []string{"spotify:track:track-2", "spotify:track:track-1"})
+			},
+			verify: func(t *testing.T, srv *spotifytest.Server) {
+				p, _ := srv.Playlist("playlist-1")
+				if len(p.Tracks) != 2 || p.Tracks[0].ID != "track-2" {
+					t.Fatalf("unexpected playlist tracks %+v", p.Tracks)
+				}
+			},
+		},
+		{
+			name: "get playlist tracks",
+			call: func(c spotify.Client) error {
+				if err := c.AddPlaylistTracks("playlist-1", []string{"spotify:track:track-3"}); err != nil {
+					return err
+				}
+
+				tracks, err := c.GetPlaylistTracks("playlist-1", 10, 0)
+				if err != nil {
+					return err
+				}
+				if tracks.Total != 1 || tracks.Items[0].Track.ID != "track-3" {
+					t.Fatalf("unexpected playlist tracks %+v", tracks)
+				}
+				return nil
+			},
+			verify: func(t *testing.T, srv *spotifytest.Server) {
+				if p, _ := srv.Playlist("playlist-1"); len(p.Tracks) != 1 {
+					t.Fatalf("unexpected playlist tracks %+v", p.Tracks)
+				}
+			},
+		},
+		{
+			name: "add queue",
+			call: func(c spotify.Client) error {
+				if err := c.AddQueue("spotify:track:track-1", ""); err != nil {
+					return err
+				}
+				return c.AddQueue("spotify:track:track-3", "")
+			},
+			verify: func(t *testing.T, srv *spotifytest.Server) {
+				q := srv.Queue()
+				if len(q) != 2 || q[0] != "spotify:track:track-1" || q[1] != "spotify:track:track-3" {
+					t.Fatalf("unexpected queue %v", q)
+				}
+			},
+		},
+		{
+			name: "pause and resume",
+			call: func(c spotify.Client) error {
+				if err := c.PlayTrack("spotify:track:track-1", ""); err != nil {
+					return err
+				}
+				if err := c.Pause(""); err != nil {
+					return err
+				}
+				return c.Play("")
+			},
+			verify: func(t *testing.T, srv *spotifytest.Server) {
+				if !srv.Player().IsPlaying {
+					t.Fatal("expected player to be playing")
+				}
+			},
+		},
+		{
+			name: "next and previous in context",
+			call: func(c spotify.Client) error {
+				if err := c.PlayContext("spotify:album:album-1", ""); err != nil {
+					return err
+				}
+				if err := c.Next(""); err != nil {
+					return err
+				}
+				if err := c.Next(""); err != nil {
+					return err
+				}
+				return c.Previous("")
+			},
+			verify: func(t *testing.T, srv *spotifytest.Server) {
+				if p := srv.Player(); p.Item.ID != "track-2" {
+					t.Fatalf("expected track-2 to be playing, got %s", p.Item.ID)
+				}
+			},
+		},
+		{
+			name: "shuffle and repeat",
+			call: func(c spotify.Client) error {
+				if err := c.PlayTrack("spotify:track:track-1", ""); err != nil {
+					return err
+				}
+				if err := c.SetShuffleState("", true); err != nil {
+					return err
+				}
+				return c.SetRepeatMode("", spotify.RepeatTrack)
+			},
+			verify: func(t *testing.T, srv *spotifytest.Server) {
+				p := srv.Player()
+				if !p.ShuffleState || p.RepeatState != spotify.RepeatTrack {
+					t.Fatalf("unexpected player state %+v", p)
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newServer(t)
+
+			if err := tc.call(srv.NewClient()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			tc.verify(t, srv)
+		})
+	}
+}
+
+func TestClientGetPlayer(t *testing.T) {
+	srv := newServer(t)
+	c := srv.NewClient()
+
+	p, err := c.GetPlayer()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p != nil {
+		t.Fatalf("expected no player, got %+v", p)
+	}
+
+	if err := c.PlayTrack("spotify:track:track-1", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	p, err = c.GetPlayer()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Item.Name != "Intro" || p.Device.Name != "Laptop" {
+		t.Fatalf("unexpected player %+v", p)
+	}
+}
+
+func TestClientPaging(t *testing.T) {
+	tests := []struct {
+		name   string
+		limit  int
+		offset int
+		want   []string
+	}{
+		{name: "first page", limit: 2, offset: 0, want: []string{"liked-a", "liked-b"}},
+		{name: "second page", limit: 2, offset: 2, want: []string{"liked-c", "liked-d"}},
+		{name: "last page", limit: 2, offset: 4, want: []string{"liked-e"}},
+		{name: "beyond last page", limit: 2, offset: 10, want: []string{}},
+	}
+
+	srv := newServer(t)
+	c := srv.NewClient()
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := c.GetLikedTracks(tc.limit, tc.offset)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if res.Total != 5 {
+				t.Fatalf("expected total 5, got %d", res.Total)
+			}
+
+			if len(res.Items) != len(tc.want) {
+				t.Fatalf("expected %d items, got %d", len(tc.want), len(res.Items))
+			}
+
+			for i, id := range tc.want {
+				if res.Items[i].Track.ID != id {
+					t.Fatalf("expected %s at %d, got %s", id, i, res.Items[i].Track.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestClientLibrary(t *testing.T) {
+	srv := newServer(t)
+	c := srv.NewClient()
+
+	albums, err := c.GetSavedAlbums(10, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(albums.Items) != 1 || len(albums.Items[0].Album.Tracks.Items) != 3 {
+		t.Fatalf("unexpected saved albums %+v", albums)
+	}
+
+	album, err := c.GetAlbum("album-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if album.Name != "First Album" {
+		t.Fatalf("unexpected album %+v", album)
+	}
+
+	track, err := c.GetTrack("track-2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if track.Name != "Second Song" || track.Album.Name != "First Album" {
+		t.Fatalf("unexpected track %+v", track)
+	}
+
+	artist, err := c.GetArtist("Artist")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if artist.Name != "Artist" || artist.URI != "spotify:artist:Artist" {
+		t.Fatalf("unexpected artist %+v", artist)
+	}
+
+	artistAlbums, err := c.GetArtistAlbums("Artist")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(artistAlbums.Items) != 1 || artistAlbums.Items[0].ID != "album-1" {
+		t.Fatalf("unexpected artist albums %+v", artistAlbums.Items)
+	}
+
+	res, err := c.Search("song", "track")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Tracks.Items) != 1 || res.Tracks.Items[0].ID != "track-2" {
+		t.Fatalf("unexpected search result %+v", res.Tracks.Items)
+	}
+
+	devices, err := c.GetDevices()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(devices.Devices) != 2 {
+		t.Fatalf("expected 2 devices, got %d", len(devices.Devices))
+	}
+}
+
+func TestClientFaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		fault   *spotifytest.Fault
+		setup   func(srv *spotifytest.Server)
+		call    func(c spotify.Client) error
+		check   func(err error) bool
+		wantErr bool
+	}{
+		{
+			name:  "rate limited",
+			fault: &spotifytest.Fault{Path: "/me/player/devices", Status: http.StatusTooManyRequests},
+			call: func(c spotify.Client) error {
+				_, err := c.GetDevices()
+				return err
+			},
+			check: func(err error) bool {
+				return spotify.IsStatusErr(err, http.StatusTooManyRequests)
+			},
+			wantErr: true,
+		},
+		{
+			name:  "server error",
+			fault: &spotifytest.Fault{Method: http.MethodPut, Status: http.StatusBadGateway},
+			call: func(c spotify.Client) error {
+				return c.PlayTrack("spotify:track:track-1", "")
+			},
+			check: func(err error) bool {
+				return spotify.IsStatusErr(err, http.StatusBadGateway)
+			},
+			wantErr: true,
+		},
+		{
+			name:  "fault only once",
+			fault: &spotifytest.Fault{Path: "/me/player/queue", Status: http.StatusServiceUnavailable, Times: 1},
+			call: func(c spotify.Client) error {
+				if _, err := c.GetQueue(); err == nil {
+					return nil
+				}
+				_, err := c.GetQueue()
+				return err
+			},
+		},
+		{
+			name: "no active device",
+			setup: func(srv *spotifytest.Server) {
+				srv.SetNoActiveDevice()
+			},
+			call: func(c spotify.Client) error {
+				return c.Pause("")
+			},
+			check:   spotify.IsNoActiveDeviceErr,
+			wantErr: true,
+		},
+		{
+			name: "expired access token",
+			setup: func(srv *spotifytest.Server) {
+				srv.ExpireTokens()
+			},
+			call: func(c spotify.Client) error {
+				return c.PlayTrack("spotify:track:track-1", "")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newServer(t)
+			c := srv.NewClient()
+
+			// Obtain an access token before the fault is applied.
+			if _, err := c.GetPlayer(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.fault != nil {
+				srv.InjectFault(*tc.fault)
+			}
+			if tc.setup != nil {
+				tc.setup(srv)
+			}
+
+			err := tc.call(c)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("expected error: %v, got %v", tc.wantErr, err)
+			}
+
+			if tc.check != nil && !tc.check(err) {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthClient(t *testing.T) {
+	srv := newServer(t)
+
+	tests := []struct {
+		name         string
+		clientSecret string
+		refreshToken string
+		wantErr      bool
+	}{
+		{name: "valid", clientSecret: spotifytest.ClientSecret, refreshToken: spotifytest.RefreshToken},
+		{name: "invalid client secret", clientSecret: "invalid", refreshToken: spotifytest.RefreshToken, wantErr: true},
+		{name: "invalid refresh token", clientSecret: spotifytest.ClientSecret, refreshToken: "invalid", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ac := spotify.NewAuthClient(spotifytest.ClientID, tc.clientSecret, "", nil, srv.Options()...)
+
+			token, err := ac.RequestRefreshedToken(tc.refreshToken)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("expected error: %v, got %v", tc.wantErr, err)
+			}
+
+			if !tc.wantErr && (token.AccessToken == "" || token.RefreshToken != "") {
+				t.Fatalf("unexpected token %+v", token)
+			}
+		})
+	}
+
+	ac := spotify.NewAuthClient(spotifytest.ClientID, spotifytest.ClientSecret, "http://localhost:8080", nil, srv.Options()...)
+	pair, err := ac.GetTokenPair(spotifytest.AuthorizationCode)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pair.RefreshToken != spotifytest.RefreshToken || pair.AccessToken == "" {
+		t.Fatalf("unexpected token pair %+v", pair)
+	}
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// The example of RFC 7636.
+	challenge := spotify.CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if challenge != "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM" {
+		t.Fatalf("unexpected code challenge %s", challenge)
+	}
+
+	verifier, err := spotify.NewCodeVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(verifier) < 43 || len(verifier) > 128 {
+		t.Fatalf("unexpected code verifier length %d", len(verifier))
+	}
+}
+
+func TestPKCE(t *testing.T) {
+	srv := newServer(t)
+
+	verifier, err := spotify.NewCodeVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	challenge := spotify.CodeChallenge(verifier)
+
+	ac := spotify.NewAuthClient(spotifytest.ClientID, "", "http://localhost:8080", nil, srv.Options()...)
+
+	state, err := spotify.NewState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authUrl, err := url.Parse(ac.BuildPKCEAuthUrl(state, challenge))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := authUrl.Query()
+	if q.Get("state") != state || q.Get("code_challenge") != challenge || q.Get("code_challenge_method") != "S256" {
+		t.Fatalf("unexpected auth url %s", authUrl)
+	}
+
+	if _, err := ac.GetPKCETokenPair(srv.AuthorizePKCE(challenge), "invalid"); err == nil {
+		t.Fatal("expected an error for an invalid code verifier")
+	}
+
+	pair, err := ac.GetPKCETokenPair(srv.AuthorizePKCE(challenge), verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rotated []string
+	opts := append(srv.Options(), spotify.WithTokenHandler(func(token spotify.Token) {
+		rotated = append(rotated, token.RefreshToken)
+	}))
+	client := spotify.NewClient(pair.RefreshToken, spotifytest.ClientID, "", opts...)
+
+	if _, err := client.GetDevices(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The rotated refresh token is used for the next refresh,
+	// the old one is not accepted anymore.
+	srv.ExpireTokens()
+	if _, err := client.GetDevices(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rotated) != 2 || rotated[0] == pair.RefreshToken || rotated[1] == rotated[0] {
+		t.Fatalf("unexpected rotated refresh tokens %v", rotated)
+	}
+
+	if _, err := ac.RequestRefreshedToken(pair.RefreshToken); err == nil {
+		t.Fatal("expected the old refresh token to be invalid")
+	}
+}
+
+func TestTokenExpiry(t *testing.T) {
+	srv := newServer(t)
+
+	var tokens []spotify.Token
+	opts := append(srv.Options(), spotify.WithTokenHandler(func(token spotify.Token) {
+		tokens = append(tokens, token)
+	}))
+
+	client := spotify.NewClient(spotifytest.RefreshToken, spotifytest.ClientID, spotifytest.ClientSecret, opts...)
+	if _, err := client.GetDevices(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tokens) != 1 || tokens[0].AccessToken == "" {
+		t.Fatalf("unexpected tokens %v", tokens)
+	}
+
+	if d := time.Until(tokens[0].Expiry); d < 59*time.Minute || d > time.Hour {
+		t.Fatalf("unexpected expiry in %s", d)
+	}
+
+	// A saved token is reused until it expires.
+	reused := spotify.NewClient(spotifytest.RefreshToken, spotifytest.ClientID, spotifytest.ClientSecret,
+		append(opts, spotify.WithToken(tokens[0]))...,
+	)
+	if _, err := reused.GetDevices(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tokens) != 1 {
+		t.Fatalf("expected the saved token to be reused, got %v", tokens)
+	}
+
+	// A token which expires soon is refreshed before the request.
+	expiring := spotify.NewClient(spotifytest.RefreshToken, spotifytest.ClientID, spotifytest.ClientSecret,
+		append(opts, spotify.WithToken(spotify.Token{
+			AccessToken: tokens[0].AccessToken,
+			Expiry:      time.Now().Add(30 * time.Second),
+		}))...,
+	)
+	if _, err := expiring.GetDevices(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tokens) != 2 || tokens[1].AccessToken == tokens[0].AccessToken {
+		t.Fatalf("expected the token to be refreshed, got %v", tokens)
+	}
+}
@@ -0,0 +1,74 @@
+package spotifytest
+
+import (
+	"fmt"
+
+	"github.com/davidborzek/spofi/pkg/spotify"
+)
+
+// NewTrack builds a track fixture with a given id, name and artist.
+func NewTrack(id string, name string, artist string) spotify.Track {
+	return spotify.Track{
+		ID:   id,
+		URI:  fmt.Sprintf("spotify:track:%s", id),
+		Name: name,
+		Artists: []spotify.Artist{
+			{
+				ID:   artist,
+				URI:  fmt.Sprintf("spotify:artist:%s", artist),
+				Name: artist,
+			},
+		},
+		DurationMs:  180000,
+		TrackNumber: 1,
+		DiscNumber:  1,
+	}
+}
+
+// NewAlbum builds an album fixture with a given id, name,
+// artist and tracks. The track numbers are set in order.
+func NewAlbum(id string, name string, artist string, tracks ...spotify.Track) spotify.AlbumWithTracks {
+	album := spotify.AlbumWithTracks{
+		Album: spotify.Album{
+			ID:   id,
+			URI:  fmt.Sprintf("spotify:album:%s", id),
+			Name: name,
+			Artists: []spotify.Artist{
+				{
+					ID:   artist,
+					URI:  fmt.Sprintf("spotify:artist:%s", artist),
+					Name: artist,
+				},
+			},
+			ReleaseDate: "2020-01-01",
+			TotalTracks: len(tracks),
+		},
+	}
+
+	for i, t := range tracks {
+		t.TrackNumber = i + 1
+		album.Tracks.Items = append(album.Tracks.Items, t)
+	}
+	album.Tracks.Total = len(tracks)
+
+	return album
+}
+
+// NewDevice builds a device fixture with a given id and name.
+func NewDevice(id string, name string) spotify.Device {
+	return spotify.Device{
+		ID:            id,
+		Name:          name,
+		VolumePercent: 50,
+	}
+}
+
+// NewPlaylist builds a playlist fixture with a given id, name and tracks.
+func NewPlaylist(id string, name string, tracks ...spotify.Track) Playlist {
+	return Playlist{
+		ID:     id,
+		URI:    fmt.Sprintf("spotify:playlist:%s", id),
+		Name:   name,
+		Tracks: tracks,
+	}
+}
@@ -0,0 +1,1119 @@
+// Package spotifytest provides an in-process fake of the
+// spotify web api and accounts service for tests.
+package spotifytest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davidborzek/spofi/pkg/spotify"
+)
+
+const (
+	// ClientID is the client id accepted by the fake accounts service.
+	ClientID = "spotifytest-client-id"
+	// ClientSecret is the client secret accepted by the fake accounts service.
+	ClientSecret = "spotifytest-client-secret"
+	// RefreshToken is the refresh token accepted by the fake accounts service.
+	RefreshToken = "spotifytest-refresh-token"
+	// AuthorizationCode is the authorization code accepted by the fake accounts service.
+	AuthorizationCode = "spotifytest-code"
+
+	apiPrefix = "/v1"
+)
+
+// Request represents a request received by the fake api.
+type Request struct {
+	Method string
+	Path   string
+	Query  map[string]string
+	Body   map[string]interface{}
+}
+
+// Fault represents an injected failure for requests
+// matching a method and path.
+type Fault struct {
+	// Method is the http method to match (empty matches all).
+	Method string
+	// Path is the api path to match without the /v1 prefix,
+	// e.g. /me/player/play (empty matches all).
+	Path string
+	// Status is the http status code to respond with.
+	Status int
+	// Reason is the optional player error reason.
+	Reason string
+	// Times is the number of requests to fail (0 fails forever).
+	Times int
+}
+
+// Server is a fake spotify server which keeps the
+// player, device, queue and library state in memory.
+type Server struct {
+	*httptest.Server
+
+	mu sync.Mutex
+
+	tokens map[string]bool
+	faults []*Fault
+
+	// refreshTokens are the valid refresh tokens and
+	// pkceCodes the code challenges of authorization codes.
+	refreshTokens map[string]bool
+	pkceCodes     map[string]string
+
+	requests []Request
+
+	devices        []spotify.Device
+	activeDevice   string
+	player         *spotify.Player
+	queue          []spotify.Track
+	recentlyPlayed []spotify.Track
+	likedTracks    []spotify.SavedTrack
+	savedAlbums    []spotify.SavedAlbum
+	albums         map[string]spotify.AlbumWithTracks
+	tracks         map[string]spotify.Track
+	playlists      []Playlist
+	context        string
+	playing        []spotify.Track
+
+	// older and newer count the library items added before
+	// and after the initial items to generate added_at dates.
+	older int
+	newer int
+}
+
+// Playlist represents a playlist in the fake library.
+type Playlist struct {
+	ID         string
+	URI        string
+	Name       string
+	SnapshotID string
+	Tracks     []spotify.Track
+}
+
+// addedAtBase is the added_at date of the fake library.
+// Items which are appended by the test setup are older and
+// items which are saved using the api are newer.
+var addedAtBase = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// addedAt is an internal implementation to generate the
+// added_at date of a library item. It must be called with
+// the mutex locked.
+func (s *Server) addedAt(newer bool) string {
+	if newer {
+		s.newer++
+		return addedAtBase.Add(time.Duration(s.newer) * time.Hour).Format(time.RFC3339)
+	}
+
+	s.older++
+	return addedAtBase.Add(-time.Duration(s.older) * time.Hour).Format(time.RFC3339)
+}
+
+// NewServer starts a new fake spotify server.
+// The server must be closed by the caller.
+func NewServer() *Server {
+	s := &Server{
+		tokens:        map[string]bool{},
+		refreshTokens: map[string]bool{RefreshToken: true},
+		pkceCodes:     map[string]string{},
+		albums:        map[string]spotify.AlbumWithTracks{},
+		tracks:        map[string]spotify.Track{},
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Options returns the client options to point a spotify
+// client to the fake server.
+func (s *Server) Options() []spotify.Option {
+	return []spotify.Option{
+		spotify.WithApiBaseUrl(s.URL + apiPrefix),
+		spotify.WithAuthBaseUrl(s.URL),
+		spotify.WithHTTPClient(s.Server.Client()),
+	}
+}
+
+// NewClient creates a new spotify client which
+// is authenticated against the fake server.
+func (s *Server) NewClient() spotify.Client {
+	return spotify.NewClient(RefreshToken, ClientID, ClientSecret, s.Options()...)
+}
+
+// AddDevice adds a device. The first added device becomes active.
+func (s *Server) AddDevice(device spotify.Device) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.devices = append(s.devices, device)
+	if s.activeDevice == "" {
+		s.activeDevice = device.ID
+	}
+}
+
+// SetNoActiveDevice deactivates the active device, so player
+// commands without a device id fail with NO_ACTIVE_DEVICE.
+func (s *Server) SetNoActiveDevice() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.activeDevice = ""
+	s.player = nil
+}
+
+// AddTracks adds tracks to the catalog, so they can be played,
+// queued and searched.
+func (s *Server) AddTracks(tracks ...spotify.Track) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range tracks {
+		s.tracks[t.URI] = t
+	}
+}
+
+// AddAlbum adds an album and its tracks to the catalog.
+func (s *Server) AddAlbum(album spotify.AlbumWithTracks) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.albums[album.ID] = album
+	for _, t := range album.Tracks.Items {
+		t.Album = album.Album
+		s.tracks[t.URI] = t
+	}
+}
+
+// AddPlaylist adds a playlist to the library.
+func (s *Server) AddPlaylist(playlist Playlist) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if playlist.SnapshotID == "" {
+		playlist.SnapshotID = newToken()
+	}
+
+	s.playlists = append(s.playlists, playlist)
+	for _, t := range playlist.Tracks {
+		s.tracks[t.URI] = t
+	}
+}
+
+// Playlist returns a playlist of the library by id.
+func (s *Server) Playlist(id string) (Playlist, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range s.playlists {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return Playlist{}, false
+}
+
+// LikeTracks adds tracks to the liked tracks of the user.
+func (s *Server) LikeTracks(tracks ...spotify.Track) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range tracks {
+		s.tracks[t.URI] = t
+		s.likedTracks = append(s.likedTracks, spotify.SavedTrack{
+			AddedAt: s.addedAt(false),
+			Track:   t,
+		})
+	}
+}
+
+// UnlikeTracks removes tracks from the liked tracks
+// of the user by id, e.g. when removed by another client.
+func (s *Server) UnlikeTracks(ids ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		for i, t := range s.likedTracks {
+			if t.Track.ID == id {
+				s.likedTracks = append(s.likedTracks[:i], s.likedTracks[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// LikedTracks returns the liked tracks of the user.
+func (s *Server) LikedTracks() []spotify.Track {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tracks := make([]spotify.Track, len(s.likedTracks))
+	for i, t := range s.likedTracks {
+		tracks[i] = t.Track
+	}
+	return tracks
+}
+
+// SaveAlbums adds albums to the library of the user.
+func (s *Server) SaveAlbums(albums ...spotify.AlbumWithTracks) {
+	for _, a := range albums {
+		s.AddAlbum(a)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, a := range albums {
+		s.savedAlbums = append(s.savedAlbums, spotify.SavedAlbum{
+			AddedAt: s.addedAt(false),
+			Album:   a,
+		})
+	}
+}
+
+// SetRecentlyPlayed sets the recently played tracks.
+func (s *Server) SetRecentlyPlayed(tracks ...spotify.Track) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.recentlyPlayed = tracks
+}
+
+// Player returns a copy of the player state or nil
+// when nothing is playing.
+func (s *Server) Player() *spotify.Player {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.player == nil {
+		return nil
+	}
+
+	p := *s.player
+	return &p
+}
+
+// Context returns the uri of the currently played context.
+func (s *Server) Context() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.context
+}
+
+// Queue returns the uris of the queued tracks.
+func (s *Server) Queue() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	uris := make([]string, len(s.queue))
+	for i, t := range s.queue {
+		uris[i] = t.URI
+	}
+	return uris
+}
+
+// InjectFault adds a fault for matching requests.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &f)
+}
+
+// ExpireTokens invalidates all issued access tokens,
+// so the next api request responds with 401.
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens = map[string]bool{}
+}
+
+// AuthorizePKCE simulates the consent of the user in the
+// authorization code flow with PKCE and returns the
+// authorization code for a code challenge.
+func (s *Server) AuthorizePKCE(codeChallenge string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	code := newToken()
+	s.pkceCodes[code] = codeChallenge
+	return code
+}
+
+// Requests returns all api requests received by the server.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request{}, s.requests...)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == "/api/token" {
+		s.handleToken(w, r)
+		return
+	}
+
+	if !strings.HasPrefix(r.URL.Path, apiPrefix) {
+		writeError(w, http.StatusNotFound, "Service not found", "")
+		return
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !s.tokens[token] {
+		writeError(w, http.StatusUnauthorized, "The access token expired", "")
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, apiPrefix)
+	req := Request{
+		Method: r.Method,
+		Path:   path,
+		Query:  map[string]string{},
+	}
+	for k := range r.URL.Query() {
+		req.Query[k] = r.URL.Query().Get(k)
+	}
+	if r.Body != nil {
+		json.NewDecoder(r.Body).Decode(&req.Body)
+	}
+	s.requests = append(s.requests, req)
+
+	if f := s.matchFault(r.Method, path); f != nil {
+		if f.Status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "1")
+		}
+		writeError(w, f.Status, http.StatusText(f.Status), f.Reason)
+		return
+	}
+
+	s.route(w, req)
+}
+
+func (s *Server) matchFault(method string, path string) *Fault {
+	for i, f := range s.faults {
+		if f.Method != "" && f.Method != method {
+			continue
+		}
+		if f.Path != "" && f.Path != path {
+			continue
+		}
+
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error(), "")
+		return
+	}
+
+	// Clients without a secret are public clients using PKCE.
+	secret := r.Form.Get("client_secret")
+	public := secret == ""
+
+	if r.Form.Get("client_id") != ClientID || (!public && secret != ClientSecret) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid_client",
+		})
+		return
+	}
+
+	invalidGrant := func() {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid_grant",
+		})
+	}
+
+	refreshToken := ""
+
+	switch r.Form.Get("grant_type") {
+	case "refresh_token":
+		token := r.Form.Get("refresh_token")
+		if !s.refreshTokens[token] {
+			invalidGrant()
+			return
+		}
+
+		// Refresh tokens of public clients are rotated.
+		if public {
+			delete(s.refreshTokens, token)
+			refreshToken = newToken()
+			s.refreshTokens[refreshToken] = true
+		}
+	case "authorization_code":
+		code := r.Form.Get("code")
+
+		if public {
+			challenge, ok := s.pkceCodes[code]
+			if !ok || spotify.CodeChallenge(r.Form.Get("code_verifier")) != challenge {
+				invalidGrant()
+				return
+			}
+
+			delete(s.pkceCodes, code)
+			refreshToken = newToken()
+			s.refreshTokens[refreshToken] = true
+		} else {
+			if code != AuthorizationCode {
+				invalidGrant()
+				return
+			}
+			refreshToken = RefreshToken
+		}
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "unsupported_grant_type",
+		})
+		return
+	}
+
+	token := newToken()
+	s.tokens[token] = true
+
+	res := map[string]interface{}{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	}
+
+	if refreshToken != "" {
+		res["refresh_token"] = refreshToken
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) route(w http.ResponseWriter, req Request) {
+	switch {
+	case req.Method == http.MethodGet && req.Path == "/me/player/devices":
+		s.getDevices(w)
+	case req.Method == http.MethodGet && req.Path == "/me/player":
+		s.getPlayer(w)
+	case req.Method == http.MethodPut && req.Path == "/me/player/play":
+		s.play(w, req)
+	case req.Method == http.MethodPut && req.Path == "/me/player/pause":
+		s.pause(w, req)
+	case req.Method == http.MethodPost && req.Path == "/me/player/next":
+		s.skip(w, req, 1)
+	case req.Method == http.MethodPost && req.Path == "/me/player/previous":
+		s.skip(w, req, -1)
+	case req.Method == http.MethodPost && req.Path == "/me/player/queue":
+		s.addQueue(w, req)
+	case req.Method == http.MethodGet && req.Path == "/me/player/queue":
+		s.getQueue(w)
+	case req.Method == http.MethodPut && req.Path == "/me/player/shuffle":
+		s.setShuffle(w, req)
+	case req.Method == http.MethodPut && req.Path == "/me/player/repeat":
+		s.setRepeat(w, req)
+	case req.Method == http.MethodGet && req.Path == "/me/player/recently-played":
+		s.getRecentlyPlayed(w)
+	case req.Method == http.MethodGet && req.Path == "/me/tracks":
+		s.getLikedTracks(w, req)
+	case req.Method == http.MethodPut && req.Path == "/me/tracks":
+		s.saveTracks(w, req)
+	case req.Method == http.MethodGet && req.Path == "/me/playlists":
+		s.getPlaylists(w, req)
+	case req.Method == http.MethodGet && strings.HasPrefix(req.Path, "/playlists/") && strings.HasSuffix(req.Path, "/tracks"):
+		s.getPlaylistTracks(w, req, strings.TrimSuffix(strings.TrimPrefix(req.Path, "/playlists/"), "/tracks"))
+	case req.Method == http.MethodPost && strings.HasPrefix(req.Path, "/playlists/") && strings.HasSuffix(req.Path, "/tracks"):
+		s.addPlaylistTracks(w, req, strings.TrimSuffix(strings.TrimPrefix(req.Path, "/playlists/"), "/tracks"))
+	case req.Method == http.MethodGet && req.Path == "/me/albums":
+		s.getSavedAlbums(w, req)
+	case req.Method == http.MethodGet && req.Path == "/search":
+		s.search(w, req)
+	case req.Method == http.MethodGet && strings.HasPrefix(req.Path, "/albums/"):
+		s.getAlbum(w, strings.TrimPrefix(req.Path, "/albums/"))
+	case req.Method == http.MethodGet && strings.HasPrefix(req.Path, "/tracks/"):
+		s.getTrack(w, strings.TrimPrefix(req.Path, "/tracks/"))
+	case req.Method == http.MethodGet && strings.HasPrefix(req.Path, "/artists/") && strings.HasSuffix(req.Path, "/albums"):
+		s.getArtistAlbums(w, strings.TrimSuffix(strings.TrimPrefix(req.Path, "/artists/"), "/albums"))
+	case req.Method == http.MethodGet && strings.HasPrefix(req.Path, "/artists/"):
+		s.getArtist(w, strings.TrimPrefix(req.Path, "/artists/"))
+	default:
+		writeError(w, http.StatusNotFound, "Service not found", "")
+	}
+}
+
+// device resolves the device of a player command and
+// activates it. It writes an error and returns false
+// when no device is available.
+func (s *Server) device(w http.ResponseWriter, req Request) (spotify.Device, bool) {
+	id := req.Query["device_id"]
+	if id == "" {
+		id = s.activeDevice
+	}
+
+	for _, d := range s.devices {
+		if d.ID == id {
+			s.activeDevice = id
+			return d, true
+		}
+	}
+
+	if req.Query["device_id"] != "" {
+		writeError(w, http.StatusNotFound, "Device not found", "")
+		return spotify.Device{}, false
+	}
+
+	writeError(w, http.StatusNotFound, "Player command failed: No active device found", spotify.ReasonNoActiveDevice)
+	return spotify.Device{}, false
+}
+
+// activePlayer returns the current player or writes
+// an error when nothing is playing.
+func (s *Server) activePlayer(w http.ResponseWriter, req Request) *spotify.Player {
+	d, ok := s.device(w, req)
+	if !ok {
+		return nil
+	}
+
+	if s.player == nil {
+		writeError(w, http.StatusNotFound, "Player command failed: No active device found", spotify.ReasonNoActiveDevice)
+		return nil
+	}
+
+	s.player.Device = d
+	return s.player
+}
+
+func (s *Server) getDevices(w http.ResponseWriter) {
+	devices := s.devices
+	if devices == nil {
+		devices = []spotify.Device{}
+	}
+
+	writeJSON(w, http.StatusOK, spotify.DeviceResponse{
+		Devices: devices,
+	})
+}
+
+func (s *Server) getPlayer(w http.ResponseWriter) {
+	if s.player == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.player)
+}
+
+func (s *Server) startTrack(d spotify.Device, track spotify.Track) {
+	if s.player != nil && s.player.Item.URI != "" {
+		s.recentlyPlayed = append([]spotify.Track{s.player.Item}, s.recentlyPlayed...)
+	}
+
+	if s.player == nil {
+		s.player = &spotify.Player{
+			RepeatState: spotify.RepeatOff,
+		}
+	}
+
+	s.player.Device = d
+	s.player.Item = track
+	s.player.ProgressMs = 0
+	s.player.IsPlaying = true
+}
+
+func (s *Server) play(w http.ResponseWriter, req Request) {
+	d, ok := s.device(w, req)
+	if !ok {
+		return
+	}
+
+	if uris, ok := req.Body["uris"].([]interface{}); ok && len(uris) > 0 {
+		tracks := make([]spotify.Track, len(uris))
+		for i, uri := range uris {
+			track, ok := s.tracks[fmt.Sprint(uri)]
+			if !ok {
+				writeError(w, http.StatusBadRequest, "Invalid track uri", "")
+				return
+			}
+			tracks[i] = track
+		}
+
+		s.context = ""
+		s.playing = tracks
+		s.startTrack(d, tracks[0])
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if contextUri, ok := req.Body["context_uri"].(string); ok {
+		tracks, ok := s.contextTracks(contextUri)
+		if !ok || len(tracks) == 0 {
+			writeError(w, http.StatusBadRequest, "Invalid context uri", "")
+			return
+		}
+
+		track := tracks[0]
+		if offset, ok := req.Body["offset"].(map[string]interface{}); ok {
+			found := false
+			for _, t := range tracks {
+				if t.URI == offset["uri"] {
+					track = t
+					found = true
+				}
+			}
+
+			if !found {
+				writeError(w, http.StatusBadRequest, "Invalid offset uri", "")
+				return
+			}
+		}
+
+		s.context = contextUri
+		s.playing = tracks
+		s.startTrack(d, track)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if s.player == nil {
+		writeError(w, http.StatusNotFound, "Player command failed: No active device found", spotify.ReasonNoActiveDevice)
+		return
+	}
+
+	s.player.Device = d
+	s.player.IsPlaying = true
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) contextTracks(uri string) ([]spotify.Track, bool) {
+	id := spotify.URIToID(uri)
+
+	switch {
+	case strings.HasPrefix(uri, "spotify:album:"):
+		a, ok := s.albums[id]
+		return a.Tracks.Items, ok
+	case strings.HasPrefix(uri, "spotify:playlist:"):
+		for _, p := range s.playlists {
+			if p.ID == id {
+				return p.Tracks, true
+			}
+		}
+	case strings.HasPrefix(uri, "spotify:artist:"):
+		if _, ok := s.artist(id); !ok {
+			return nil, false
+		}
+
+		var tracks []spotify.Track
+		for _, a := range s.albums {
+			if len(a.Artists) > 0 && a.Artists[0].ID == id {
+				tracks = append(tracks, a.Tracks.Items...)
+			}
+		}
+		return tracks, true
+	}
+
+	return nil, false
+}
+
+// artist is an internal implementation to find an artist
+// of the albums or tracks. It must be called with the
+// mutex locked.
+func (s *Server) artist(id string) (spotify.Artist, bool) {
+	for _, a := range s.albums {
+		for _, artist := range a.Artists {
+			if artist.ID == id {
+				return artist, true
+			}
+		}
+	}
+
+	for _, t := range s.tracks {
+		for _, artist := range t.Artists {
+			if artist.ID == id {
+				return artist, true
+			}
+		}
+	}
+
+	return spotify.Artist{}, false
+}
+
+func (s *Server) pause(w http.ResponseWriter, req Request) {
+	p := s.activePlayer(w, req)
+	if p == nil {
+		return
+	}
+
+	p.IsPlaying = false
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) skip(w http.ResponseWriter, req Request, direction int) {
+	p := s.activePlayer(w, req)
+	if p == nil {
+		return
+	}
+
+	if direction > 0 && len(s.queue) > 0 {
+		next := s.queue[0]
+		s.queue = s.queue[1:]
+		s.startTrack(p.Device, next)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	tracks := s.playing
+	for i, t := range tracks {
+		if t.URI == p.Item.URI && i+direction >= 0 && i+direction < len(tracks) {
+			s.startTrack(p.Device, tracks[i+direction])
+			break
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) addQueue(w http.ResponseWriter, req Request) {
+	if _, ok := s.device(w, req); !ok {
+		return
+	}
+
+	track, ok := s.tracks[req.Query["uri"]]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid track uri", "")
+		return
+	}
+
+	s.queue = append(s.queue, track)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getQueue(w http.ResponseWriter) {
+	queue := s.queue
+	if queue == nil {
+		queue = []spotify.Track{}
+	}
+
+	var current *spotify.Track
+	if s.player != nil {
+		item := s.player.Item
+		current = &item
+	}
+
+	writeJSON(w, http.StatusOK, spotify.QueueResponse{
+		CurrentlyPlaying: current,
+		Queue:            queue,
+	})
+}
+
+func (s *Server) setShuffle(w http.ResponseWriter, req Request) {
+	p := s.activePlayer(w, req)
+	if p == nil {
+		return
+	}
+
+	state, err := strconv.ParseBool(req.Query["state"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid state", "")
+		return
+	}
+
+	p.ShuffleState = state
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) setRepeat(w http.ResponseWriter, req Request) {
+	p := s.activePlayer(w, req)
+	if p == nil {
+		return
+	}
+
+	state := spotify.RepeatState(req.Query["state"])
+	switch state {
+	case spotify.RepeatOff, spotify.RepeatContext, spotify.RepeatTrack:
+		p.RepeatState = state
+	default:
+		writeError(w, http.StatusBadRequest, "Invalid state", "")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getRecentlyPlayed(w http.ResponseWriter) {
+	var data spotify.RecentlyPlayedResponse
+	for _, t := range s.recentlyPlayed {
+		data.Items = append(data.Items, struct {
+			Track spotify.Track `json:"track"`
+		}{Track: t})
+	}
+
+	writeJSON(w, http.StatusOK, data)
+}
+
+// paging parses limit and offset of a request and
+// returns the bounds for a list with the given length.
+func paging(req Request, total int) (int, int, spotify.PagingResult) {
+	limit, err := strconv.Atoi(req.Query["limit"])
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+
+	offset, _ := strconv.Atoi(req.Query["offset"])
+	if offset > total {
+		offset = total
+	}
+
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+
+	return offset, end, spotify.PagingResult{
+		Limit:  limit,
+		Offset: offset,
+		Total:  total,
+	}
+}
+
+func (s *Server) getLikedTracks(w http.ResponseWriter, req Request) {
+	start, end, page := paging(req, len(s.likedTracks))
+
+	var data spotify.LikeTracksResponse
+	data.PagingResult = page
+	data.Items = append(data.Items, s.likedTracks[start:end]...)
+
+	writeJSON(w, http.StatusOK, data)
+}
+
+func (s *Server) saveTracks(w http.ResponseWriter, req Request) {
+	ids := strings.Split(req.Query["ids"], ",")
+
+	for _, id := range ids {
+		track, ok := s.tracks[fmt.Sprintf("spotify:track:%s", id)]
+		if !ok {
+			writeError(w, http.StatusBadRequest, "Invalid track id", "")
+			return
+		}
+
+		liked := false
+		for _, t := range s.likedTracks {
+			if t.Track.ID == id {
+				liked = true
+			}
+		}
+
+		if !liked {
+			saved := spotify.SavedTrack{AddedAt: s.addedAt(true), Track: track}
+			s.likedTracks = append([]spotify.SavedTrack{saved}, s.likedTracks...)
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) getPlaylists(w http.ResponseWriter, req Request) {
+	start, end, page := paging(req, len(s.playlists))
+
+	data := spotify.PlaylistsResponse{
+		Items:        []spotify.Playlist{},
+		PagingResult: page,
+	}
+	for _, p := range s.playlists[start:end] {
+		playlist := spotify.Playlist{
+			ID:         p.ID,
+			URI:        p.URI,
+			Name:       p.Name,
+			SnapshotID: p.SnapshotID,
+		}
+		playlist.Tracks.Total = len(p.Tracks)
+		data.Items = append(data.Items, playlist)
+	}
+
+	writeJSON(w, http.StatusOK, data)
+}
+
+func (s *Server) getPlaylistTracks(w http.ResponseWriter, req Request, id string) {
+	for _, p := range s.playlists {
+		if p.ID != id {
+			continue
+		}
+
+		start, end, page := paging(req, len(p.Tracks))
+
+		data := spotify.PlaylistTracksResponse{
+			Items:        []spotify.PlaylistTrack{},
+			PagingResult: page,
+		}
+		for _, t := range p.Tracks[start:end] {
+			data.Items = append(data.Items, spotify.PlaylistTrack{
+				AddedAt: addedAtBase.Format(time.RFC3339),
+				Track:   t,
+			})
+		}
+
+		writeJSON(w, http.StatusOK, data)
+		return
+	}
+
+	writeError(w, http.StatusNotFound, "Invalid playlist id", "")
+}
+
+func (s *Server) addPlaylistTracks(w http.ResponseWriter, req Request, id string) {
+	uris, _ := req.Body["uris"].([]interface{})
+
+	for i, p := range s.playlists {
+		if p.ID != id {
+			continue
+		}
+
+		for _, uri := range uris {
+			track, ok := s.tracks[fmt.Sprint(uri)]
+			if !ok {
+				writeError(w, http.StatusBadRequest, "Invalid track uri", "")
+				return
+			}
+			s.playlists[i].Tracks = append(s.playlists[i].Tracks, track)
+		}
+
+		s.playlists[i].SnapshotID = newToken()
+		writeJSON(w, http.StatusCreated, map[string]string{
+			"snapshot_id": s.playlists[i].SnapshotID,
+		})
+		return
+	}
+
+	writeError(w, http.StatusNotFound, "Invalid playlist id", "")
+}
+
+func (s *Server) getSavedAlbums(w http.ResponseWriter, req Request) {
+	start, end, page := paging(req, len(s.savedAlbums))
+
+	var data spotify.SavedAlbumResponse
+	data.PagingResult = page
+	data.Items = append(data.Items, s.savedAlbums[start:end]...)
+
+	writeJSON(w, http.StatusOK, data)
+}
+
+func (s *Server) getAlbum(w http.ResponseWriter, id string) {
+	a, ok := s.albums[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Non existing id", "")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, a)
+}
+
+func (s *Server) getTrack(w http.ResponseWriter, id string) {
+	t, ok := s.tracks["spotify:track:"+id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Non existing id", "")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) getArtist(w http.ResponseWriter, id string) {
+	artist, ok := s.artist(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Non existing id", "")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, artist)
+}
+
+func (s *Server) getArtistAlbums(w http.ResponseWriter, id string) {
+	data := spotify.ArtistAlbumsResponse{Items: []spotify.Album{}}
+	for _, a := range s.albums {
+		for _, artist := range a.Artists {
+			if artist.ID == id {
+				data.Items = append(data.Items, a.Album)
+				break
+			}
+		}
+	}
+	sort.Slice(data.Items, func(i, j int) bool {
+		return data.Items[i].Name < data.Items[j].Name
+	})
+	data.Total = len(data.Items)
+
+	writeJSON(w, http.StatusOK, data)
+}
+
+func (s *Server) search(w http.ResponseWriter, req Request) {
+	q := strings.ToLower(req.Query["q"])
+	types := strings.Split(req.Query["type"], ",")
+
+	var data spotify.SearchResponse
+	for _, t := range types {
+		switch t {
+		case "track":
+			for _, track := range s.tracks {
+				if strings.Contains(strings.ToLower(track.Name), q) {
+					data.Tracks.Items = append(data.Tracks.Items, track)
+				}
+			}
+			sort.Slice(data.Tracks.Items, func(i, j int) bool {
+				return data.Tracks.Items[i].URI < data.Tracks.Items[j].URI
+			})
+			data.Tracks.Total = len(data.Tracks.Items)
+		case "album":
+			for _, album := range s.albums {
+				if strings.Contains(strings.ToLower(album.Name), q) {
+					data.Albums.Items = append(data.Albums.Items, album.Album)
+				}
+			}
+			sort.Slice(data.Albums.Items, func(i, j int) bool {
+				return data.Albums.Items[i].URI < data.Albums.Items[j].URI
+			})
+			data.Albums.Total = len(data.Albums.Items)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, data)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string, reason string) {
+	body := map[string]interface{}{
+		"status":  status,
+		"message": message,
+	}
+	if reason != "" {
+		body["reason"] = reason
+	}
+
+	writeJSON(w, status, map[string]interface{}{
+		"error": body,
+	})
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
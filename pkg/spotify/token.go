@@ -0,0 +1,40 @@
+package spotify
+
+import "time"
+
+// expiryDelta is the time before the expiry of an access
+// token, from which on it is refreshed before a request.
+const expiryDelta = time.Minute
+
+// Token represents an access token of the spotify
+// api with the time when it expires.
+type Token struct {
+	AccessToken string
+	Expiry      time.Time
+	// RefreshToken is the refresh token for the next refresh.
+	// The refresh token of a PKCE client is rotated on every
+	// refresh, so the old one can't be used again.
+	RefreshToken string
+}
+
+// Valid checks if the token is set and does not expire
+// soon. A token without an expiry is valid until it
+// is rejected by the api.
+func (t Token) Valid() bool {
+	if t.AccessToken == "" {
+		return false
+	}
+
+	return t.Expiry.IsZero() || time.Now().Add(expiryDelta).Before(t.Expiry)
+}
+
+// newToken is an internal implementation to create
+// a token which expires in the given seconds.
+func newToken(accessToken string, expiresIn int) Token {
+	token := Token{AccessToken: accessToken}
+	if expiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(expiresIn) * time.Second)
+	}
+
+	return token
+}